@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"DecisionTree/evaluation"
+	"DecisionTree/tree"
+	"flag"
+	"fmt"
+)
+
+func runCalibrate(args []string) error {
+	fs := flag.NewFlagSet("calibrate", flag.ContinueOnError)
+	var (
+		modelFile      = fs.String("model", "tree.json", "model file")
+		attributesFile = fs.String("names", "dataset/adult.names", "attributes (names) file")
+		validationFile = fs.String("data", "dataset/adult.test", "validation data file")
+		preprocess     = fs.Bool("preprocess", true, "pre-process the attributes with dataset.PreProcessAttributes, the data is not resampled")
+		method         = fs.String("method", "", "calibration method: laplace, m-estimate, platt, isotonic, skipped if empty")
+		m              = fs.Float64("m", 0, "m of the m-estimate, chosen on the validation data if 0")
+		positiveClass  = fs.String("positive-class", "", "positive class of the decision threshold, skipped if empty")
+		criterion      = fs.String("criterion", "f1", "threshold criterion: f1, precision, recall")
+		target         = fs.Float64("target", 0, "target precision or recall of the threshold")
+		outFile        = fs.String("out", "", "output model file, the model file is overwritten if empty")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *method == "" && *positiveClass == "" {
+		return fmt.Errorf("nothing to do, either -method or -positive-class is required")
+	}
+	if *outFile == "" {
+		*outFile = *modelFile
+	}
+
+	tr, err := tree.ReadTreeFromFile(*modelFile)
+	if err != nil {
+		return fmt.Errorf("failed to read tree: %w", err)
+	}
+	validationData, err := readModelDataset(*attributesFile, *validationFile, *preprocess, false, tr)
+	if err != nil {
+		return err
+	}
+
+	if *method != "" {
+		calibrator, err := tree.FitCalibrator(tr, validationData, &tree.CalibrationOptions{
+			Method: tree.CalibrationMethod(*method),
+			M:      *m,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fit calibrator: %w", err)
+		}
+		tr.Calibrator = calibrator
+		fmt.Printf("Calibrated with %s\n", calibrator.Method)
+	}
+	if *positiveClass != "" {
+		threshold, err := evaluation.SelectThreshold(tr, validationData, *positiveClass, evaluation.ThresholdCriterion(*criterion), *target)
+		if err != nil {
+			return fmt.Errorf("failed to select threshold: %w", err)
+		}
+		tr.Threshold = threshold
+		fmt.Printf("Threshold of class %s: %v\n", threshold.PositiveClass, threshold.Threshold)
+	}
+
+	if err := tree.WriteTreeToFile(tr, *outFile); err != nil {
+		return fmt.Errorf("failed to save tree: %w", err)
+	}
+	return nil
+}
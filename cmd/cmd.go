@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type command struct {
+	description string
+	run         func(args []string) error
+}
+
+var commands = map[string]*command{
+	"train":      {description: "train a decision tree and save it to a model file", run: runTrain},
+	"profile":    {description: "summarize a data set before training", run: runProfile},
+	"serve":      {description: "serve predictions of trained models over HTTP", run: runServe},
+	"importance": {description: "show the feature importance of a trained model", run: runImportance},
+	"rules":      {description: "convert a trained model into an ordered rule set", run: runRules},
+	"evaluate":   {description: "evaluate a trained model on a data set", run: runEvaluate},
+	"drift":      {description: "check a data set for drift from the training data of a model", run: runDrift},
+	"compare":    {description: "compare two trained models on a data set with significance tests", run: runCompare},
+	"calibrate":  {description: "calibrate the probabilities and tune the decision threshold of a model", run: runCalibrate},
+	"export":     {description: "export a trained model to other formats", run: runExport},
+	"import":     {description: "import a model from other formats", run: runImport},
+}
+
+// Execute runs the sub-command named by the first argument, the "train" command runs if no argument is given.
+func Execute(args []string) error {
+	if len(args) == 0 {
+		return runTrain(nil)
+	}
+	c, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command '%s', available commands:\n%s", args[0], usage())
+	}
+	return c.run(args[1:])
+}
+
+func usage() string {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("  %-10s %s\n", name, commands[name].description))
+	}
+	return sb.String()
+}
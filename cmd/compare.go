@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"DecisionTree/evaluation"
+	"DecisionTree/tree"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func runCompare(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	var (
+		modelFileA     = fs.String("a", "", "model file of model A")
+		modelFileB     = fs.String("b", "", "model file of model B")
+		attributesFile = fs.String("names", "dataset/adult.names", "attributes (names) file")
+		testDataFile   = fs.String("data", "dataset/adult.test", "testing data file")
+		preprocess     = fs.Bool("preprocess", true, "pre-process the attributes with dataset.PreProcessAttributes, the data is not resampled")
+		iterations     = fs.Int("iterations", 1000, "number of bootstrap resamples")
+		confidence     = fs.Float64("confidence", 0.95, "confidence level of the intervals")
+		seed           = fs.Int64("seed", 1, "random seed of the bootstrap")
+		format         = fs.String("format", "csv", "report format: csv, json")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *modelFileA == "" || *modelFileB == "" {
+		return fmt.Errorf("both -a and -b are required")
+	}
+
+	a, err := tree.ReadTreeFromFile(*modelFileA)
+	if err != nil {
+		return fmt.Errorf("failed to read tree A: %w", err)
+	}
+	b, err := tree.ReadTreeFromFile(*modelFileB)
+	if err != nil {
+		return fmt.Errorf("failed to read tree B: %w", err)
+	}
+	testData, err := readModelDataset(*attributesFile, *testDataFile, *preprocess, false, a, b)
+	if err != nil {
+		return err
+	}
+	comparison, err := evaluation.Compare(a, b, testData, &evaluation.BootstrapOptions{
+		Iterations: *iterations,
+		Confidence: *confidence,
+		Seed:       *seed,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to compare: %w", err)
+	}
+
+	switch *format {
+	case "csv":
+		if err := evaluation.WriteComparisonCsv(os.Stdout, comparison); err != nil {
+			return err
+		}
+	case "json":
+		if err := evaluation.WriteComparisonJson(os.Stdout, comparison); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format '%s'", *format)
+	}
+
+	alpha := 1 - *confidence
+	switch comparison.Better(alpha) {
+	case "a":
+		fmt.Fprintf(os.Stderr, "Model A is significantly more accurate (McNemar p = %.4g)\n", comparison.McNemar.PValue)
+	case "b":
+		fmt.Fprintf(os.Stderr, "Model B is significantly more accurate (McNemar p = %.4g)\n", comparison.McNemar.PValue)
+	default:
+		fmt.Fprintf(os.Stderr, "No significant difference in accuracy (McNemar p = %.4g)\n", comparison.McNemar.PValue)
+	}
+	return nil
+}
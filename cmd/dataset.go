@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/dataset"
+	"DecisionTree/tree"
+	"fmt"
+)
+
+// readDataset reads the attributes and the values, and pre-processes the values if required.
+func readDataset(attributesFile, dataFile string, preprocess bool) (*data.AttributeTable, *data.ValueTable, error) {
+	attrTable, err := data.ReadAttributes(attributesFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read attributes: %w", err)
+	}
+
+	valueTable, err := data.ReadValues(config.Conf, attrTable, dataFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read data: %w", err)
+	}
+
+	if preprocess {
+		dataset.PreProcessData(valueTable)
+	}
+	return attrTable, valueTable, nil
+}
+
+// readModelDataset reads a data set to use with the trees. Only training data is resampled by pre-processing, the
+// other data sets keep their rows so that they are evaluated as they are. The pipeline of a tree transforms the
+// attributes itself, so if any tree has one, pre-processing does not change the attributes. Trees without a pipeline
+// ignore the attributes they do not know.
+func readModelDataset(attributesFile, dataFile string, preprocess, training bool, trees ...*tree.Tree) (*data.ValueTable, error) {
+	_, valueTable, err := readDataset(attributesFile, dataFile, false)
+	if err != nil || !preprocess {
+		return valueTable, err
+	}
+	if training {
+		dataset.Resample(valueTable)
+	}
+	for _, tr := range trees {
+		if tr.Pipeline != nil {
+			return valueTable, nil
+		}
+	}
+	dataset.PreProcessAttributes(valueTable)
+	return valueTable, nil
+}
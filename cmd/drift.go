@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"DecisionTree/drift"
+	"DecisionTree/tree"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func runDrift(args []string) error {
+	fs := flag.NewFlagSet("drift", flag.ContinueOnError)
+	var (
+		modelFile        = fs.String("model", "tree.json", "model file")
+		attributesFile   = fs.String("names", "dataset/adult.names", "attributes (names) file")
+		dataFile         = fs.String("data", "dataset/adult.test", "data file to check against the training data")
+		preprocess       = fs.Bool("preprocess", true, "pre-process the attributes with dataset.PreProcessAttributes, the data is not resampled")
+		format           = fs.String("format", "text", "report format: text, json")
+		psiThreshold     = fs.Float64("psi-threshold", 0.2, "PSI from which an attribute drifts")
+		missingThreshold = fs.Float64("missing-threshold", 0.05, "change of the missing rate from which an attribute drifts")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tr, err := tree.ReadTreeFromFile(*modelFile)
+	if err != nil {
+		return fmt.Errorf("failed to read tree: %w", err)
+	}
+	valueTable, err := readModelDataset(*attributesFile, *dataFile, *preprocess, false, tr)
+	if err != nil {
+		return err
+	}
+	report, err := drift.Check(tr, valueTable, &drift.Options{PSIThreshold: *psiThreshold, MissingRateThreshold: *missingThreshold})
+	if err != nil {
+		return fmt.Errorf("failed to check drift: %w", err)
+	}
+
+	switch *format {
+	case "text":
+		return drift.WriteText(os.Stdout, report)
+	case "json":
+		return drift.WriteJson(os.Stdout, report)
+	default:
+		return fmt.Errorf("unknown format '%s'", *format)
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"DecisionTree/evaluation"
+	"DecisionTree/tree"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func runEvaluate(args []string) error {
+	fs := flag.NewFlagSet("evaluate", flag.ContinueOnError)
+	var (
+		modelFile       = fs.String("model", "tree.json", "model file")
+		attributesFile  = fs.String("names", "dataset/adult.names", "attributes (names) file")
+		testDataFile    = fs.String("data", "dataset/adult.test", "testing data file")
+		preprocess      = fs.Bool("preprocess", true, "pre-process the attributes with dataset.PreProcessAttributes, the data is not resampled")
+		format          = fs.String("format", "csv", "report format: csv, json")
+		curvesFile      = fs.String("curves", "", "output CSV file of the ROC and precision-recall curves, skipped if empty")
+		reliabilityFile = fs.String("reliability", "", "output CSV file of the reliability diagrams, skipped if empty")
+		bootstrap       = fs.Int("bootstrap", 0, "number of bootstrap resamples of the confidence intervals, skipped if 0")
+		seed            = fs.Int64("seed", 1, "random seed of the bootstrap")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tr, err := tree.ReadTreeFromFile(*modelFile)
+	if err != nil {
+		return fmt.Errorf("failed to read tree: %w", err)
+	}
+	testData, err := readModelDataset(*attributesFile, *testDataFile, *preprocess, false, tr)
+	if err != nil {
+		return err
+	}
+	report, err := evaluation.Evaluate(tr, testData)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate: %w", err)
+	}
+	if *bootstrap > 0 {
+		report.Intervals, err = evaluation.Bootstrap(tr, testData, &evaluation.BootstrapOptions{Iterations: *bootstrap, Seed: *seed})
+		if err != nil {
+			return fmt.Errorf("failed to bootstrap: %w", err)
+		}
+	}
+
+	switch *format {
+	case "csv":
+		err = evaluation.WriteReportCsv(os.Stdout, report)
+	case "json":
+		err = evaluation.WriteReportJson(os.Stdout, report)
+	default:
+		return fmt.Errorf("unknown format '%s'", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *curvesFile != "" {
+		if err := writeReportFile(*curvesFile, report, evaluation.WriteCurvesCsv); err != nil {
+			return err
+		}
+	}
+	if *reliabilityFile != "" {
+		if err := writeReportFile(*reliabilityFile, report, evaluation.WriteReliabilityCsv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeReportFile(filepath string, report *evaluation.Report, write func(w io.Writer, report *evaluation.Report) error) error {
+	file, err := os.Create(filepath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+	return write(file, report)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/export"
+	"DecisionTree/tree"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	var (
+		modelFile    = fs.String("model", "tree.json", "model file")
+		format       = fs.String("format", "dot", "output format: dot, mermaid, html, go, sql, pmml, onnx")
+		outFile      = fs.String("out", "", "output file, print to stdout if empty")
+		maxDepth     = fs.Int("max-depth", 0, "max depth of rendered nodes for graph formats, 0 means no limit")
+		colorByClass = fs.Bool("color", false, "color nodes by majority class for graph formats")
+		title        = fs.String("title", "", "page title for html format")
+		instance     = fs.String("instance", "", "JSON object of an instance whose decision path is highlighted, for html format")
+		goPackage    = fs.String("go-package", "model", "package name for go format")
+		sqlDialect   = fs.String("sql-dialect", "ansi", "dialect for sql format: ansi, sqlite, mysql, sqlserver")
+		onnxEncoding = fs.String("onnx-encoding", "label", "encoding of nominal attributes for onnx format: label, onehot")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tr, err := tree.ReadTreeFromFile(*modelFile)
+	if err != nil {
+		return fmt.Errorf("failed to read tree: %w", err)
+	}
+	if tr.Pipeline != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warning: the preprocessing pipeline of the model is not exported, the exported model takes the transformed attributes")
+	}
+
+	var w io.Writer = os.Stdout
+	if *outFile != "" {
+		file, err := os.Create(*outFile)
+		if err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+		defer func(file *os.File) {
+			_ = file.Close()
+		}(file)
+		w = file
+	}
+
+	graphOpts := &export.GraphOptions{MaxDepth: *maxDepth, ColorByClass: *colorByClass}
+	switch *format {
+	case "dot":
+		return export.WriteDot(w, tr, graphOpts)
+	case "mermaid":
+		return export.WriteMermaid(w, tr, graphOpts)
+	case "html":
+		htmlOpts := &export.HTMLOptions{Title: *title}
+		if *instance != "" {
+			htmlOpts.Instance, err = parseJsonInstance(tr, *instance)
+			if err != nil {
+				return err
+			}
+		}
+		return export.WriteHTML(w, tr, htmlOpts)
+	case "go":
+		return export.WriteGoCode(w, tr, &export.GoCodeOptions{PackageName: *goPackage})
+	case "sql":
+		return export.WriteSQL(w, tr, &export.SQLOptions{Dialect: export.SQLDialect(*sqlDialect)})
+	case "pmml":
+		return export.WritePMML(w, tr, nil)
+	case "onnx":
+		return export.WriteONNX(w, tr, &export.ONNXOptions{Encoding: export.ONNXEncoding(*onnxEncoding)})
+	default:
+		return fmt.Errorf("unknown format '%s'", *format)
+	}
+}
+
+func parseJsonInstance(tr *tree.Tree, content string) (*data.Instance, error) {
+	var row map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &row); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal instance JSON: %w", err)
+	}
+	strRow, err := data.StringRowFromJson(row)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := data.ParseInstance(config.Conf, tr.InputAttributes(), tr.Class, strRow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse instance: %w", err)
+	}
+	return instance, nil
+}
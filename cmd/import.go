@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"DecisionTree/export"
+	"DecisionTree/tree"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	var (
+		inFile  = fs.String("in", "", "input file")
+		format  = fs.String("format", "pmml", "input format: pmml")
+		outFile = fs.String("out", "tree.json", "output model file")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *inFile == "" {
+		return fmt.Errorf("no input file given, use -in")
+	}
+
+	file, err := os.Open(*inFile)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	var tr *tree.Tree
+	switch *format {
+	case "pmml":
+		tr, err = export.ReadPMML(file)
+	default:
+		return fmt.Errorf("unknown format '%s'", *format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to import tree: %w", err)
+	}
+	fmt.Printf("Imported tree with %d nodes, max depth %d\n", tr.GetNodeCount(), tr.GetMaxDepth())
+
+	if err := tree.WriteTreeToFile(tr, *outFile); err != nil {
+		return fmt.Errorf("failed to save tree: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"DecisionTree/tree"
+	"flag"
+	"fmt"
+	"sort"
+)
+
+func runImportance(args []string) error {
+	fs := flag.NewFlagSet("importance", flag.ContinueOnError)
+	var (
+		modelFile      = fs.String("model", "tree.json", "model file")
+		attributesFile = fs.String("names", "dataset/adult.names", "attributes (names) file")
+		dataFile       = fs.String("data", "", "data file for permutation importance, permutation importance is skipped if empty")
+		preprocess     = fs.Bool("preprocess", true, "pre-process the attributes with dataset.PreProcessAttributes, the data is not resampled")
+		repeats        = fs.Int("repeats", 5, "number of shuffles of each attribute for permutation importance")
+		seed           = fs.Int64("seed", 1, "random seed for permutation importance")
+		save           = fs.Bool("save", false, "save the importance into the model file")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tr, err := tree.ReadTreeFromFile(*modelFile)
+	if err != nil {
+		return fmt.Errorf("failed to read tree: %w", err)
+	}
+	importance := &tree.FeatureImportance{
+		Impurity: tr.ImpurityImportance(),
+	}
+
+	if *dataFile != "" {
+		valueTable, err := readModelDataset(*attributesFile, *dataFile, *preprocess, false, tr)
+		if err != nil {
+			return err
+		}
+		importance.Permutation, err = tree.PermutationImportance(tr, valueTable, *repeats, *seed)
+		if err != nil {
+			return fmt.Errorf("failed to calculate permutation importance: %w", err)
+		}
+	} else if tr.FeatureImportance != nil {
+		// keep the saved permutation importance
+		importance.Permutation = tr.FeatureImportance.Permutation
+	}
+
+	printImportance("Impurity importance", importance.Impurity)
+	printImportance("Permutation importance", importance.Permutation)
+
+	if *save {
+		tr.FeatureImportance = importance
+		if err := tree.WriteTreeToFile(tr, *modelFile); err != nil {
+			return fmt.Errorf("failed to save tree: %w", err)
+		}
+	}
+	return nil
+}
+
+func printImportance(title string, importance map[string]float64) {
+	if len(importance) == 0 {
+		return
+	}
+	var names []string
+	for name := range importance {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if importance[names[i]] != importance[names[j]] {
+			return importance[names[i]] > importance[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	fmt.Printf("%s:\n", title)
+	for _, name := range names {
+		fmt.Printf("  %-20s %.4f\n", name, importance[name])
+	}
+}
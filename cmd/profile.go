@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"DecisionTree/profile"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func runProfile(args []string) error {
+	fs := flag.NewFlagSet("profile", flag.ContinueOnError)
+	var (
+		attributesFile = fs.String("names", "dataset/adult.names", "attributes (names) file")
+		dataFile       = fs.String("data", "dataset/adult.data", "data file")
+		preprocess     = fs.Bool("preprocess", false, "pre-process the data with dataset.PreProcessData, which duplicates rows to balance the classes")
+		format         = fs.String("format", "text", "report format: text, json")
+		quantiles      = fs.String("quantiles", "", "comma-separated quantiles of continuous attributes, 0.05,0.25,0.5,0.75,0.95 if empty")
+		groups         = fs.Int("duplicate-groups", 10, "number of the most frequent duplicate rows to show")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts := &profile.Options{DuplicateGroups: *groups}
+	if *quantiles != "" {
+		for _, s := range strings.Split(*quantiles, ",") {
+			q, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse quantile '%s': %w", s, err)
+			}
+			opts.Quantiles = append(opts.Quantiles, q)
+		}
+	}
+
+	attrTable, valueTable, err := readDataset(*attributesFile, *dataFile, *preprocess)
+	if err != nil {
+		return err
+	}
+	report, err := profile.Profile(attrTable, valueTable, opts)
+	if err != nil {
+		return fmt.Errorf("failed to profile data: %w", err)
+	}
+
+	switch *format {
+	case "text":
+		return profile.WriteText(os.Stdout, report)
+	case "json":
+		return profile.WriteJson(os.Stdout, report)
+	default:
+		return fmt.Errorf("unknown format '%s'", *format)
+	}
+}
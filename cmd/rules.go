@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"DecisionTree/rules"
+	"DecisionTree/tree"
+	"flag"
+	"fmt"
+)
+
+func runRules(args []string) error {
+	fs := flag.NewFlagSet("rules", flag.ContinueOnError)
+	var (
+		modelFile      = fs.String("model", "tree.json", "model file")
+		attributesFile = fs.String("names", "dataset/adult.names", "attributes (names) file")
+		trainDataFile  = fs.String("data", "dataset/adult.data", "training data file, used to simplify the rules")
+		preprocess     = fs.Bool("preprocess", true, "pre-process the data with dataset.PreProcessData")
+		outFile        = fs.String("out", "rules.json", "output rule set file, skipped if empty")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tr, err := tree.ReadTreeFromFile(*modelFile)
+	if err != nil {
+		return fmt.Errorf("failed to read tree: %w", err)
+	}
+	trainData, err := readModelDataset(*attributesFile, *trainDataFile, *preprocess, true, tr)
+	if err != nil {
+		return err
+	}
+
+	ruleSet, err := rules.ExtractRules(tr, trainData)
+	if err != nil {
+		return fmt.Errorf("failed to extract rules: %w", err)
+	}
+	fmt.Print(ruleSet.String())
+
+	if *outFile != "" {
+		if err := rules.WriteRuleSetToFile(ruleSet, *outFile); err != nil {
+			return fmt.Errorf("failed to save rules: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/server"
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// modelFlags collects repeated "-model [name=]path" flags.
+type modelFlags []string
+
+func (m *modelFlags) String() string {
+	return strings.Join(*m, ",")
+}
+
+func (m *modelFlags) Set(value string) error {
+	*m = append(*m, value)
+	return nil
+}
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	var (
+		models         modelFlags
+		addr           = fs.String("addr", ":8080", "address to listen on")
+		reloadInterval = fs.Duration("reload-interval", 5*time.Second, "interval of checking model files for hot reload, 0 disables hot reload")
+		maxBodyBytes   = fs.Int64("max-body-bytes", server.DefaultMaxBodyBytes, "maximum size of request bodies in bytes")
+	)
+	fs.Var(&models, "model", "model to serve, in the form of [name=]path, can be repeated (default tree=tree.json)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(models) == 0 {
+		models = modelFlags{"tree=tree.json"}
+	}
+
+	s := server.NewServer(config.Conf)
+	s.MaxBodyBytes = *maxBodyBytes
+	for _, m := range models {
+		name, path, ok := strings.Cut(m, "=")
+		if !ok {
+			path = m
+			name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		if err := s.LoadModel(name, path); err != nil {
+			return fmt.Errorf("failed to load model '%s': %w", name, err)
+		}
+	}
+
+	if *reloadInterval > 0 {
+		go s.WatchModels(context.Background(), *reloadInterval)
+	}
+	return s.ListenAndServe(*addr)
+}
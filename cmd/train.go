@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/dataset"
+	"DecisionTree/transform"
+	"DecisionTree/tree"
+	"flag"
+	"fmt"
+)
+
+func runTrain(args []string) error {
+	fs := flag.NewFlagSet("train", flag.ContinueOnError)
+	var (
+		attributesFile = fs.String("names", "dataset/adult.names", "attributes (names) file")
+		trainDataFile  = fs.String("data", "dataset/adult.data", "training data file")
+		preprocess     = fs.Bool("preprocess", true, "pre-process the data with dataset.PreProcessData")
+		modelFile      = fs.String("out", "tree.json", "output model file")
+		pipelineFile   = fs.String("pipeline", "", "preprocessing pipeline spec file to fit and save with the model, e.g. dataset/adult.pipeline.json")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var pipeline *transform.Pipeline
+	if *pipelineFile != "" {
+		var err error
+		pipeline, err = transform.ReadPipelineFromFile(*pipelineFile)
+		if err != nil {
+			return fmt.Errorf("failed to read pipeline: %w", err)
+		}
+	}
+
+	// read dataset
+	print("Reading dataset...")
+	var (
+		trainData *data.ValueTable
+		err       error
+	)
+	if pipeline != nil {
+		// the pipeline transforms the attributes, pre-processing only resamples the classes
+		_, trainData, err = readDataset(*attributesFile, *trainDataFile, false)
+		if err == nil && *preprocess {
+			dataset.Resample(trainData)
+		}
+	} else {
+		_, trainData, err = readDataset(*attributesFile, *trainDataFile, *preprocess)
+	}
+	if err != nil {
+		return err
+	}
+	print("OK\n")
+
+	// train decision tree
+	print("Training decision tree...")
+	var t *tree.Tree
+	if pipeline != nil {
+		t, err = tree.BuildTreeWithPipeline(config.Conf, pipeline, trainData)
+	} else {
+		t, err = tree.BuildTree(config.Conf, trainData)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to build tree: %w", err)
+	}
+	print("OK\n")
+
+	// save tree to file
+	print("Saving tree...")
+	err = tree.WriteTreeToFile(t, *modelFile)
+	if err != nil {
+		return fmt.Errorf("failed to save tree: %w", err)
+	}
+	print("OK\n")
+	return nil
+}
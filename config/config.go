@@ -15,12 +15,38 @@ type Config struct {
 	MinSamplesLeaf               int     `json:"min_samples_leaf"`
 	MinImpurityDecrease          float64 `json:"min_impurity_decrease"`
 
-	// For nominal attribute, if the number of accepted values is less than this value, use brute-force to find
-	// the best split. If not, we will first join the values with fewer instances until the number of values is
-	// less than or equal to this value, then perform brute-force.
+	// For nominal attribute with more than two classes (or with targets), if the number of values of the node is
+	// less than or equal to this value, use brute-force to find the best binary split. If not, the values are sorted
+	// by the proportion of every class in turn and only the cut points of these orderings are tried. The two-class
+	// binary splits are always found from a single ordering, which is exact.
 	// This value must >= 2.
 	MaxNominalBruteForceScale int `json:"max_nominal_brute_force_scale"`
 
+	// Candidate thresholds of the splits by continuous attributes: "" for the midpoints between all distinct values,
+	// or the bin edges of the instances of the node by "mdl", "width" or "frequency" (see transform.NewDiscretizers).
+	ContinuousSplitCandidates string `json:"continuous_split_candidates,omitempty"`
+	// Number of bins of the "width" and "frequency" split candidates, 10 if 0.
+	ContinuousSplitBins int `json:"continuous_split_bins,omitempty"`
+
+	// Whether to also search oblique splits, hyperplanes w·x < b over the continuous attributes (see
+	// tree.ObliqueCondition). An oblique split is used only if its gain exceeds the gain of the best axis-aligned
+	// split of the node by ObliqueSplitMargin.
+	ObliqueSplits      bool    `json:"oblique_splits,omitempty"`
+	ObliqueSplitMargin float64 `json:"oblique_split_margin,omitempty"`
+
+	// Whether to fit a linear model of the class indicators over the continuous attributes at every node, and predict
+	// by the models of the leaves smoothed along the path, as the model trees of M5 (see tree.LinearModel).
+	// After the pruning by the pessimistic error, model trees replace the subtrees by the models of their root nodes
+	// if the models predict the class indicators as well, as the pruning of M5.
+	ModelTree bool `json:"model_tree,omitempty"`
+	// k of the smoothing of model trees, 15 if 0, no smoothing if negative.
+	ModelTreeSmoothing float64 `json:"model_tree_smoothing,omitempty"`
+
+	// Layouts of time.Parse of the datetime values, tried after the layouts of the attribute (see data.DateTimeAttribute).
+	DateTimeLayouts []string `json:"datetime_layouts,omitempty"`
+	// Parts of the datetime values that are split on as well as the values: "year", "month", "weekday" and "hour".
+	DateTimeParts []string `json:"datetime_parts,omitempty"`
+
 	MinPostPruneGeneralizationErrorDecrease float64 `json:"min_post_prune_ge_decrease"`
 
 	VerboseLog bool   `json:"verbose_log"`
@@ -11,8 +11,15 @@ const (
 	Unknown    AttributeType = ""
 	Continuous AttributeType = "continuous"
 	Nominal    AttributeType = "nominal"
+	Ordinal    AttributeType = "ordinal"  // nominal attribute of which the values are ordered, see NewOrdinalAttribute
+	DateTime   AttributeType = "datetime" // continuous values of the seconds since the Unix epoch, see DateTimeAttribute
 )
 
+// IsNumeric tells whether the values of the type are float64, i.e. of continuous and datetime attributes.
+func (t AttributeType) IsNumeric() bool {
+	return t == Continuous || t == DateTime
+}
+
 type Attribute interface {
 	Name() string
 	Type() AttributeType
@@ -23,6 +30,10 @@ type ContinuousAttribute struct {
 	name string
 }
 
+func NewContinuousAttribute(name string) *ContinuousAttribute {
+	return &ContinuousAttribute{name: name}
+}
+
 func (c *ContinuousAttribute) Name() string {
 	return c.name
 }
@@ -42,6 +53,17 @@ func (c *ContinuousAttribute) Parse(conf *config.Config, value string) (Value, e
 type NominalAttribute struct {
 	name           string
 	AcceptedValues []string
+	ordered        bool // whether the accepted values are the levels of an ordinal attribute
+}
+
+func NewNominalAttribute(name string, acceptedValues []string) *NominalAttribute {
+	return &NominalAttribute{name: name, AcceptedValues: acceptedValues}
+}
+
+// NewOrdinalAttribute returns a nominal attribute of type Ordinal, of which the levels are the accepted values from
+// the lowest to the highest.
+func NewOrdinalAttribute(name string, levels []string) *NominalAttribute {
+	return &NominalAttribute{name: name, AcceptedValues: levels, ordered: true}
 }
 
 func (n *NominalAttribute) Name() string {
@@ -49,9 +71,22 @@ func (n *NominalAttribute) Name() string {
 }
 
 func (n *NominalAttribute) Type() AttributeType {
+	if n.ordered {
+		return Ordinal
+	}
 	return Nominal
 }
 
+// Level returns the index of the value in the levels of an ordinal attribute, -1 if it is not an accepted value.
+func (n *NominalAttribute) Level(value string) int {
+	for i, v := range n.AcceptedValues {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
 func (n *NominalAttribute) Parse(conf *config.Config, value string) (Value, error) {
 	res, err := newNominalValue(conf, n, value)
 	if err != nil {
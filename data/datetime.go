@@ -0,0 +1,146 @@
+package data
+
+import (
+	"DecisionTree/config"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultDateTimeLayouts are the layouts of the datetime values tried after those of the attribute and of the config.
+var DefaultDateTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+// DateTimeAttribute is an attribute of dates or datetimes. The values are parsed by the layouts of the attribute, the
+// DateTimeLayouts of the config and the DefaultDateTimeLayouts, in this order, and kept as continuous values of the
+// seconds since the Unix epoch, so that they are split like continuous values.
+// Values without a time zone are in UTC.
+type DateTimeAttribute struct {
+	name    string
+	Layouts []string // layouts of time.Parse
+}
+
+func NewDateTimeAttribute(name string, layouts []string) *DateTimeAttribute {
+	return &DateTimeAttribute{name: name, Layouts: layouts}
+}
+
+func (d *DateTimeAttribute) Name() string {
+	return d.name
+}
+
+func (d *DateTimeAttribute) Type() AttributeType {
+	return DateTime
+}
+
+func (d *DateTimeAttribute) Parse(conf *config.Config, value string) (Value, error) {
+	value = strings.TrimSpace(value)
+	if value == "?" {
+		return &ContinuousValue{attr: d, isMissing: true}, nil
+	}
+	var layouts []string
+	layouts = append(layouts, d.Layouts...)
+	layouts = append(layouts, conf.DateTimeLayouts...)
+	layouts = append(layouts, DefaultDateTimeLayouts...)
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return NewDateTimeValue(d, epochSeconds(t)), nil
+		}
+	}
+	if conf.ConsiderInvalidDataAsMissing {
+		return &ContinuousValue{attr: d, isMissing: true, invalidValue: value}, nil
+	}
+	return nil, fmt.Errorf("failed to parse datetime value: value '%s' does not match the layouts %q", value, layouts)
+}
+
+// Time returns the time of the seconds since the Unix epoch, in UTC.
+func (d *DateTimeAttribute) Time(seconds float64) time.Time {
+	sec := int64(seconds)
+	if float64(sec) > seconds {
+		sec-- // floor of negative values
+	}
+	return time.Unix(sec, int64((seconds-float64(sec))*1e9)).UTC()
+}
+
+// Format formats the seconds since the Unix epoch as a readable date, or datetime if not at midnight.
+func (d *DateTimeAttribute) Format(seconds float64) string {
+	t := d.Time(seconds)
+	switch {
+	case t.Nanosecond() != 0:
+		return t.Format("2006-01-02 15:04:05.999999999")
+	case t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0:
+		return t.Format("2006-01-02")
+	default:
+		return t.Format("2006-01-02 15:04:05")
+	}
+}
+
+// NewDateTimeValue returns a non-missing value of the attribute, of the seconds since the Unix epoch.
+func NewDateTimeValue(attr *DateTimeAttribute, seconds float64) *ContinuousValue {
+	return &ContinuousValue{attr: attr, value: seconds}
+}
+
+func epochSeconds(t time.Time) float64 {
+	return float64(t.Unix()) + float64(t.Nanosecond())/1e9
+}
+
+type DateTimePart string
+
+const (
+	Year    DateTimePart = "year"
+	Month   DateTimePart = "month"   // 1 to 12
+	Weekday DateTimePart = "weekday" // 0 (Sunday) to 6
+	Hour    DateTimePart = "hour"    // 0 to 23
+)
+
+// DateTimeParts are the parts of the datetime values that the trees can split on, see config.Config.DateTimeParts.
+var DateTimeParts = []DateTimePart{Year, Month, Weekday, Hour}
+
+// DateTimePartAttribute is a continuous attribute derived from a datetime attribute, e.g. the month of the dates.
+// Its values are not in the instances, Instance.GetValueByAttr derives them from the values of the datetime attribute.
+type DateTimePartAttribute struct {
+	Parent *DateTimeAttribute
+	Part   DateTimePart
+}
+
+func NewDateTimePartAttribute(parent *DateTimeAttribute, part DateTimePart) *DateTimePartAttribute {
+	return &DateTimePartAttribute{Parent: parent, Part: part}
+}
+
+// Name is the name of the datetime attribute followed by the part, e.g. "time.month".
+func (d *DateTimePartAttribute) Name() string {
+	return d.Parent.Name() + "." + string(d.Part)
+}
+
+func (d *DateTimePartAttribute) Type() AttributeType {
+	return Continuous
+}
+
+// Parse parses a datetime value of the datetime attribute, and returns its part.
+func (d *DateTimePartAttribute) Parse(conf *config.Config, value string) (Value, error) {
+	parentValue, err := d.Parent.Parse(conf, value)
+	if err != nil {
+		return nil, err
+	}
+	return d.Derive(parentValue), nil
+}
+
+// Derive returns the part of the value of the datetime attribute, missing if the value is missing.
+func (d *DateTimePartAttribute) Derive(value Value) Value {
+	if value == nil || value.IsMissing() {
+		return &ContinuousValue{attr: d, isMissing: true}
+	}
+	t := d.Parent.Time(value.Value().(float64))
+	var v int
+	switch d.Part {
+	case Year:
+		v = t.Year()
+	case Month:
+		v = int(t.Month())
+	case Weekday:
+		v = int(t.Weekday())
+	case Hour:
+		v = t.Hour()
+	default:
+		return &ContinuousValue{attr: d, isMissing: true}
+	}
+	return &ContinuousValue{attr: d, value: float64(v)}
+}
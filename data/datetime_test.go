@@ -0,0 +1,52 @@
+package data
+
+import (
+	"DecisionTree/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateTimeAttribute(t *testing.T) {
+	attr, err := handleAttributeLine("time: datetime 02/01/2006 | 02/01/2006 15:04.")
+	assert.NoError(t, err)
+	assert.Equal(t, DateTime, attr.Type())
+	dateTimeAttr := attr.(*DateTimeAttribute)
+	assert.Equal(t, []string{"02/01/2006", "02/01/2006 15:04"}, dateTimeAttr.Layouts)
+
+	conf := &config.Config{ConsiderInvalidDataAsMissing: true, DateTimeLayouts: []string{"Jan 2 2006"}}
+	for value, expected := range map[string]string{
+		"15/03/2021":           "2021-03-15",
+		"15/03/2021 18:30":     "2021-03-15 18:30:00",
+		"Mar 15 2021":          "2021-03-15",
+		"2021-03-15T18:30:00Z": "2021-03-15 18:30:00",
+		"2021-03-15":           "2021-03-15",
+	} {
+		v, err := attr.Parse(conf, value)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v.Log(), "value %s", value)
+	}
+	v, err := attr.Parse(conf, "2021-03-15T18:30:00+02:00")
+	assert.NoError(t, err)
+	assert.Equal(t, 1615825800.0, v.Value(), "seconds since the epoch in UTC")
+
+	v, err = attr.Parse(conf, "yesterday")
+	assert.NoError(t, err)
+	assert.True(t, v.IsMissing())
+	assert.Equal(t, "yesterday", v.InvalidValue())
+	_, err = attr.Parse(&config.Config{}, "yesterday")
+	assert.Error(t, err)
+
+	// the parts are derived from the values of the instances
+	instance := &Instance{AttributeValues: []Value{NewDateTimeValue(dateTimeAttr, 1615825800)}}
+	for part, expected := range map[DateTimePart]float64{Year: 2021, Month: 3, Weekday: 1, Hour: 16} {
+		v := instance.GetValueByAttr(NewDateTimePartAttribute(dateTimeAttr, part))
+		assert.Equal(t, expected, v.Value(), "part %s", part)
+	}
+	assert.Equal(t, "time.weekday", NewDateTimePartAttribute(dateTimeAttr, Weekday).Name())
+	assert.True(t, NewDateTimePartAttribute(dateTimeAttr, Month).Derive(NewMissingValue(dateTimeAttr)).IsMissing())
+
+	restored, err := NewPersistentAttribute(attr).ToAttribute()
+	assert.NoError(t, err)
+	assert.Equal(t, attr, restored)
+}
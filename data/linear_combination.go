@@ -0,0 +1,62 @@
+package data
+
+import (
+	"DecisionTree/config"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LinearCombinationAttribute is a continuous attribute derived from continuous attributes, the weighted sum of
+// their values, e.g. the left side of the oblique split w·x < b.
+// Its values are not in the instances, Instance.GetValueByAttr derives them from the values of the attributes.
+type LinearCombinationAttribute struct {
+	Attributes []Attribute
+	Weights    []float64
+}
+
+func NewLinearCombinationAttribute(attributes []Attribute, weights []float64) *LinearCombinationAttribute {
+	return &LinearCombinationAttribute{Attributes: attributes, Weights: weights}
+}
+
+// Name is the weighted sum of the names of the attributes, e.g. "0.5*age - 1.2*hours-per-week".
+func (l *LinearCombinationAttribute) Name() string {
+	var sb strings.Builder
+	for i, attr := range l.Attributes {
+		w := l.Weights[i]
+		switch {
+		case i == 0 && w < 0:
+			sb.WriteString("-")
+		case i > 0 && w < 0:
+			sb.WriteString(" - ")
+		case i > 0:
+			sb.WriteString(" + ")
+		}
+		if w < 0 {
+			w = -w
+		}
+		sb.WriteString(strconv.FormatFloat(w, 'g', 4, 64) + "*" + attr.Name())
+	}
+	return sb.String()
+}
+
+func (l *LinearCombinationAttribute) Type() AttributeType {
+	return Continuous
+}
+
+// Parse always fails, the values are derived from the values of the attributes, see Derive.
+func (l *LinearCombinationAttribute) Parse(_ *config.Config, _ string) (Value, error) {
+	return nil, fmt.Errorf("the values of '%s' are derived from the values of its attributes", l.Name())
+}
+
+// Derive returns the weighted sum of the values of the attributes, missing if any of them is missing.
+func (l *LinearCombinationAttribute) Derive(values []Value) Value {
+	sum := 0.0
+	for i, value := range values {
+		if value == nil || value.IsMissing() {
+			return &ContinuousValue{attr: l, isMissing: true}
+		}
+		sum += l.Weights[i] * value.Value().(float64)
+	}
+	return &ContinuousValue{attr: l, value: sum}
+}
@@ -0,0 +1,30 @@
+package data
+
+import (
+	"DecisionTree/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinearCombinationAttribute(t *testing.T) {
+	var (
+		conf  = &config.Config{ConsiderInvalidDataAsMissing: true}
+		x     = NewContinuousAttribute("x")
+		y     = NewContinuousAttribute("y")
+		class = NewNominalAttribute("Class", []string{"yes", "no"})
+		attr  = NewLinearCombinationAttribute([]Attribute{x, y}, []float64{0.5, -2})
+	)
+	assert.Equal(t, "0.5*x - 2*y", attr.Name())
+	assert.Equal(t, Continuous, attr.Type())
+	_, err := attr.Parse(conf, "1")
+	assert.Error(t, err)
+
+	instance, err := ParseInstance(conf, []Attribute{x, y}, class, map[string]string{"x": "4", "y": "1.5"})
+	assert.NoError(t, err)
+	assert.Equal(t, -1.0, instance.GetValueByAttr(attr).Value())
+
+	instance, err = ParseInstance(conf, []Attribute{x, y}, class, map[string]string{"x": "4", "y": "?"})
+	assert.NoError(t, err)
+	assert.True(t, instance.GetValueByAttr(attr).IsMissing())
+}
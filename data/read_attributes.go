@@ -11,6 +11,43 @@ import (
 type AttributeTable struct {
 	Attributes []Attribute
 	Class      *NominalAttribute
+	Targets    []*NominalAttribute // additional class attributes of multi-output data sets, see ReadAttributes
+
+	columns []Attribute // attributes and targets in the order of the data columns before the class, nil if no target
+}
+
+// dateTimePrefix starts the optional layouts of a datetime attribute in the attributes file.
+const dateTimePrefix = "datetime"
+
+// ordinalPrefix starts the ordered values of an ordinal attribute in the attributes file.
+const ordinalPrefix = "ordinal "
+
+// targetPrefix marks an additional class attribute in the attributes file.
+const targetPrefix = "@target "
+
+// dataColumns returns the attributes and targets in the order of the values in a data row, before the class value.
+func (a *AttributeTable) dataColumns() []Attribute {
+	if a.columns != nil {
+		return a.columns
+	}
+	res := append([]Attribute(nil), a.Attributes...)
+	for _, target := range a.Targets {
+		res = append(res, target)
+	}
+	return res
+}
+
+// IsTarget tells whether the attribute is the class attribute or an additional target.
+func (a *AttributeTable) IsTarget(name string) bool {
+	if a.Class != nil && a.Class.Name() == name {
+		return true
+	}
+	for _, target := range a.Targets {
+		if target.Name() == name {
+			return true
+		}
+	}
+	return false
 }
 
 func (a *AttributeTable) GetAttrByName(name string) Attribute {
@@ -27,7 +64,14 @@ func (a *AttributeTable) GetAttrByName(name string) Attribute {
 // <attribute name>: continuous.
 // or:
 // <attribute name>: <V1>, <V2>, <V3>.
+// or, for an ordinal attribute of which the values are ordered from the lowest to the highest:
+// <attribute name>: ordinal <V1>, <V2>, <V3>.
+// or, for a datetime attribute, with optional layouts of time.Parse separated by " | " (see DateTimeAttribute):
+// <attribute name>: datetime <layout 1> | <layout 2>.
 // The first attribute is the class attribute.
+// A line starting with "@target " is an additional class attribute of a multi-output data set, e.g.:
+// @target <attribute name>: <V1>, <V2>.
+// Its values are in its column among the other attributes in the data file, while the class value is the last one.
 // Empty lines or lines starting with '|' are ignored.
 func ReadAttributes(filepath string) (*AttributeTable, error) {
 	// Open file
@@ -46,8 +90,9 @@ func ReadAttributes(filepath string) (*AttributeTable, error) {
 	lineNo := 0
 	for scanner.Scan() {
 		lineNo++
-		line := scanner.Text()
-		attr, err := handleAttributeLine(line)
+		line := strings.TrimSpace(scanner.Text())
+		isTarget := strings.HasPrefix(line, targetPrefix)
+		attr, err := handleAttributeLine(strings.TrimPrefix(line, targetPrefix))
 		if err != nil {
 			log.Printf("Error parsing line %d: %s\n", lineNo, err)
 			continue
@@ -56,7 +101,14 @@ func ReadAttributes(filepath string) (*AttributeTable, error) {
 			continue
 		}
 
-		if table.Class == nil {
+		if isTarget {
+			nominalAttr, ok := attr.(*NominalAttribute)
+			if !ok || attr.Name() == "" {
+				return nil, fmt.Errorf("target attribute on line %d must be nominal and have a name", lineNo)
+			}
+			table.Targets = append(table.Targets, nominalAttr)
+			table.columns = append(table.columns, attr)
+		} else if table.Class == nil {
 			nominalAttr, ok := attr.(*NominalAttribute)
 			if !ok {
 				return nil, fmt.Errorf("first attribute is considered class attribute, it must be nominal")
@@ -70,8 +122,12 @@ func ReadAttributes(filepath string) (*AttributeTable, error) {
 				return nil, fmt.Errorf("non-class attribute on line %d does not have a name", lineNo)
 			}
 			table.Attributes = append(table.Attributes, attr)
+			table.columns = append(table.columns, attr)
 		}
 	}
+	if len(table.Targets) == 0 {
+		table.columns = nil
+	}
 
 	return table, nil
 }
@@ -94,16 +150,30 @@ func handleAttributeLine(line string) (Attribute, error) {
 		attrContent = strings.TrimSpace(parts[1])
 	}
 
-	// Check if the attribute is nominal or continuous
+	// Check if the attribute is nominal, ordinal, datetime or continuous
 	switch {
 	case attrContent == "continuous":
 		return &ContinuousAttribute{name: attrName}, nil
+	case attrContent == dateTimePrefix || strings.HasPrefix(attrContent, dateTimePrefix+" "):
+		var layouts []string
+		for _, layout := range strings.Split(strings.TrimPrefix(attrContent, dateTimePrefix), " | ") {
+			if layout = strings.TrimSpace(layout); layout != "" {
+				layouts = append(layouts, layout)
+			}
+		}
+		return &DateTimeAttribute{name: attrName, Layouts: layouts}, nil
+	case strings.HasPrefix(attrContent, ordinalPrefix):
+		return &NominalAttribute{name: attrName, AcceptedValues: splitValues(strings.TrimPrefix(attrContent, ordinalPrefix)), ordered: true}, nil
 	default:
 		// Parse nominal attribute values
-		values := strings.Split(attrContent, ",")
-		for i := range values {
-			values[i] = strings.TrimSpace(values[i])
-		}
-		return &NominalAttribute{name: attrName, AcceptedValues: values}, nil
+		return &NominalAttribute{name: attrName, AcceptedValues: splitValues(attrContent)}, nil
+	}
+}
+
+func splitValues(content string) []string {
+	values := strings.Split(content, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
 	}
+	return values
 }
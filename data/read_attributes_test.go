@@ -28,3 +28,36 @@ func TestReadAttributes(t *testing.T) {
 	assert.Equal(t, Nominal, res.Attributes[3].Type(), "Attribute4 type")
 	assert.Equal(t, []string{"D", "E", "F"}, res.Attributes[3].(*NominalAttribute).AcceptedValues, "Attribute4 list")
 }
+
+func TestReadAttributesWithTargets(t *testing.T) {
+	res, err := ReadAttributes("test_dataset/multi_output.names")
+	if err != nil {
+		t.Errorf("Error reading attributes: %s", err)
+		return
+	}
+
+	assert.Equal(t, []string{"OK", "Not OK"}, res.Class.AcceptedValues, "Class.AcceptedValues")
+	assert.Equal(t, 2, len(res.Attributes), "Number of attributes")
+	assert.Equal(t, "Attribute 2", res.Attributes[1].Name(), "Attribute 2 name")
+	assert.Equal(t, 2, len(res.Targets), "Number of targets")
+	assert.Equal(t, "Target 1", res.Targets[0].Name(), "Target 1 name")
+	assert.Equal(t, []string{"P", "Q"}, res.Targets[1].AcceptedValues, "Target 2 list")
+	assert.True(t, res.IsTarget("Class"))
+	assert.True(t, res.IsTarget("Target 2"))
+	assert.False(t, res.IsTarget("Attribute1"))
+}
+
+func TestReadOrdinalAttribute(t *testing.T) {
+	attr, err := handleAttributeLine("size: ordinal small, medium, large.")
+	assert.NoError(t, err)
+	assert.Equal(t, Ordinal, attr.Type(), "size type")
+	ordinalAttr := attr.(*NominalAttribute)
+	assert.Equal(t, []string{"small", "medium", "large"}, ordinalAttr.AcceptedValues, "size levels")
+	assert.Equal(t, 1, ordinalAttr.Level("medium"))
+	assert.Equal(t, -1, ordinalAttr.Level("huge"))
+
+	// the order is kept when the attribute is saved
+	restored, err := NewPersistentAttribute(attr).ToAttribute()
+	assert.NoError(t, err)
+	assert.Equal(t, attr, restored)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -30,9 +31,24 @@ func (v *ValueTable) RemoveAttribute(name string) {
 type Instance struct {
 	AttributeValues []Value
 	ClassValue      *NominalValue
+	TargetValues    []*NominalValue // values of the additional targets of multi-output data sets, see AttributeTable.Targets
 }
 
 func (i *Instance) GetValueByAttr(attr Attribute) Value {
+	if part, ok := attr.(*DateTimePartAttribute); ok {
+		parentValue := i.GetValueByAttr(part.Parent)
+		if parentValue == nil {
+			return nil
+		}
+		return part.Derive(parentValue)
+	}
+	if combination, ok := attr.(*LinearCombinationAttribute); ok {
+		values := make([]Value, len(combination.Attributes))
+		for j, a := range combination.Attributes {
+			values[j] = i.GetValueByAttr(a)
+		}
+		return combination.Derive(values)
+	}
 	for _, value := range i.AttributeValues {
 		if value.Attribute().Name() == attr.Name() {
 			return value
@@ -59,6 +75,13 @@ func (i *Instance) String() string {
 			sb.WriteString(fmt.Sprintf("%v, ", value.Value()))
 		}
 	}
+	for _, value := range i.TargetValues {
+		if value.IsMissing() {
+			sb.WriteString("?, ")
+		} else {
+			sb.WriteString(fmt.Sprintf("%v, ", value.Value()))
+		}
+	}
 	if i.ClassValue.IsMissing() {
 		sb.WriteString("?")
 	} else {
@@ -113,25 +136,30 @@ func handleInstanceLine(conf *config.Config, attrTable *AttributeTable, line str
 	}
 
 	dataValues := strings.Split(line, ",")
+	columns := attrTable.dataColumns()
 
 	// If data is not sufficient, return error
-	if len(dataValues) < len(attrTable.Attributes) {
-		return nil, fmt.Errorf("insufficient data values, expected %d, got %d", len(attrTable.Attributes), len(dataValues))
+	if len(dataValues) < len(columns) {
+		return nil, fmt.Errorf("insufficient data values, expected %d, got %d", len(columns), len(dataValues))
 	}
 
 	// Parse each value
 	instance := &Instance{}
-	for i, attr := range attrTable.Attributes {
+	for i, attr := range columns {
 		dataValue := dataValues[i]
 		value, err := attr.Parse(conf, dataValue)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse value '%s': %w", dataValue, err)
 		}
-		instance.AttributeValues = append(instance.AttributeValues, value)
+		if attrTable.IsTarget(attr.Name()) {
+			instance.TargetValues = append(instance.TargetValues, value.(*NominalValue))
+		} else {
+			instance.AttributeValues = append(instance.AttributeValues, value)
+		}
 	}
 
 	// Parse class value
-	if len(dataValues) >= len(attrTable.Attributes)+1 {
+	if len(dataValues) >= len(columns)+1 {
 		classValue, err := attrTable.Class.Parse(conf, dataValues[len(dataValues)-1])
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse class value '%s': %w", dataValues[len(dataValues)-1], err)
@@ -147,3 +175,74 @@ func handleInstanceLine(conf *config.Config, attrTable *AttributeTable, line str
 
 	return instance, nil
 }
+
+// ParseInstance builds an instance from a row keyed by attribute name, e.g. a decoded JSON request.
+// Attributes absent from the row are treated as missing values ("?"), and so is the class value
+// when classAttr is nil or the row does not contain it.
+func ParseInstance(conf *config.Config, attributes []Attribute, classAttr *NominalAttribute, row map[string]string) (*Instance, error) {
+	instance := &Instance{}
+	for _, attr := range attributes {
+		dataValue, ok := row[attr.Name()]
+		if !ok {
+			dataValue = "?"
+		}
+		value, err := attr.Parse(conf, dataValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse value '%s' of attribute '%s': %w", dataValue, attr.Name(), err)
+		}
+		instance.AttributeValues = append(instance.AttributeValues, value)
+	}
+
+	classDataValue := "?"
+	if classAttr == nil {
+		classAttr = &NominalAttribute{name: "Class"}
+	} else if v, ok := row[classAttr.Name()]; ok {
+		classDataValue = v
+	}
+	classValue, err := newNominalValue(conf, classAttr, classDataValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse class value '%s': %w", classDataValue, err)
+	}
+	instance.ClassValue = classValue.(*NominalValue)
+
+	return instance, nil
+}
+
+// ParseTargetValues parses the values of the additional targets from a row keyed by attribute name, targets absent
+// from the row are missing.
+func ParseTargetValues(conf *config.Config, targets []*NominalAttribute, row map[string]string) ([]*NominalValue, error) {
+	var res []*NominalValue
+	for _, target := range targets {
+		dataValue, ok := row[target.Name()]
+		if !ok {
+			dataValue = "?"
+		}
+		value, err := newNominalValue(conf, target, dataValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse value '%s' of target '%s': %w", dataValue, target.Name(), err)
+		}
+		res = append(res, value.(*NominalValue))
+	}
+	return res, nil
+}
+
+// StringRowFromJson converts a decoded JSON object into a row for ParseInstance.
+// JSON null is converted into a missing value ("?").
+func StringRowFromJson(row map[string]interface{}) (map[string]string, error) {
+	res := make(map[string]string)
+	for k, v := range row {
+		switch val := v.(type) {
+		case nil:
+			res[k] = "?"
+		case string:
+			res[k] = val
+		case float64:
+			res[k] = strconv.FormatFloat(val, 'f', -1, 64)
+		case bool:
+			res[k] = strconv.FormatBool(val)
+		default:
+			return nil, fmt.Errorf("unsupported value type %T of attribute '%s'", v, k)
+		}
+	}
+	return res, nil
+}
@@ -3,6 +3,8 @@ package data
 import (
 	"DecisionTree/config"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestReadValues(t *testing.T) {
@@ -19,3 +21,26 @@ func TestReadValues(t *testing.T) {
 
 	t.Log(res.String())
 }
+
+func TestReadValuesWithTargets(t *testing.T) {
+	attrTable, err := ReadAttributes("test_dataset/multi_output.names")
+	if err != nil {
+		t.Errorf("Error reading attributes: %s", err)
+		return
+	}
+	res, err := ReadValues(&config.Config{ConsiderInvalidDataAsMissing: true}, attrTable, "test_dataset/multi_output.data")
+	if err != nil {
+		t.Errorf("Error reading data: %s", err)
+		return
+	}
+
+	assert.Equal(t, 3, len(res.Instances), "Number of instances")
+	instance := res.Instances[1]
+	assert.Equal(t, 2, len(instance.AttributeValues), "Number of attribute values")
+	assert.Equal(t, "B", instance.AttributeValues[1].Value(), "Attribute 2 value")
+	assert.Equal(t, "Y", instance.TargetValues[0].Value(), "Target 1 value")
+	assert.True(t, instance.TargetValues[1].IsMissing(), "Target 2 value")
+	assert.Equal(t, "Not OK", instance.ClassValue.Value(), "Class value")
+	assert.True(t, res.Instances[2].ClassValue.IsMissing(), "Class value without class column")
+	assert.Equal(t, "Q", res.Instances[2].TargetValues[1].Value(), "Target 2 value without class column")
+}
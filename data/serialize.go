@@ -3,15 +3,24 @@ package data
 import "fmt"
 
 type PersistentAttribute struct {
-	Name string        `json:"name"`
-	Type AttributeType `json:"type"`
+	Name           string        `json:"name"`
+	Type           AttributeType `json:"type"`
+	AcceptedValues []string      `json:"accepted_values,omitempty"`
+	Layouts        []string      `json:"layouts,omitempty"` // of datetime attributes
 }
 
 func NewPersistentAttribute(attr Attribute) *PersistentAttribute {
-	return &PersistentAttribute{
+	p := &PersistentAttribute{
 		Name: attr.Name(),
 		Type: attr.Type(),
 	}
+	if nominalAttr, ok := attr.(*NominalAttribute); ok {
+		p.AcceptedValues = nominalAttr.AcceptedValues
+	}
+	if dateTimeAttr, ok := attr.(*DateTimeAttribute); ok {
+		p.Layouts = dateTimeAttr.Layouts
+	}
+	return p
 }
 
 func (p *PersistentAttribute) ToAttribute() (Attribute, error) {
@@ -19,7 +28,11 @@ func (p *PersistentAttribute) ToAttribute() (Attribute, error) {
 	case Continuous:
 		return &ContinuousAttribute{name: p.Name}, nil
 	case Nominal:
-		return &NominalAttribute{name: p.Name}, nil
+		return &NominalAttribute{name: p.Name, AcceptedValues: p.AcceptedValues}, nil
+	case DateTime:
+		return &DateTimeAttribute{name: p.Name, Layouts: p.Layouts}, nil
+	case Ordinal:
+		return &NominalAttribute{name: p.Name, AcceptedValues: p.AcceptedValues, ordered: true}, nil
 	default:
 		return nil, fmt.Errorf("unknown attribute type: %s", p.Type)
 	}
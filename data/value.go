@@ -12,12 +12,15 @@ type Value interface {
 	IsMissing() bool
 	Value() interface{}
 	Log() string
+	// InvalidValue returns the raw value that was considered as missing because it is invalid, empty otherwise.
+	InvalidValue() string
 }
 
 type ContinuousValue struct {
-	attr      Attribute
-	isMissing bool
-	value     float64
+	attr         Attribute
+	isMissing    bool
+	value        float64
+	invalidValue string
 }
 
 func newContinuousValue(conf *config.Config, attr *ContinuousAttribute, value string) (Value, error) {
@@ -33,8 +36,9 @@ func newContinuousValue(conf *config.Config, attr *ContinuousAttribute, value st
 	if err != nil {
 		if conf.ConsiderInvalidDataAsMissing {
 			return &ContinuousValue{
-				attr:      attr,
-				isMissing: true,
+				attr:         attr,
+				isMissing:    true,
+				invalidValue: value,
 			}, nil
 		}
 		return nil, fmt.Errorf("failed to parse value '%s' to float: %w", value, err)
@@ -45,6 +49,14 @@ func newContinuousValue(conf *config.Config, attr *ContinuousAttribute, value st
 	}, nil
 }
 
+// NewContinuousValue returns a non-missing value of the attribute.
+func NewContinuousValue(attr *ContinuousAttribute, value float64) *ContinuousValue {
+	return &ContinuousValue{
+		attr:  attr,
+		value: value,
+	}
+}
+
 func (c ContinuousValue) Attribute() Attribute {
 	return c.attr
 }
@@ -57,10 +69,17 @@ func (c ContinuousValue) Value() interface{} {
 	return c.value
 }
 
+func (c ContinuousValue) InvalidValue() string {
+	return c.invalidValue
+}
+
 func (c ContinuousValue) Log() string {
 	if c.isMissing {
 		return "<missing>"
 	}
+	if dateTimeAttr, ok := c.attr.(*DateTimeAttribute); ok {
+		return dateTimeAttr.Format(c.value)
+	}
 	return fmt.Sprintf("%f", c.value)
 }
 
@@ -73,6 +92,14 @@ func newNominalValue(conf *config.Config, attr *NominalAttribute, value string)
 		}, nil
 	}
 
+	// attributes restored from older model files do not know their accepted values, accept anything
+	if len(attr.AcceptedValues) == 0 {
+		return &NominalValue{
+			attr:  attr,
+			value: value,
+		}, nil
+	}
+
 	// check if value is in accepted values
 	for _, acceptedValue := range attr.AcceptedValues {
 		if value == acceptedValue {
@@ -85,17 +112,35 @@ func newNominalValue(conf *config.Config, attr *NominalAttribute, value string)
 	// value is not in accepted values
 	if conf.ConsiderInvalidDataAsMissing {
 		return &NominalValue{
-			attr:      attr,
-			isMissing: true,
+			attr:         attr,
+			isMissing:    true,
+			invalidValue: value,
 		}, nil
 	}
 	return nil, fmt.Errorf("value '%s' is not in accepted values", value)
 }
 
 type NominalValue struct {
-	attr      Attribute
-	isMissing bool
-	value     string
+	attr         Attribute
+	isMissing    bool
+	value        string
+	invalidValue string
+}
+
+// NewNominalValue returns a non-missing value of the attribute, the value is not checked against the accepted values.
+func NewNominalValue(attr *NominalAttribute, value string) *NominalValue {
+	return &NominalValue{
+		attr:  attr,
+		value: value,
+	}
+}
+
+// NewMissingValue returns a missing value of the attribute.
+func NewMissingValue(attr Attribute) Value {
+	if nominalAttr, ok := attr.(*NominalAttribute); ok {
+		return &NominalValue{attr: nominalAttr, isMissing: true}
+	}
+	return &ContinuousValue{attr: attr, isMissing: true}
 }
 
 func (n NominalValue) Attribute() Attribute {
@@ -110,6 +155,10 @@ func (n NominalValue) Value() interface{} {
 	return n.value
 }
 
+func (n NominalValue) InvalidValue() string {
+	return n.invalidValue
+}
+
 func (n NominalValue) Log() string {
 	if n.isMissing {
 		return "<missing>"
@@ -3,6 +3,21 @@ package dataset
 import "DecisionTree/data"
 
 func PreProcessData(valueTable *data.ValueTable) {
+	Resample(valueTable)
+	PreProcessAttributes(valueTable)
+}
+
+// PreProcessAttributes removes the attributes PreProcessData removes, without resampling. It is used with the data
+// sets a tree is evaluated on, whose rows must not be duplicated.
+func PreProcessAttributes(valueTable *data.ValueTable) {
+	// education and education-num are the same
+	// remove education-num
+	valueTable.RemoveAttribute("education-num")
+}
+
+// Resample balances the classes of the data set. It does not change the attributes, so it is also used with the
+// pipeline in adult.pipeline.json, which drops education-num instead.
+func Resample(valueTable *data.ValueTable) {
 	// 75% is <=50K, 25% is >50K
 	// Resample the data to make it balanced
 	dataInstances := make(map[string][]*data.Instance)
@@ -12,8 +27,4 @@ func PreProcessData(valueTable *data.ValueTable) {
 	}
 	valueTable.Instances = append(valueTable.Instances, dataInstances[">50K"]...)
 	valueTable.Instances = append(valueTable.Instances, dataInstances[">50K"]...)
-
-	// education and education-num are the same
-	// remove education-num
-	valueTable.RemoveAttribute("education-num")
 }
@@ -0,0 +1,327 @@
+package drift
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Report compares a data set to the training stats of a tree.
+type Report struct {
+	InstanceCount         int               `json:"instance_count"`
+	TrainingInstanceCount int               `json:"training_instance_count"`
+	Attributes            []*AttributeDrift `json:"attributes"`
+	Class                 *AttributeDrift   `json:"class,omitempty"` // nil if the data set has no class values
+	Leaves                *LeafDrift        `json:"leaves"`
+	Warnings              []string          `json:"warnings,omitempty"`
+}
+
+type AttributeDrift struct {
+	Name                string             `json:"name"`
+	Type                data.AttributeType `json:"type"`
+	TrainingMissingRate float64            `json:"training_missing_rate"`
+	MissingRate         float64            `json:"missing_rate"`
+	PSI                 float64            `json:"psi"` // population stability index of the non-missing values
+	UnseenValues        []*UnseenValue     `json:"unseen_values,omitempty"`
+	OutOfRange          int                `json:"out_of_range,omitempty"` // continuous values outside the training range
+	Drifted             bool               `json:"drifted"`
+}
+
+// UnseenValue is a nominal value that never occurs in the training data. Invalid values are not accepted by the
+// attribute at all, and have been considered as missing.
+type UnseenValue struct {
+	Value   string `json:"value"`
+	Count   int    `json:"count"`
+	Invalid bool   `json:"invalid,omitempty"`
+}
+
+// LeafDrift compares the fraction of instances reaching each leaf.
+type LeafDrift struct {
+	PSI    float64          `json:"psi"`
+	Leaves []*LeafOccupancy `json:"leaves"`
+}
+
+type LeafOccupancy struct {
+	UniqId            int     `json:"uniq_id"`
+	LeafClass         string  `json:"leaf_class"`
+	TrainingFrequency float64 `json:"training_frequency"`
+	Frequency         float64 `json:"frequency"`
+}
+
+type Options struct {
+	PSIThreshold         float64 // an attribute drifts if its PSI is at least this, 0.2 if 0
+	MissingRateThreshold float64 // an attribute drifts if its missing rate changes by more than this, 0.05 if 0
+}
+
+// psiFloor replaces empty bins in PSI, which is infinite otherwise.
+const psiFloor = 1e-4
+
+func (o *Options) withDefaults() *Options {
+	res := &Options{PSIThreshold: 0.2, MissingRateThreshold: 0.05}
+	if o != nil {
+		if o.PSIThreshold > 0 {
+			res.PSIThreshold = o.PSIThreshold
+		}
+		if o.MissingRateThreshold > 0 {
+			res.MissingRateThreshold = o.MissingRateThreshold
+		}
+	}
+	return res
+}
+
+// Check compares the data set with the training stats of the tree. An attribute drifts if its PSI or missing rate
+// exceeds the thresholds, or if it has unseen values, and every drift is explained by a warning.
+// The data set is transformed by the pipeline of the tree first, if any.
+func Check(tr *tree.Tree, valueTable *data.ValueTable, opts *Options) (*Report, error) {
+	stats := tr.TrainingStats
+	if stats == nil {
+		return nil, fmt.Errorf("model has no training stats, retrain it to check drift")
+	}
+	if len(valueTable.Instances) == 0 {
+		return nil, fmt.Errorf("no instances")
+	}
+	valueTable, err := tr.TransformTable(valueTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform data: %w", err)
+	}
+	opts = opts.withDefaults()
+	res := &Report{
+		InstanceCount:         len(valueTable.Instances),
+		TrainingInstanceCount: stats.InstanceCount,
+	}
+
+	for _, attr := range tr.Attributes {
+		attrStats := stats.Attributes[attr.Name()]
+		if attrStats == nil {
+			return nil, fmt.Errorf("training stats have no attribute '%s'", attr.Name())
+		}
+		values := make([]data.Value, len(valueTable.Instances))
+		for i, instance := range valueTable.Instances {
+			values[i] = instance.GetValueByAttr(attr)
+			if values[i] == nil {
+				return nil, fmt.Errorf("instance %d has no attribute '%s'", i, attr.Name())
+			}
+		}
+		res.Attributes = append(res.Attributes, checkAttribute(attr.Name(), attr.Type(), attrStats, values, opts))
+	}
+
+	if stats.Class != nil {
+		values := make([]data.Value, len(valueTable.Instances))
+		labeled := false
+		for i, instance := range valueTable.Instances {
+			values[i] = instance.ClassValue
+			labeled = labeled || !values[i].IsMissing() || values[i].InvalidValue() != ""
+		}
+		if labeled {
+			name := "Class"
+			if tr.Class != nil {
+				name = tr.Class.Name()
+			}
+			res.Class = checkAttribute(name, data.Nominal, stats.Class, values, opts)
+		}
+	}
+
+	res.Leaves = checkLeaves(tr, valueTable)
+
+	for _, attr := range res.Attributes {
+		res.Warnings = append(res.Warnings, attributeWarnings(attr, opts)...)
+	}
+	if res.Class != nil {
+		res.Warnings = append(res.Warnings, attributeWarnings(res.Class, opts)...)
+	}
+	if res.Leaves.PSI >= opts.PSIThreshold {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("leaf occupancy: PSI %.4f", res.Leaves.PSI))
+	}
+	return res, nil
+}
+
+func checkAttribute(name string, attrType data.AttributeType, stats *tree.AttributeStats, values []data.Value, opts *Options) *AttributeDrift {
+	res := &AttributeDrift{
+		Name:                name,
+		Type:                attrType,
+		TrainingMissingRate: stats.MissingRate,
+	}
+	var (
+		missing  int
+		known    int
+		nominal  = make(map[string]float64)
+		bins     = make([]float64, len(stats.Bins))
+		unseen   = make(map[string]int)
+		invalids = make(map[string]int)
+	)
+	for _, value := range values {
+		if value.IsMissing() {
+			missing++
+			if value.InvalidValue() != "" {
+				invalids[value.InvalidValue()]++
+			}
+			continue
+		}
+		known++
+		if attrType.IsNumeric() {
+			v := value.Value().(float64)
+			if len(bins) > 0 {
+				bins[stats.Bin(v)]++
+			}
+			if v < stats.Min || v > stats.Max {
+				res.OutOfRange++
+			}
+			continue
+		}
+		v := value.Value().(string)
+		nominal[v]++
+		if _, ok := stats.Values[v]; !ok {
+			unseen[v]++
+		}
+	}
+	res.MissingRate = float64(missing) / float64(len(values))
+
+	if known > 0 {
+		if attrType.IsNumeric() {
+			for i := range bins {
+				bins[i] /= float64(known)
+			}
+			res.PSI = PSI(stats.Bins, bins)
+		} else {
+			var expected, actual []float64
+			for _, v := range sortedKeys(stats.Values, nominal) {
+				expected = append(expected, stats.Values[v])
+				actual = append(actual, nominal[v]/float64(known))
+			}
+			res.PSI = PSI(expected, actual)
+		}
+	}
+
+	for v, count := range unseen {
+		res.UnseenValues = append(res.UnseenValues, &UnseenValue{Value: v, Count: count})
+	}
+	for v, count := range invalids {
+		res.UnseenValues = append(res.UnseenValues, &UnseenValue{Value: v, Count: count, Invalid: true})
+	}
+	sort.Slice(res.UnseenValues, func(i, j int) bool {
+		if res.UnseenValues[i].Count != res.UnseenValues[j].Count {
+			return res.UnseenValues[i].Count > res.UnseenValues[j].Count
+		}
+		return res.UnseenValues[i].Value < res.UnseenValues[j].Value
+	})
+
+	res.Drifted = res.PSI >= opts.PSIThreshold ||
+		math.Abs(res.MissingRate-res.TrainingMissingRate) > opts.MissingRateThreshold ||
+		len(res.UnseenValues) > 0
+	return res
+}
+
+func checkLeaves(tr *tree.Tree, valueTable *data.ValueTable) *LeafDrift {
+	var (
+		res    = &LeafDrift{}
+		counts = make(map[int]float64)
+	)
+	for _, instance := range valueTable.Instances {
+		// the instances are transformed already, so the nodes are walked instead of Tree.PredictPath
+		node := tr.RootNode
+		for node != nil && len(node.Children) > 0 {
+			node = node.GetRelatedChild(instance)
+		}
+		if node != nil {
+			counts[node.UniqId()]++
+		}
+	}
+	var expected, actual []float64
+	for _, leaf := range tr.GetLeafNodes() {
+		occupancy := &LeafOccupancy{
+			UniqId:            leaf.UniqId(),
+			LeafClass:         leaf.LeafClass,
+			TrainingFrequency: tr.TrainingStats.LeafOccupancy[leaf.UniqId()],
+			Frequency:         counts[leaf.UniqId()] / float64(len(valueTable.Instances)),
+		}
+		res.Leaves = append(res.Leaves, occupancy)
+		expected = append(expected, occupancy.TrainingFrequency)
+		actual = append(actual, occupancy.Frequency)
+	}
+	res.PSI = PSI(expected, actual)
+	return res
+}
+
+func attributeWarnings(attr *AttributeDrift, opts *Options) []string {
+	var res []string
+	if attr.PSI >= opts.PSIThreshold {
+		res = append(res, fmt.Sprintf("attribute '%s': PSI %.4f", attr.Name, attr.PSI))
+	}
+	if math.Abs(attr.MissingRate-attr.TrainingMissingRate) > opts.MissingRateThreshold {
+		res = append(res, fmt.Sprintf("attribute '%s': missing rate changed from %.2f%% to %.2f%%", attr.Name, attr.TrainingMissingRate*100, attr.MissingRate*100))
+	}
+	for _, v := range attr.UnseenValues {
+		if v.Invalid {
+			res = append(res, fmt.Sprintf("attribute '%s': invalid value '%s' considered as missing in %d instances", attr.Name, v.Value, v.Count))
+		} else {
+			res = append(res, fmt.Sprintf("attribute '%s': value '%s' never seen in training, in %d instances", attr.Name, v.Value, v.Count))
+		}
+	}
+	return res
+}
+
+// PSI is the population stability index of the actual distribution against the expected one, over the same bins:
+// the sum of (actual - expected) * ln(actual / expected). Empty bins count as 0.0001.
+// Below 0.1 is usually considered stable, and 0.2 or above a significant shift.
+func PSI(expected, actual []float64) float64 {
+	var res float64
+	for i := range expected {
+		e, a := math.Max(expected[i], psiFloor), math.Max(actual[i], psiFloor)
+		res += (a - e) * math.Log(a/e)
+	}
+	return res
+}
+
+// InstanceWarnings checks a single instance to predict against the training stats of the tree: invalid values which
+// have been considered as missing, nominal values never seen in training and continuous values outside the training
+// range, after the pipeline of the tree if any. It returns nil if the tree has no training stats.
+func InstanceWarnings(tr *tree.Tree, instance *data.Instance) []string {
+	if tr.TrainingStats == nil {
+		return nil
+	}
+	instance, err := tr.Transform(instance)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var res []string
+	for _, attr := range tr.Attributes {
+		var (
+			stats = tr.TrainingStats.Attributes[attr.Name()]
+			value = instance.GetValueByAttr(attr)
+		)
+		if stats == nil || value == nil {
+			continue
+		}
+		switch {
+		case value.InvalidValue() != "":
+			res = append(res, fmt.Sprintf("attribute '%s': invalid value '%s' considered as missing", attr.Name(), value.InvalidValue()))
+		case value.IsMissing():
+		case attr.Type().IsNumeric():
+			if v := value.Value().(float64); v < stats.Min || v > stats.Max {
+				res = append(res, fmt.Sprintf("attribute '%s': value %g outside the training range [%g, %g]", attr.Name(), v, stats.Min, stats.Max))
+			}
+		default:
+			if _, ok := stats.Values[value.Value().(string)]; !ok {
+				res = append(res, fmt.Sprintf("attribute '%s': value '%s' never seen in training", attr.Name(), value.Value()))
+			}
+		}
+	}
+	return res
+}
+
+func sortedKeys(maps ...map[string]float64) []string {
+	keys := make(map[string]bool)
+	for _, m := range maps {
+		for k := range m {
+			keys[k] = true
+		}
+	}
+	var res []string
+	for k := range keys {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
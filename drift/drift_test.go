@@ -0,0 +1,190 @@
+package drift
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testConf = &config.Config{ConsiderInvalidDataAsMissing: true}
+
+// newTestTree returns the tree: x < 10 (prioritized) -> a, x >= 10 -> b, with the training stats of x = 1..20,
+// colored red up to 10 and green above, and classified as the tree does.
+func newTestTree(t *testing.T) *tree.Tree {
+	var (
+		x         = data.NewContinuousAttribute("x")
+		color     = data.NewNominalAttribute("color", []string{"red", "green", "blue"})
+		classAttr = data.NewNominalAttribute("Class", []string{"a", "b"})
+	)
+	tr := &tree.Tree{
+		Attributes: []data.Attribute{x, color},
+		Class:      classAttr,
+		RootNode: &tree.Node{Children: []*tree.Node{
+			{Condition: tree.NewLessThanCondition(x, 10), IsPrioritized: true, LeafClass: "a", ClassCount: map[string]float64{"a": 9}},
+			{Condition: tree.NewGreaterThanEqCondition(x, 10), LeafClass: "b", ClassCount: map[string]float64{"b": 11}},
+		}},
+	}
+	var rows []map[string]string
+	for i := 1; i <= 20; i++ {
+		row := map[string]string{"x": strconv.Itoa(i), "color": "red", "Class": "a"}
+		if i > 10 {
+			row["color"] = "green"
+		}
+		if i >= 10 {
+			row["Class"] = "b"
+		}
+		rows = append(rows, row)
+	}
+	tr.TrainingStats = tree.NewTrainingStats(tr, newValueTable(t, tr, rows))
+	return tr
+}
+
+func newValueTable(t *testing.T, tr *tree.Tree, rows []map[string]string) *data.ValueTable {
+	res := &data.ValueTable{}
+	for _, row := range rows {
+		instance, err := data.ParseInstance(testConf, tr.Attributes, tr.Class, row)
+		if err != nil {
+			t.Fatalf("failed to parse instance: %v", err)
+		}
+		res.Instances = append(res.Instances, instance)
+	}
+	return res
+}
+
+func TestTrainingStats(t *testing.T) {
+	stats := newTestTree(t).TrainingStats
+	assert.Equal(t, 20, stats.InstanceCount)
+
+	x := stats.Attributes["x"]
+	assert.Equal(t, 0.0, x.MissingRate)
+	assert.Equal(t, 1.0, x.Min)
+	assert.Equal(t, 20.0, x.Max)
+	assert.Equal(t, []float64{2, 4, 6, 8, 10, 12, 14, 16, 18}, x.BinEdges)
+	assert.Equal(t, []float64{0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1}, x.Bins)
+	assert.Equal(t, 0, x.Bin(2))
+	assert.Equal(t, 1, x.Bin(2.5))
+	assert.Equal(t, 9, x.Bin(100))
+
+	assert.Equal(t, map[string]float64{"red": 0.5, "green": 0.5}, stats.Attributes["color"].Values)
+	assert.Equal(t, map[string]float64{"a": 0.45, "b": 0.55}, stats.Class.Values)
+	assert.Len(t, stats.LeafOccupancy, 2)
+}
+
+func TestCheck(t *testing.T) {
+	tr := newTestTree(t)
+
+	// the same data does not drift
+	var same []map[string]string
+	for i := 1; i <= 20; i++ {
+		same = append(same, map[string]string{"x": strconv.Itoa(i), "color": map[bool]string{true: "red", false: "green"}[i <= 10]})
+	}
+	report, err := Check(tr, newValueTable(t, tr, same), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Warnings)
+	assert.Nil(t, report.Class, "the data set is not labeled")
+	assert.InDelta(t, 0, report.Attributes[0].PSI, 1e-9)
+	assert.InDelta(t, 0, report.Leaves.PSI, 1e-9)
+
+	shifted := []map[string]string{
+		{"x": "15", "color": "blue"}, {"x": "16", "color": "blue"}, {"x": "17", "color": "blue"}, {"x": "18", "color": "blue"},
+		{"x": "19", "color": "red"}, {"x": "20", "color": "red"}, {"x": "25", "color": "green"}, {"x": "30", "color": "green"},
+		{"x": "?", "color": "green"}, {"x": "abc", "color": "purple"},
+	}
+	report, err = Check(tr, newValueTable(t, tr, shifted), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, report.InstanceCount)
+	assert.Equal(t, 20, report.TrainingInstanceCount)
+
+	x := report.Attributes[0]
+	assert.InDelta(t, 0.2, x.MissingRate, 1e-9)
+	assert.InDelta(t, 5.74925565, x.PSI, 1e-6)
+	assert.Equal(t, 2, x.OutOfRange)
+	assert.Equal(t, []*UnseenValue{{Value: "abc", Count: 1, Invalid: true}}, x.UnseenValues)
+	assert.True(t, x.Drifted)
+
+	color := report.Attributes[1]
+	assert.InDelta(t, 0.1, color.MissingRate, 1e-9)
+	assert.InDelta(t, 4.02506715, color.PSI, 1e-6)
+	assert.Equal(t, []*UnseenValue{{Value: "blue", Count: 4}, {Value: "purple", Count: 1, Invalid: true}}, color.UnseenValues)
+
+	// the missing values go along the prioritized leaf a
+	assert.Equal(t, 0.45, report.Leaves.Leaves[0].TrainingFrequency)
+	assert.InDelta(t, 0.2, report.Leaves.Leaves[0].Frequency, 1e-9)
+	assert.InDelta(t, 0.29640592, report.Leaves.PSI, 1e-6)
+
+	assert.Contains(t, report.Warnings, "attribute 'x': missing rate changed from 0.00% to 20.00%")
+	assert.Contains(t, report.Warnings, "attribute 'color': value 'blue' never seen in training, in 4 instances")
+	assert.Contains(t, report.Warnings, "attribute 'color': invalid value 'purple' considered as missing in 1 instances")
+	assert.Contains(t, report.Warnings, "leaf occupancy: PSI 0.2964")
+
+	// higher thresholds ignore the leaf occupancy and the missing rate, not the unseen values
+	report, err = Check(tr, newValueTable(t, tr, shifted), &Options{PSIThreshold: 10, MissingRateThreshold: 0.5})
+	assert.NoError(t, err)
+	assert.True(t, report.Attributes[0].Drifted)
+	assert.NotContains(t, report.Warnings, "leaf occupancy: PSI 0.2964")
+
+	tr.TrainingStats = nil
+	_, err = Check(tr, newValueTable(t, tr, shifted), nil)
+	assert.Error(t, err)
+}
+
+func TestCheckClass(t *testing.T) {
+	tr := newTestTree(t)
+	// a trailing period does not match the accepted class values
+	report, err := Check(tr, newValueTable(t, tr, []map[string]string{
+		{"x": "1", "color": "red", "Class": "a."}, {"x": "12", "color": "green", "Class": "b"},
+	}), nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, report.Class)
+	assert.InDelta(t, 0.5, report.Class.MissingRate, 1e-9)
+	assert.Equal(t, []*UnseenValue{{Value: "a.", Count: 1, Invalid: true}}, report.Class.UnseenValues)
+	assert.Contains(t, report.Warnings, "attribute 'Class': invalid value 'a.' considered as missing in 1 instances")
+}
+
+func TestInstanceWarnings(t *testing.T) {
+	tr := newTestTree(t)
+	instances := newValueTable(t, tr, []map[string]string{
+		{"x": "5", "color": "red"},
+		{"x": "?", "color": "?"},
+		{"x": "50", "color": "blue"},
+		{"x": "abc", "color": "purple"},
+	}).Instances
+
+	assert.Empty(t, InstanceWarnings(tr, instances[0]))
+	assert.Empty(t, InstanceWarnings(tr, instances[1]))
+	assert.Equal(t, []string{
+		"attribute 'x': value 50 outside the training range [1, 20]",
+		"attribute 'color': value 'blue' never seen in training",
+	}, InstanceWarnings(tr, instances[2]))
+	assert.Equal(t, []string{
+		"attribute 'x': invalid value 'abc' considered as missing",
+		"attribute 'color': invalid value 'purple' considered as missing",
+	}, InstanceWarnings(tr, instances[3]))
+
+	tr.TrainingStats = nil
+	assert.Nil(t, InstanceWarnings(tr, instances[2]))
+}
+
+func TestWriteReport(t *testing.T) {
+	tr := newTestTree(t)
+	report, err := Check(tr, newValueTable(t, tr, []map[string]string{{"x": "15", "color": "blue", "Class": "b"}}), nil)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteJson(&buf, report))
+	var decoded Report
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, report, &decoded)
+
+	buf.Reset()
+	assert.NoError(t, WriteText(&buf, report))
+	assert.Contains(t, buf.String(), "Instances: 1 (training: 20)\n")
+	assert.Contains(t, buf.String(), "Leaf occupancy PSI:")
+	assert.Contains(t, buf.String(), "  attribute 'color': value 'blue' never seen in training, in 1 instances\n")
+}
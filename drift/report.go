@@ -0,0 +1,53 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteJson writes the report as indented JSON.
+func WriteJson(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false) // class values like "<=50K"
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to write drift report: %w", err)
+	}
+	return nil
+}
+
+// WriteText writes the report as human-readable text: a table of the attributes and the class, the PSI of the leaf
+// occupancy, and the warnings.
+func WriteText(w io.Writer, report *Report) error {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Instances: %d (training: %d)\n\n", report.InstanceCount, report.TrainingInstanceCount))
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Attribute\tType\tPSI\tMissing (training)\tMissing\tUnseen values\tOut of range\tDrifted")
+	attributes := report.Attributes
+	if report.Class != nil {
+		attributes = append(append([]*AttributeDrift(nil), attributes...), report.Class)
+	}
+	for _, attr := range attributes {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%.4f\t%.2f%%\t%.2f%%\t%d\t%d\t%v\n", attr.Name, attr.Type, attr.PSI,
+			attr.TrainingMissingRate*100, attr.MissingRate*100, len(attr.UnseenValues), attr.OutOfRange, attr.Drifted)
+	}
+	_ = tw.Flush()
+
+	sb.WriteString(fmt.Sprintf("\nLeaf occupancy PSI: %.4f over %d leaves\n", report.Leaves.PSI, len(report.Leaves.Leaves)))
+
+	if len(report.Warnings) > 0 {
+		sb.WriteString("\nWarnings:\n")
+		for _, warning := range report.Warnings {
+			sb.WriteString("  " + warning + "\n")
+		}
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write drift report: %w", err)
+	}
+	return nil
+}
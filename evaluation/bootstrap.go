@@ -0,0 +1,322 @@
+package evaluation
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// MetricInterval is the bootstrap confidence interval of a metric, the class is empty for overall metrics.
+type MetricInterval struct {
+	Metric   string  `json:"metric"`
+	Class    string  `json:"class,omitempty"`
+	Estimate float64 `json:"estimate"` // the metric on the data set itself
+	Lower    float64 `json:"lower"`
+	Upper    float64 `json:"upper"`
+}
+
+// MetricDifference is the difference of a metric between two models A and B predicting the same data set, with its
+// paired bootstrap confidence interval and p-value.
+type MetricDifference struct {
+	Metric     string  `json:"metric"`
+	Class      string  `json:"class,omitempty"`
+	A          float64 `json:"a"`
+	B          float64 `json:"b"`
+	Difference float64 `json:"difference"` // A - B
+	Lower      float64 `json:"lower"`
+	Upper      float64 `json:"upper"`
+	PValue     float64 `json:"p_value"` // two-sided, of the hypothesis that the difference is 0
+}
+
+// McNemarResult is McNemar's test of whether two models make errors on the same data set at the same rate, only the
+// instances on which exactly one of the models is correct count.
+type McNemarResult struct {
+	OnlyACorrect int     `json:"only_a_correct"`
+	OnlyBCorrect int     `json:"only_b_correct"`
+	Statistic    float64 `json:"statistic"` // chi-squared with continuity correction
+	PValue       float64 `json:"p_value"`
+	Exact        bool    `json:"exact"` // the p-value is of the exact binomial test, used if few instances disagree
+}
+
+// Comparison is the paired comparison of two models A and B on the same data set.
+type Comparison struct {
+	McNemar *McNemarResult      `json:"mcnemar"`
+	Metrics []*MetricDifference `json:"metrics"`
+}
+
+type BootstrapOptions struct {
+	Iterations int     // number of resamples, 1000 if 0
+	Confidence float64 // confidence level of the intervals, 0.95 if 0
+	Seed       int64
+}
+
+// mcNemarExactLimit is the number of disagreeing instances below which the exact binomial test is used.
+const mcNemarExactLimit = 25
+
+func (o *BootstrapOptions) withDefaults() *BootstrapOptions {
+	res := &BootstrapOptions{Iterations: 1000, Confidence: 0.95}
+	if o != nil {
+		res.Seed = o.Seed
+		if o.Iterations > 0 {
+			res.Iterations = o.Iterations
+		}
+		if o.Confidence > 0 {
+			res.Confidence = o.Confidence
+		}
+	}
+	return res
+}
+
+// Predict predicts every instance of the data set, and returns the actual and predicted classes.
+func Predict(tr *tree.Tree, dataTable *data.ValueTable) (actual, predicted []string, err error) {
+	actual = make([]string, len(dataTable.Instances))
+	predicted = make([]string, len(dataTable.Instances))
+	for i, instance := range dataTable.Instances {
+		actual[i] = instance.ClassValue.Value().(string)
+		predicted[i], err = tr.Predict(instance)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to predict instance %d: %w", i, err)
+		}
+	}
+	return actual, predicted, nil
+}
+
+// Bootstrap estimates the confidence intervals of the test run metrics of the tree on the data set.
+func Bootstrap(tr *tree.Tree, dataTable *data.ValueTable, opts *BootstrapOptions) ([]*MetricInterval, error) {
+	actual, predicted, err := Predict(tr, dataTable)
+	if err != nil {
+		return nil, err
+	}
+	return BootstrapPredictions(actual, predicted, len(tr.GetLeafNodes()), opts), nil
+}
+
+// BootstrapPredictions estimates the percentile bootstrap confidence intervals of the test run metrics of the
+// predictions, by resampling the instances with replacement.
+func BootstrapPredictions(actual, predicted []string, leafCount int, opts *BootstrapOptions) []*MetricInterval {
+	opts = opts.withDefaults()
+	var (
+		classes   = predictionClasses(actual, predicted)
+		estimates = testRunMetrics(tree.NewTestResults(actual, predicted, leafCount), classes)
+		samples   = make([][]float64, len(estimates))
+	)
+	resample(len(actual), opts, func(indexes []int) {
+		metrics := testRunMetrics(tree.NewTestResults(pick(actual, indexes), pick(predicted, indexes), leafCount), classes)
+		for i, m := range metrics {
+			samples[i] = append(samples[i], m.value)
+		}
+	})
+
+	var res []*MetricInterval
+	for i, m := range estimates {
+		lower, upper := percentileInterval(samples[i], opts.Confidence)
+		res = append(res, &MetricInterval{Metric: m.name, Class: m.class, Estimate: m.value, Lower: lower, Upper: upper})
+	}
+	return res
+}
+
+// Compare compares the trees on the same data set with McNemar's test and the paired bootstrap.
+func Compare(a, b *tree.Tree, dataTable *data.ValueTable, opts *BootstrapOptions) (*Comparison, error) {
+	actual, predictedA, err := Predict(a, dataTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to predict with model A: %w", err)
+	}
+	_, predictedB, err := Predict(b, dataTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to predict with model B: %w", err)
+	}
+	return ComparePredictions(actual, predictedA, predictedB, len(a.GetLeafNodes()), len(b.GetLeafNodes()), opts), nil
+}
+
+// ComparePredictions compares the predictions of two models on the same instances with McNemar's test and the
+// paired bootstrap, in which both models are evaluated on the same resamples.
+func ComparePredictions(actual, predictedA, predictedB []string, leafCountA, leafCountB int, opts *BootstrapOptions) *Comparison {
+	opts = opts.withDefaults()
+	var (
+		classes  = predictionClasses(actual, append(append([]string(nil), predictedA...), predictedB...))
+		metricsA = testRunMetrics(tree.NewTestResults(actual, predictedA, leafCountA), classes)
+		metricsB = testRunMetrics(tree.NewTestResults(actual, predictedB, leafCountB), classes)
+		samples  = make([][]float64, len(metricsA))
+	)
+	resample(len(actual), opts, func(indexes []int) {
+		sampleActual := pick(actual, indexes)
+		sampleA := testRunMetrics(tree.NewTestResults(sampleActual, pick(predictedA, indexes), leafCountA), classes)
+		sampleB := testRunMetrics(tree.NewTestResults(sampleActual, pick(predictedB, indexes), leafCountB), classes)
+		for i := range sampleA {
+			samples[i] = append(samples[i], sampleA[i].value-sampleB[i].value)
+		}
+	})
+
+	res := &Comparison{McNemar: McNemarTest(actual, predictedA, predictedB)}
+	for i, m := range metricsA {
+		lower, upper := percentileInterval(samples[i], opts.Confidence)
+		var nonPositive, nonNegative int
+		for _, d := range samples[i] {
+			if d <= 0 {
+				nonPositive++
+			}
+			if d >= 0 {
+				nonNegative++
+			}
+		}
+		// the original sample counts as a resample, so the p-value is never 0 with finite resamples
+		pValue := math.Min(1, 2*float64(min(nonPositive, nonNegative)+1)/float64(len(samples[i])+1))
+		res.Metrics = append(res.Metrics, &MetricDifference{
+			Metric:     m.name,
+			Class:      m.class,
+			A:          m.value,
+			B:          metricsB[i].value,
+			Difference: m.value - metricsB[i].value,
+			Lower:      lower,
+			Upper:      upper,
+			PValue:     pValue,
+		})
+	}
+	return res
+}
+
+// Better returns "a" or "b" if McNemar's test finds the model more accurate at the significance level, or "" if
+// the difference could be noise.
+func (c *Comparison) Better(alpha float64) string {
+	switch {
+	case c.McNemar.PValue >= alpha:
+		return ""
+	case c.McNemar.OnlyACorrect > c.McNemar.OnlyBCorrect:
+		return "a"
+	case c.McNemar.OnlyBCorrect > c.McNemar.OnlyACorrect:
+		return "b"
+	default:
+		return ""
+	}
+}
+
+// McNemarTest tests whether the models make errors at the same rate. The chi-squared statistic with continuity
+// correction is used, and the exact binomial test if fewer than 25 instances are correctly predicted by only one
+// of the models.
+func McNemarTest(actual, predictedA, predictedB []string) *McNemarResult {
+	res := &McNemarResult{}
+	for i, class := range actual {
+		correctA, correctB := predictedA[i] == class, predictedB[i] == class
+		switch {
+		case correctA && !correctB:
+			res.OnlyACorrect++
+		case correctB && !correctA:
+			res.OnlyBCorrect++
+		}
+	}
+	n := res.OnlyACorrect + res.OnlyBCorrect
+	if n == 0 {
+		res.PValue = 1
+		return res
+	}
+	diff := math.Max(math.Abs(float64(res.OnlyACorrect-res.OnlyBCorrect))-1, 0)
+	res.Statistic = diff * diff / float64(n)
+	if n < mcNemarExactLimit {
+		res.Exact = true
+		res.PValue = binomialTwoSidedPValue(min(res.OnlyACorrect, res.OnlyBCorrect), n)
+	} else {
+		// chi-squared distribution with 1 degree of freedom
+		res.PValue = math.Erfc(math.Sqrt(res.Statistic / 2))
+	}
+	return res
+}
+
+// binomialTwoSidedPValue is the probability of at most k successes in n trials with probability 0.5, doubled.
+func binomialTwoSidedPValue(k, n int) float64 {
+	var p float64
+	for i := 0; i <= k; i++ {
+		lgN, _ := math.Lgamma(float64(n + 1))
+		lgI, _ := math.Lgamma(float64(i + 1))
+		lgNI, _ := math.Lgamma(float64(n - i + 1))
+		p += math.Exp(lgN - lgI - lgNI - float64(n)*math.Ln2)
+	}
+	return math.Min(1, 2*p)
+}
+
+// metricValue is a metric of the test results, the class is empty for overall metrics.
+type metricValue struct {
+	name  string
+	class string
+	value float64
+}
+
+// testRunMetrics lists the metrics of the test results in a fixed order, per-class metrics are listed for every
+// given class.
+func testRunMetrics(res *tree.TestResults, classes []string) []*metricValue {
+	metrics := []*metricValue{
+		{name: "accuracy", value: res.Accuracy},
+		{name: "balanced_accuracy", value: res.BalancedAccuracy},
+		{name: "macro_f1", value: res.MacroF1},
+		{name: "micro_f1", value: res.MicroF1},
+		{name: "weighted_f1", value: res.WeightedF1},
+		{name: "mcc", value: res.MCC},
+		{name: "kappa", value: res.Kappa},
+		{name: "pessimistic_error", value: res.PessimisticError},
+	}
+	for _, class := range classes {
+		metrics = append(metrics,
+			&metricValue{name: "precision", class: class, value: res.ClassPrecision[class]},
+			&metricValue{name: "recall", class: class, value: res.ClassRecall[class]},
+			&metricValue{name: "f1", class: class, value: res.ClassF1[class]},
+		)
+	}
+	return metrics
+}
+
+func predictionClasses(actual, predicted []string) []string {
+	classes := make(map[string]bool)
+	for _, class := range actual {
+		classes[class] = true
+	}
+	for _, class := range predicted {
+		classes[class] = true
+	}
+	return sortedKeys(classes)
+}
+
+// resample calls f with the indexes of every resample, drawn with replacement.
+func resample(n int, opts *BootstrapOptions, f func(indexes []int)) {
+	if n == 0 {
+		return
+	}
+	var (
+		rnd     = rand.New(rand.NewSource(opts.Seed))
+		indexes = make([]int, n)
+	)
+	for iteration := 0; iteration < opts.Iterations; iteration++ {
+		for i := range indexes {
+			indexes[i] = rnd.Intn(n)
+		}
+		f(indexes)
+	}
+}
+
+func pick(values []string, indexes []int) []string {
+	res := make([]string, len(indexes))
+	for i, index := range indexes {
+		res[i] = values[index]
+	}
+	return res
+}
+
+// percentileInterval returns the central interval of the values at the confidence level, interpolating between
+// the sorted values.
+func percentileInterval(values []float64, confidence float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return quantile(sorted, (1-confidence)/2), quantile(sorted, 1-(1-confidence)/2)
+}
+
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(math.Floor(pos))
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
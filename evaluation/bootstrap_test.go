@@ -0,0 +1,112 @@
+package evaluation
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newPairedPredictions returns 100 instances, on which model A is correct on the first 20 and B only on the next 5,
+// and both are correct on the rest.
+func newPairedPredictions() (actual, predictedA, predictedB []string) {
+	for i := 0; i < 100; i++ {
+		class := []string{"x", "y"}[i%2]
+		wrong := []string{"y", "x"}[i%2]
+		actual = append(actual, class)
+		switch {
+		case i < 20:
+			predictedA, predictedB = append(predictedA, class), append(predictedB, wrong)
+		case i < 25:
+			predictedA, predictedB = append(predictedA, wrong), append(predictedB, class)
+		default:
+			predictedA, predictedB = append(predictedA, class), append(predictedB, class)
+		}
+	}
+	return actual, predictedA, predictedB
+}
+
+func TestMcNemarTest(t *testing.T) {
+	actual, predictedA, predictedB := newPairedPredictions()
+	res := McNemarTest(actual, predictedA, predictedB)
+	assert.Equal(t, 20, res.OnlyACorrect)
+	assert.Equal(t, 5, res.OnlyBCorrect)
+	assert.InDelta(t, 7.84, res.Statistic, 1e-9)
+	assert.InDelta(t, 0.00511026, res.PValue, 1e-6)
+	assert.False(t, res.Exact)
+
+	// 8 vs 1 disagreements: 2 * (1 + 9) / 2^9
+	res = McNemarTest(
+		[]string{"x", "x", "x", "x", "x", "x", "x", "x", "x", "x"},
+		[]string{"x", "x", "x", "x", "x", "x", "x", "x", "y", "x"},
+		[]string{"y", "y", "y", "y", "y", "y", "y", "y", "x", "x"})
+	assert.True(t, res.Exact)
+	assert.InDelta(t, 0.0390625, res.PValue, 1e-9)
+
+	res = McNemarTest(actual, actual, actual)
+	assert.Equal(t, 1.0, res.PValue)
+}
+
+func TestBootstrapPredictions(t *testing.T) {
+	actual, predicted, _ := newPairedPredictions()
+	intervals := BootstrapPredictions(actual, predicted, 4, &BootstrapOptions{Iterations: 200, Seed: 1})
+	assert.Len(t, intervals, 8+3*2)
+	accuracy := intervals[0]
+	assert.Equal(t, "accuracy", accuracy.Metric)
+	assert.InDelta(t, 0.95, accuracy.Estimate, 1e-9)
+	assert.True(t, accuracy.Lower < accuracy.Estimate && accuracy.Estimate < accuracy.Upper, "%+v", accuracy)
+	assert.True(t, accuracy.Lower > 0.85 && accuracy.Upper <= 1, "%+v", accuracy)
+	assert.Equal(t, "precision", intervals[8].Metric)
+	assert.Equal(t, "x", intervals[8].Class)
+
+	// the same seed gives the same intervals
+	assert.Equal(t, intervals, BootstrapPredictions(actual, predicted, 4, &BootstrapOptions{Iterations: 200, Seed: 1}))
+
+	// perfect predictions have no uncertainty
+	intervals = BootstrapPredictions(actual, actual, 4, nil)
+	assert.Equal(t, 1.0, intervals[0].Lower)
+	assert.Equal(t, 1.0, intervals[0].Upper)
+}
+
+func TestComparePredictions(t *testing.T) {
+	actual, predictedA, predictedB := newPairedPredictions()
+	comparison := ComparePredictions(actual, predictedA, predictedB, 4, 4, &BootstrapOptions{Iterations: 500, Seed: 1})
+	assert.Equal(t, "a", comparison.Better(0.05))
+	assert.Equal(t, "b", ComparePredictions(actual, predictedB, predictedA, 4, 4, nil).Better(0.05))
+
+	accuracy := comparison.Metrics[0]
+	assert.InDelta(t, 0.95, accuracy.A, 1e-9)
+	assert.InDelta(t, 0.8, accuracy.B, 1e-9)
+	assert.InDelta(t, 0.15, accuracy.Difference, 1e-9)
+	assert.True(t, accuracy.Lower > 0, "%+v", accuracy)
+	assert.True(t, accuracy.PValue < 0.05, "%+v", accuracy)
+
+	// identical models never differ
+	comparison = ComparePredictions(actual, predictedA, predictedA, 4, 4, nil)
+	assert.Equal(t, "", comparison.Better(0.05))
+	assert.Equal(t, 0.0, comparison.Metrics[0].Upper)
+	assert.Equal(t, 1.0, comparison.Metrics[0].PValue)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteComparisonCsv(&buf, ComparePredictions(actual, predictedA, predictedB, 4, 4, nil)))
+	assert.Contains(t, buf.String(), "metric,class,a,b,difference,lower,upper,p_value\nmcnemar,,20,5,15,,,0.0051")
+}
+
+func TestCompare(t *testing.T) {
+	tr, dataTable := newTestData(t)
+	comparison, err := Compare(tr, tr.Copy(), dataTable, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, comparison.McNemar.OnlyACorrect+comparison.McNemar.OnlyBCorrect)
+
+	intervals, err := Bootstrap(tr, dataTable, &BootstrapOptions{Iterations: 100})
+	assert.NoError(t, err)
+	assert.InDelta(t, 5.0/9, intervals[0].Estimate, 1e-9)
+
+	report, err := Evaluate(tr, dataTable)
+	assert.NoError(t, err)
+	report.Intervals = intervals
+	var buf bytes.Buffer
+	assert.NoError(t, WriteReportCsv(&buf, report))
+	assert.Contains(t, buf.String(), "\naccuracy_lower,,")
+	assert.Contains(t, buf.String(), "\nf1_upper,d,")
+}
@@ -0,0 +1,317 @@
+package evaluation
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Report is the evaluation of a tree on a data set.
+type Report struct {
+	TotalDataCount   int                       `json:"total_data_count"`
+	Accuracy         float64                   `json:"accuracy"`
+	BalancedAccuracy float64                   `json:"balanced_accuracy"`
+	MacroF1          float64                   `json:"macro_f1"`
+	MicroF1          float64                   `json:"micro_f1"`
+	WeightedF1       float64                   `json:"weighted_f1"`
+	MCC              float64                   `json:"mcc"`
+	Kappa            float64                   `json:"kappa"`
+	PessimisticError float64                   `json:"pessimistic_error"`
+	LogLoss          float64                   `json:"log_loss,omitempty"`
+	BrierScore       float64                   `json:"brier_score,omitempty"`   // mean squared error of the class probabilities
+	MacroROCAUC      float64                   `json:"macro_roc_auc,omitempty"` // mean of the ROC AUC of the classes having one
+	Classes          []*ClassReport            `json:"classes"`
+	ConfusionMatrix  map[string]map[string]int `json:"confusion_matrix"`    // actual class -> predicted class -> count
+	Intervals        []*MetricInterval         `json:"intervals,omitempty"` // bootstrap confidence intervals, see Bootstrap
+	Targets          map[string]*Report        `json:"targets,omitempty"`   // additional targets of multi-output trees, by name
+}
+
+// ClassReport is the evaluation of a class. The curves and their areas treat the class as positive and the other
+// classes as negative, they are only available if the data set has both positive and negative instances.
+type ClassReport struct {
+	Class            string      `json:"class"`
+	DataCount        int         `json:"data_count"`
+	PredictCount     int         `json:"predict_count"`
+	Precision        float64     `json:"precision"`
+	Recall           float64     `json:"recall"`
+	F1               float64     `json:"f1"`
+	ROCAUC           float64     `json:"roc_auc,omitempty"`
+	AveragePrecision float64     `json:"average_precision,omitempty"`
+	ROC              []*ROCPoint `json:"roc,omitempty"`
+	PR               []*PRPoint  `json:"pr,omitempty"`
+
+	// Reliability is the reliability diagram of the class, CalibrationError is the expected calibration error,
+	// the mean of the gap between the mean probability and the frequency of the bins weighted by their data count.
+	Reliability      []*ReliabilityBin `json:"reliability,omitempty"`
+	CalibrationError float64           `json:"calibration_error,omitempty"`
+}
+
+// ROCPoint is a point of the ROC curve, instances with probability >= Threshold are predicted as positive.
+type ROCPoint struct {
+	Threshold         float64 `json:"threshold"`
+	FalsePositiveRate float64 `json:"fpr"`
+	TruePositiveRate  float64 `json:"tpr"`
+}
+
+// PRPoint is a point of the precision-recall curve, instances with probability >= Threshold are predicted as
+// positive.
+type PRPoint struct {
+	Threshold float64 `json:"threshold"`
+	Recall    float64 `json:"recall"`
+	Precision float64 `json:"precision"`
+}
+
+// ReliabilityBin is a bin of the reliability diagram, holding the instances with probability in [Lower, Upper).
+// The last bin includes probability 1.
+type ReliabilityBin struct {
+	Lower           float64 `json:"lower"`
+	Upper           float64 `json:"upper"`
+	DataCount       int     `json:"data_count"`
+	MeanProbability float64 `json:"mean_probability"`
+	Frequency       float64 `json:"frequency"` // fraction of the instances being positive
+}
+
+// ReliabilityBinCount is the number of equal-width bins of the reliability diagrams in reports.
+const ReliabilityBinCount = 10
+
+// logLossEpsilon clips probabilities so that a wrong prediction with probability 0 has a finite loss.
+const logLossEpsilon = 1e-15
+
+// NewReport creates a report of the test results, without the metrics requiring class probabilities.
+func NewReport(res *tree.TestResults) *Report {
+	report := &Report{
+		TotalDataCount:   res.TotalDataCount,
+		Accuracy:         res.Accuracy,
+		BalancedAccuracy: res.BalancedAccuracy,
+		MacroF1:          res.MacroF1,
+		MicroF1:          res.MicroF1,
+		WeightedF1:       res.WeightedF1,
+		MCC:              res.MCC,
+		Kappa:            res.Kappa,
+		PessimisticError: res.PessimisticError,
+		ConfusionMatrix:  res.ConfusionMatrix,
+	}
+	classes := make(map[string]bool)
+	for class := range res.ClassDataCount {
+		classes[class] = true
+	}
+	for class := range res.ClassPredictCount {
+		classes[class] = true
+	}
+	for _, class := range sortedKeys(classes) {
+		report.Classes = append(report.Classes, &ClassReport{
+			Class:        class,
+			DataCount:    res.ClassDataCount[class],
+			PredictCount: res.ClassPredictCount[class],
+			Precision:    res.ClassPrecision[class],
+			Recall:       res.ClassRecall[class],
+			F1:           res.ClassF1[class],
+		})
+	}
+	for name, targetRes := range res.Targets {
+		if report.Targets == nil {
+			report.Targets = make(map[string]*Report)
+		}
+		report.Targets[name] = NewReport(targetRes)
+	}
+	return report
+}
+
+// Evaluate tests the tree on the data set, and evaluates the class probabilities predicted by the tree with
+// log-loss, Brier score, and the ROC curve, precision-recall curve and reliability diagram of every class.
+func Evaluate(tr *tree.Tree, dataTable *data.ValueTable) (*Report, error) {
+	res, probabilities, err := tree.TestRunProba(tr, dataTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to do test run: %w", err)
+	}
+	report := NewReport(res)
+
+	var (
+		actual     = make([]string, len(dataTable.Instances))
+		logLoss    float64
+		brierScore float64
+	)
+	for i, instance := range dataTable.Instances {
+		actual[i] = instance.ClassValue.Value().(string)
+		logLoss -= math.Log(math.Max(probabilities[i][actual[i]], logLossEpsilon))
+		for class, p := range probabilities[i] {
+			if class == actual[i] {
+				p--
+			}
+			brierScore += p * p
+		}
+		if _, ok := probabilities[i][actual[i]]; !ok {
+			brierScore++
+		}
+	}
+	if len(actual) > 0 {
+		report.LogLoss = logLoss / float64(len(actual))
+		report.BrierScore = brierScore / float64(len(actual))
+	}
+
+	var (
+		rocAUCSum   float64
+		rocAUCCount int
+	)
+	for _, classReport := range report.Classes {
+		var (
+			scores    = make([]float64, len(actual))
+			positives = make([]bool, len(actual))
+		)
+		for i := range actual {
+			scores[i] = probabilities[i][classReport.Class]
+			positives[i] = actual[i] == classReport.Class
+		}
+		classReport.ROC, classReport.ROCAUC = ROCCurve(scores, positives)
+		classReport.PR, classReport.AveragePrecision = PRCurve(scores, positives)
+		classReport.Reliability, classReport.CalibrationError = ReliabilityDiagram(scores, positives, ReliabilityBinCount)
+		if classReport.ROC != nil {
+			rocAUCSum += classReport.ROCAUC
+			rocAUCCount++
+		}
+	}
+	if rocAUCCount > 0 {
+		report.MacroROCAUC = rocAUCSum / float64(rocAUCCount)
+	}
+	return report, nil
+}
+
+// scoreThreshold is the number of positive and negative instances having a score no less than the threshold.
+type scoreThreshold struct {
+	threshold float64
+	positives int
+	negatives int
+}
+
+// cumulateScores groups the instances by score in descending order, nil is returned if there is no positive or
+// no negative instance.
+func cumulateScores(scores []float64, positives []bool) (res []*scoreThreshold, totalPositives, totalNegatives int) {
+	indexes := make([]int, len(scores))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return scores[indexes[i]] > scores[indexes[j]]
+	})
+	for _, i := range indexes {
+		if len(res) == 0 || res[len(res)-1].threshold != scores[i] {
+			st := &scoreThreshold{threshold: scores[i]}
+			if len(res) > 0 {
+				st.positives, st.negatives = res[len(res)-1].positives, res[len(res)-1].negatives
+			}
+			res = append(res, st)
+		}
+		if positives[i] {
+			res[len(res)-1].positives++
+			totalPositives++
+		} else {
+			res[len(res)-1].negatives++
+			totalNegatives++
+		}
+	}
+	if totalPositives == 0 || totalNegatives == 0 {
+		return nil, totalPositives, totalNegatives
+	}
+	return res, totalPositives, totalNegatives
+}
+
+// ROCCurve returns the ROC curve of the scores and the area under it. The first point of the curve has threshold
+// max score + 1, which predicts no instance as positive.
+// nil is returned if there is no positive or no negative instance.
+func ROCCurve(scores []float64, positives []bool) ([]*ROCPoint, float64) {
+	thresholds, totalPositives, totalNegatives := cumulateScores(scores, positives)
+	if thresholds == nil {
+		return nil, 0
+	}
+	var (
+		curve = []*ROCPoint{{Threshold: thresholds[0].threshold + 1}}
+		auc   float64
+	)
+	for _, st := range thresholds {
+		last := curve[len(curve)-1]
+		point := &ROCPoint{
+			Threshold:         st.threshold,
+			FalsePositiveRate: float64(st.negatives) / float64(totalNegatives),
+			TruePositiveRate:  float64(st.positives) / float64(totalPositives),
+		}
+		// trapezoid, instances with the same score are ranked as ties
+		auc += (point.FalsePositiveRate - last.FalsePositiveRate) * (point.TruePositiveRate + last.TruePositiveRate) / 2
+		curve = append(curve, point)
+	}
+	return curve, auc
+}
+
+// PRCurve returns the precision-recall curve of the scores and the average precision, which is the sum of the
+// precision at each threshold weighted by the increase of recall.
+// nil is returned if there is no positive or no negative instance.
+func PRCurve(scores []float64, positives []bool) ([]*PRPoint, float64) {
+	thresholds, totalPositives, _ := cumulateScores(scores, positives)
+	if thresholds == nil {
+		return nil, 0
+	}
+	var (
+		curve            []*PRPoint
+		averagePrecision float64
+		lastRecall       float64
+	)
+	for _, st := range thresholds {
+		point := &PRPoint{
+			Threshold: st.threshold,
+			Recall:    float64(st.positives) / float64(totalPositives),
+			Precision: float64(st.positives) / float64(st.positives+st.negatives),
+		}
+		averagePrecision += (point.Recall - lastRecall) * point.Precision
+		lastRecall = point.Recall
+		curve = append(curve, point)
+	}
+	return curve, averagePrecision
+}
+
+// ReliabilityDiagram groups the scores into equal-width bins over [0, 1], and returns the non-empty bins with the
+// expected calibration error.
+func ReliabilityDiagram(scores []float64, positives []bool, binCount int) ([]*ReliabilityBin, float64) {
+	var (
+		bins = make([]*ReliabilityBin, binCount)
+		sums = make([]float64, binCount)
+		hits = make([]int, binCount)
+		res  []*ReliabilityBin
+		ece  float64
+	)
+	for i, score := range scores {
+		b := int(score * float64(binCount))
+		if b >= binCount {
+			b = binCount - 1
+		} else if b < 0 {
+			b = 0
+		}
+		if bins[b] == nil {
+			bins[b] = &ReliabilityBin{Lower: float64(b) / float64(binCount), Upper: float64(b+1) / float64(binCount)}
+		}
+		bins[b].DataCount++
+		sums[b] += score
+		if positives[i] {
+			hits[b]++
+		}
+	}
+	for b, bin := range bins {
+		if bin == nil {
+			continue
+		}
+		bin.MeanProbability = sums[b] / float64(bin.DataCount)
+		bin.Frequency = float64(hits[b]) / float64(bin.DataCount)
+		ece += math.Abs(bin.MeanProbability-bin.Frequency) * float64(bin.DataCount) / float64(len(scores))
+		res = append(res, bin)
+	}
+	return res, ece
+}
+
+func sortedKeys(m map[string]bool) []string {
+	var res []string
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
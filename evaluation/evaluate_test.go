@@ -0,0 +1,184 @@
+package evaluation
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestData returns the tree: x < 10 (prioritized) -> a, x >= 10 -> (x < 20 (prioritized) -> b, x >= 20 -> c),
+// and a data set on which class d is never predicted.
+func newTestData(t *testing.T) (*tree.Tree, *data.ValueTable) {
+	var (
+		conf      = &config.Config{ConsiderInvalidDataAsMissing: true}
+		x         = data.NewContinuousAttribute("x")
+		classAttr = data.NewNominalAttribute("Class", []string{"a", "b", "c", "d"})
+	)
+	tr := &tree.Tree{
+		Attributes: []data.Attribute{x},
+		Class:      classAttr,
+		RootNode: &tree.Node{Children: []*tree.Node{
+			{Condition: tree.NewLessThanCondition(x, 10), IsPrioritized: true, LeafClass: "a", ClassCount: map[string]float64{"a": 3, "b": 1}},
+			{Condition: tree.NewGreaterThanEqCondition(x, 10), Children: []*tree.Node{
+				{Condition: tree.NewLessThanCondition(x, 20), IsPrioritized: true, LeafClass: "b", ClassCount: map[string]float64{"b": 3, "c": 1}},
+				{Condition: tree.NewGreaterThanEqCondition(x, 20), LeafClass: "c", ClassCount: map[string]float64{"c": 2}},
+			}},
+		}},
+	}
+
+	// predicted: a a a b b b c c c
+	rows := [][2]string{{"1", "a"}, {"2", "a"}, {"3", "b"}, {"11", "b"}, {"12", "c"}, {"13", "b"}, {"25", "c"}, {"26", "a"}, {"27", "d"}}
+	dataTable := &data.ValueTable{}
+	for _, row := range rows {
+		instance, err := data.ParseInstance(conf, tr.Attributes, classAttr, map[string]string{"x": row[0], "Class": row[1]})
+		if err != nil {
+			t.Fatalf("failed to parse instance: %v", err)
+		}
+		dataTable.Instances = append(dataTable.Instances, instance)
+	}
+	return tr, dataTable
+}
+
+func TestTestRunMetrics(t *testing.T) {
+	tr, dataTable := newTestData(t)
+	res, err := tree.TestRun(tr, dataTable)
+	assert.NoError(t, err)
+
+	assert.InDelta(t, 5.0/9, res.Accuracy, 1e-9)
+	assert.Equal(t, 0.0, res.ClassPrecision["d"], "class d is never predicted")
+	assert.InDelta(t, 1.0/3, res.ClassPrecision["c"], 1e-9)
+	assert.InDelta(t, 0.4, res.ClassF1["c"], 1e-9)
+	assert.InDelta(t, 0.43333333, res.MacroF1, 1e-6)
+	assert.InDelta(t, 5.0/9, res.MicroF1, 1e-9)
+	assert.InDelta(t, 0.53333333, res.WeightedF1, 1e-6)
+	assert.InDelta(t, 0.45833333, res.BalancedAccuracy, 1e-6)
+	assert.InDelta(t, 0.37523939, res.MCC, 1e-6)
+	assert.InDelta(t, 0.36842105, res.Kappa, 1e-6)
+}
+
+func TestTestRunProba(t *testing.T) {
+	tr, dataTable := newTestData(t)
+	tr.Threshold = &tree.DecisionThreshold{PositiveClass: "b", Threshold: 0.2}
+	expected, err := tree.TestRun(tr, dataTable)
+	assert.NoError(t, err)
+
+	// the classes and the probabilities are those of predicting every instance on its own
+	res, probabilities, err := tree.TestRunProba(tr, dataTable)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.ConfusionMatrix, res.ConfusionMatrix)
+	assert.Equal(t, expected.Accuracy, res.Accuracy)
+	assert.Len(t, probabilities, len(dataTable.Instances))
+	for i, instance := range dataTable.Instances {
+		p, err := tr.PredictProba(instance)
+		assert.NoError(t, err)
+		assert.Equal(t, p, probabilities[i])
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tr, dataTable := newTestData(t)
+	report, err := Evaluate(tr, dataTable)
+	assert.NoError(t, err)
+
+	// the instance of class d has probability 0, clipped to 1e-15
+	assert.InDelta(t, 8.11120776, report.LogLoss, 1e-6)
+	assert.InDelta(t, 0.75, report.BrierScore, 1e-9)
+	assert.InDelta(t, 0.69940476, report.MacroROCAUC, 1e-6)
+	assert.Len(t, report.Classes, 4)
+
+	a := report.Classes[0]
+	assert.Equal(t, "a", a.Class)
+	assert.Equal(t, 3, a.DataCount)
+	assert.InDelta(t, 0.75, a.ROCAUC, 1e-9)
+	assert.InDelta(t, 0.55555556, a.AveragePrecision, 1e-6)
+	assert.Equal(t, []*ROCPoint{
+		{Threshold: 1.75, FalsePositiveRate: 0, TruePositiveRate: 0},
+		{Threshold: 0.75, FalsePositiveRate: 1.0 / 6, TruePositiveRate: 2.0 / 3},
+		{Threshold: 0, FalsePositiveRate: 1, TruePositiveRate: 1},
+	}, a.ROC)
+	assert.Equal(t, []*PRPoint{
+		{Threshold: 0.75, Recall: 2.0 / 3, Precision: 2.0 / 3},
+		{Threshold: 0, Recall: 1, Precision: 1.0 / 3},
+	}, a.PR)
+	assert.Len(t, a.Reliability, 2)
+	assert.InDelta(t, 5.0/36, a.CalibrationError, 1e-9)
+	assert.InDelta(t, 0.83333333, report.Classes[1].ROCAUC, 1e-6)
+	assert.InDelta(t, 0.71428571, report.Classes[2].ROCAUC, 1e-6)
+	assert.InDelta(t, 0.5, report.Classes[3].ROCAUC, 1e-9)
+}
+
+func TestCurvesWithoutNegatives(t *testing.T) {
+	roc, auc := ROCCurve([]float64{0.2, 0.8}, []bool{true, true})
+	assert.Nil(t, roc)
+	assert.Equal(t, 0.0, auc)
+	pr, ap := PRCurve([]float64{0.2, 0.8}, []bool{false, false})
+	assert.Nil(t, pr)
+	assert.Equal(t, 0.0, ap)
+}
+
+func TestReliabilityDiagram(t *testing.T) {
+	bins, ece := ReliabilityDiagram(
+		[]float64{0.75, 0.75, 0.75, 0, 0, 0, 0, 0, 1},
+		[]bool{true, true, false, false, false, false, true, false, true},
+		10)
+	assert.Len(t, bins, 3)
+	assert.InDelta(t, 0.0, bins[0].Lower, 1e-9)
+	assert.InDelta(t, 0.1, bins[0].Upper, 1e-9)
+	assert.Equal(t, 5, bins[0].DataCount)
+	assert.InDelta(t, 0.2, bins[0].Frequency, 1e-9)
+	assert.InDelta(t, 0.7, bins[1].Lower, 1e-9)
+	assert.InDelta(t, 0.75, bins[1].MeanProbability, 1e-9)
+	assert.InDelta(t, 2.0/3, bins[1].Frequency, 1e-9)
+	assert.InDelta(t, 0.9, bins[2].Lower, 1e-9, "probability 1 falls into the last bin")
+	assert.InDelta(t, 1.0, bins[2].Frequency, 1e-9)
+	assert.InDelta(t, 5.0/9*0.2+3.0/9/12, ece, 1e-9)
+}
+
+func TestWriteReport(t *testing.T) {
+	tr, dataTable := newTestData(t)
+	report, err := Evaluate(tr, dataTable)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteReportJson(&buf, report))
+	var decoded Report
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, report, &decoded)
+
+	buf.Reset()
+	assert.NoError(t, WriteReportCsv(&buf, report))
+	csv := buf.String()
+	assert.True(t, strings.HasPrefix(csv, "metric,class,value\n"))
+	assert.Contains(t, csv, "kappa,,0.368421052631579")
+	assert.Contains(t, csv, "precision,d,0\n")
+	assert.Contains(t, csv, "confusion[c],d,1\n")
+
+	buf.Reset()
+	assert.NoError(t, WriteCurvesCsv(&buf, report))
+	assert.Contains(t, buf.String(), "a,roc,0.75,0.16666666666666666,0.6666666666666666\n")
+	assert.Contains(t, buf.String(), "a,pr,0,1,0.3333333333333333\n")
+
+	buf.Reset()
+	assert.NoError(t, WriteReliabilityCsv(&buf, report))
+	assert.True(t, strings.HasPrefix(buf.String(), "class,lower,upper,data_count,mean_probability,frequency\n"))
+	assert.Contains(t, buf.String(), "a,0,0.1,6,0,0.16666666666666666\n")
+}
+
+func TestNewReport(t *testing.T) {
+	report := NewReport(&tree.TestResults{
+		TotalDataCount:    2,
+		Accuracy:          0.5,
+		ClassDataCount:    map[string]int{"x": 2},
+		ClassPredictCount: map[string]int{"x": 1, "y": 1},
+	})
+	assert.Len(t, report.Classes, 2)
+	assert.Equal(t, "y", report.Classes[1].Class)
+	assert.Equal(t, 0.0, report.LogLoss)
+	assert.Nil(t, report.Classes[0].ROC)
+}
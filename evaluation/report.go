@@ -0,0 +1,151 @@
+package evaluation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteReportJson writes the report as indented JSON, including the curves.
+func WriteReportJson(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false) // class values like "<=50K"
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// WriteComparisonJson writes the comparison as indented JSON.
+func WriteComparisonJson(w io.Writer, comparison *Comparison) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(comparison); err != nil {
+		return fmt.Errorf("failed to write comparison: %w", err)
+	}
+	return nil
+}
+
+// WriteReportCsv writes the metrics of the report as CSV rows of "metric,class,value", the class is empty for
+// overall metrics. Confusion matrix cells are written as metric "confusion[<predicted class>]", and the bounds of the
+// confidence intervals as metrics "<metric>_lower" and "<metric>_upper".
+func WriteReportCsv(w io.Writer, report *Report) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"metric", "class", "value"})
+	for _, m := range []struct {
+		name  string
+		value float64
+	}{
+		{"total_data_count", float64(report.TotalDataCount)},
+		{"accuracy", report.Accuracy},
+		{"balanced_accuracy", report.BalancedAccuracy},
+		{"macro_f1", report.MacroF1},
+		{"micro_f1", report.MicroF1},
+		{"weighted_f1", report.WeightedF1},
+		{"mcc", report.MCC},
+		{"kappa", report.Kappa},
+		{"pessimistic_error", report.PessimisticError},
+		{"log_loss", report.LogLoss},
+		{"brier_score", report.BrierScore},
+		{"macro_roc_auc", report.MacroROCAUC},
+	} {
+		_ = cw.Write([]string{m.name, "", formatFloat(m.value)})
+	}
+	for _, c := range report.Classes {
+		for _, m := range []struct {
+			name  string
+			value float64
+		}{
+			{"data_count", float64(c.DataCount)},
+			{"predict_count", float64(c.PredictCount)},
+			{"precision", c.Precision},
+			{"recall", c.Recall},
+			{"f1", c.F1},
+			{"roc_auc", c.ROCAUC},
+			{"average_precision", c.AveragePrecision},
+			{"calibration_error", c.CalibrationError},
+		} {
+			_ = cw.Write([]string{m.name, c.Class, formatFloat(m.value)})
+		}
+		for _, predicted := range report.Classes {
+			count := report.ConfusionMatrix[c.Class][predicted.Class]
+			_ = cw.Write([]string{"confusion[" + predicted.Class + "]", c.Class, strconv.Itoa(count)})
+		}
+	}
+	for _, interval := range report.Intervals {
+		_ = cw.Write([]string{interval.Metric + "_lower", interval.Class, formatFloat(interval.Lower)})
+		_ = cw.Write([]string{interval.Metric + "_upper", interval.Class, formatFloat(interval.Upper)})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// WriteCurvesCsv writes the ROC and precision-recall curves of every class as CSV rows of
+// "class,curve,threshold,x,y", x and y are the false positive rate and true positive rate for ROC curves, and the
+// recall and precision for PR curves.
+func WriteCurvesCsv(w io.Writer, report *Report) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"class", "curve", "threshold", "x", "y"})
+	for _, c := range report.Classes {
+		for _, p := range c.ROC {
+			_ = cw.Write([]string{c.Class, "roc", formatFloat(p.Threshold), formatFloat(p.FalsePositiveRate), formatFloat(p.TruePositiveRate)})
+		}
+		for _, p := range c.PR {
+			_ = cw.Write([]string{c.Class, "pr", formatFloat(p.Threshold), formatFloat(p.Recall), formatFloat(p.Precision)})
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write curves: %w", err)
+	}
+	return nil
+}
+
+// WriteReliabilityCsv writes the reliability diagram of every class as CSV rows of
+// "class,lower,upper,data_count,mean_probability,frequency".
+func WriteReliabilityCsv(w io.Writer, report *Report) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"class", "lower", "upper", "data_count", "mean_probability", "frequency"})
+	for _, c := range report.Classes {
+		for _, bin := range c.Reliability {
+			_ = cw.Write([]string{c.Class, formatFloat(bin.Lower), formatFloat(bin.Upper), strconv.Itoa(bin.DataCount),
+				formatFloat(bin.MeanProbability), formatFloat(bin.Frequency)})
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write reliability diagrams: %w", err)
+	}
+	return nil
+}
+
+// WriteComparisonCsv writes the comparison as CSV rows of "metric,class,a,b,difference,lower,upper,p_value", lower and
+// upper bound the confidence interval of the difference. McNemar's test is written as metric "mcnemar", in which a
+// and b are the numbers of instances correctly predicted by only one of the models.
+func WriteComparisonCsv(w io.Writer, comparison *Comparison) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"metric", "class", "a", "b", "difference", "lower", "upper", "p_value"})
+	m := comparison.McNemar
+	_ = cw.Write([]string{"mcnemar", "", strconv.Itoa(m.OnlyACorrect), strconv.Itoa(m.OnlyBCorrect),
+		strconv.Itoa(m.OnlyACorrect - m.OnlyBCorrect), "", "", formatFloat(m.PValue)})
+	for _, d := range comparison.Metrics {
+		_ = cw.Write([]string{d.Metric, d.Class, formatFloat(d.A), formatFloat(d.B), formatFloat(d.Difference),
+			formatFloat(d.Lower), formatFloat(d.Upper), formatFloat(d.PValue)})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write comparison: %w", err)
+	}
+	return nil
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
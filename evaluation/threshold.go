@@ -0,0 +1,71 @@
+package evaluation
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"fmt"
+)
+
+type ThresholdCriterion string
+
+const (
+	MaxF1Criterion           ThresholdCriterion = "f1"        // maximize the F1 score of the positive class
+	TargetPrecisionCriterion ThresholdCriterion = "precision" // maximize recall with precision >= target
+	TargetRecallCriterion    ThresholdCriterion = "recall"    // maximize precision with recall >= target
+)
+
+// SelectThreshold selects the decision threshold of the positive class on the data set, from the probabilities
+// predicted by the tree, calibrated if the tree has a calibrator. The target is ignored by MaxF1Criterion.
+// The threshold is not set to the tree.
+func SelectThreshold(tr *tree.Tree, dataTable *data.ValueTable, positiveClass string, criterion ThresholdCriterion, target float64) (*tree.DecisionThreshold, error) {
+	var (
+		scores    = make([]float64, len(dataTable.Instances))
+		positives = make([]bool, len(dataTable.Instances))
+	)
+	for i, instance := range dataTable.Instances {
+		probabilities, err := tr.PredictProba(instance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to predict probabilities of instance %d: %w", i, err)
+		}
+		scores[i] = probabilities[positiveClass]
+		positives[i] = instance.ClassValue.Value().(string) == positiveClass
+	}
+	curve, _ := PRCurve(scores, positives)
+	if curve == nil {
+		return nil, fmt.Errorf("data set has no positive or no negative instance of class '%s'", positiveClass)
+	}
+
+	// the curve is in descending order of threshold, so ties keep the highest threshold
+	var (
+		best      *PRPoint
+		bestValue float64
+	)
+	for _, point := range curve {
+		var value float64
+		switch criterion {
+		case MaxF1Criterion:
+			if point.Precision+point.Recall > 0 {
+				value = 2 * point.Precision * point.Recall / (point.Precision + point.Recall)
+			}
+		case TargetPrecisionCriterion:
+			if point.Precision < target {
+				continue
+			}
+			value = point.Recall
+		case TargetRecallCriterion:
+			if point.Recall < target {
+				continue
+			}
+			value = point.Precision
+		default:
+			return nil, fmt.Errorf("unknown threshold criterion '%s'", criterion)
+		}
+		if best == nil || value > bestValue {
+			best, bestValue = point, value
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no threshold reaches %s %v", criterion, target)
+	}
+	return &tree.DecisionThreshold{PositiveClass: positiveClass, Threshold: best.Threshold}, nil
+}
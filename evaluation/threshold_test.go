@@ -0,0 +1,56 @@
+package evaluation
+
+import (
+	"DecisionTree/tree"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectThreshold(t *testing.T) {
+	tr, dataTable := newTestData(t)
+
+	// class a: threshold 0.75 has precision 2/3 and recall 2/3, threshold 0 has precision 1/3 and recall 1
+	threshold, err := SelectThreshold(tr, dataTable, "a", MaxF1Criterion, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, &tree.DecisionThreshold{PositiveClass: "a", Threshold: 0.75}, threshold)
+
+	threshold, err = SelectThreshold(tr, dataTable, "a", TargetRecallCriterion, 0.9)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, threshold.Threshold)
+
+	threshold, err = SelectThreshold(tr, dataTable, "a", TargetPrecisionCriterion, 0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.75, threshold.Threshold)
+
+	_, err = SelectThreshold(tr, dataTable, "a", TargetPrecisionCriterion, 0.9)
+	assert.Error(t, err)
+	_, err = SelectThreshold(tr, dataTable, "a", "accuracy", 0)
+	assert.Error(t, err)
+	_, err = SelectThreshold(tr, dataTable, "e", MaxF1Criterion, 0)
+	assert.Error(t, err, "no positive instance")
+}
+
+func TestDecisionThreshold(t *testing.T) {
+	tr, dataTable := newTestData(t)
+
+	// b is predicted at the leaves x < 10 (p = 0.25) and 10 <= x < 20 (p = 0.75), c is the most probable other class
+	// at the leaf x >= 20
+	tr.Threshold = &tree.DecisionThreshold{PositiveClass: "b", Threshold: 0.2}
+	var predicted []string
+	for _, instance := range dataTable.Instances {
+		class, err := tr.Predict(instance)
+		assert.NoError(t, err)
+		predicted = append(predicted, class)
+	}
+	assert.Equal(t, []string{"b", "b", "b", "b", "b", "b", "c", "c", "c"}, predicted)
+
+	res, err := tree.TestRun(tr, dataTable)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, res.ClassPredictCount["b"])
+	assert.InDelta(t, 4.0/9, res.Accuracy, 1e-9)
+
+	explanation, err := tr.Explain(dataTable.Instances[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "b", explanation.PredictedClass)
+}
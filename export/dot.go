@@ -0,0 +1,53 @@
+package export
+
+import (
+	"DecisionTree/tree"
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteDot renders the tree in Graphviz DOT language.
+// Prioritized branches are drawn with bold edges.
+func WriteDot(w io.Writer, tr *tree.Tree, opts *GraphOptions) error {
+	if opts == nil {
+		opts = &GraphOptions{}
+	}
+	var (
+		bw      = bufio.NewWriter(w)
+		classes = classOrder(tr)
+	)
+	_, _ = fmt.Fprintln(bw, "digraph Tree {")
+	_, _ = fmt.Fprintln(bw, `  node [shape=box, style="rounded,filled", fillcolor="#ffffff", fontname="Helvetica"];`)
+	_, _ = fmt.Fprintln(bw, `  edge [fontname="Helvetica"];`)
+	walkGraph(tr, opts, func(n *graphNode) {
+		var attrs []string
+		attrs = append(attrs, fmt.Sprintf(`label="%s"`, escapeDot(strings.Join(nodeLabelLines(classes, n), "\n"))))
+		if opts.ColorByClass {
+			attrs = append(attrs, fmt.Sprintf(`fillcolor="%s"`, classColor(classes, majorityClass(classes, n.node))))
+		}
+		if n.truncated {
+			attrs = append(attrs, `style="rounded,filled,dashed"`)
+		}
+		_, _ = fmt.Fprintf(bw, "  %s [%s];\n", n.id, strings.Join(attrs, ", "))
+		if n.parent != nil {
+			edgeStyle := ""
+			if n.node.IsPrioritized {
+				edgeStyle = " [style=bold]"
+			}
+			_, _ = fmt.Fprintf(bw, "  %s -> %s%s;\n", n.parent.id, n.id, edgeStyle)
+		}
+	})
+	_, _ = fmt.Fprintln(bw, "}")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write dot: %w", err)
+	}
+	return nil
+}
+
+func escapeDot(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
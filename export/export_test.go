@@ -0,0 +1,462 @@
+package export
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testConf = &config.Config{ConsiderInvalidDataAsMissing: true}
+
+// newTestTree builds the tree:
+// age < 30 (prioritized) -> color in [red] -> yes, color in [green, "blue"] (prioritized) -> no
+// age >= 30 -> no
+func newTestTree() *tree.Tree {
+	var (
+		age       = data.NewContinuousAttribute("age")
+		color     = data.NewNominalAttribute("color", []string{"red", "green", `"blue"`})
+		classAttr = data.NewNominalAttribute("Class", []string{"yes", "no"})
+	)
+	return &tree.Tree{
+		Attributes: []data.Attribute{age, color},
+		Class:      classAttr,
+		RootNode: &tree.Node{ClassCount: map[string]float64{"yes": 5, "no": 5}, Children: []*tree.Node{
+			{
+				Condition:     tree.NewLessThanCondition(age, 30),
+				IsPrioritized: true,
+				ClassCount:    map[string]float64{"yes": 5, "no": 2},
+				Children: []*tree.Node{
+					{Condition: tree.NewIsOneOfCondition(color, []string{"red"}), LeafClass: "yes", ClassCount: map[string]float64{"yes": 4, "no": 0.5}},
+					{Condition: tree.NewIsOneOfCondition(color, []string{"green", `"blue"`}), LeafClass: "no", IsPrioritized: true, ClassCount: map[string]float64{"yes": 1, "no": 1.5}},
+				},
+			},
+			{Condition: tree.NewGreaterThanEqCondition(age, 30), LeafClass: "no", ClassCount: map[string]float64{"no": 3}},
+		}},
+	}
+}
+
+func newTestInstance(t *testing.T, tr *tree.Tree, row map[string]string) *data.Instance {
+	instance, err := data.ParseInstance(testConf, tr.Attributes, tr.Class, row)
+	if err != nil {
+		t.Fatalf("failed to parse instance: %v", err)
+	}
+	return instance
+}
+
+func TestWriteDot(t *testing.T) {
+	tr := newTestTree()
+	var buf bytes.Buffer
+	assert.NoError(t, WriteDot(&buf, tr, &GraphOptions{ColorByClass: true}))
+	out := buf.String()
+	t.Log("\n" + out)
+
+	assert.True(t, strings.HasPrefix(out, "digraph Tree {"))
+	assert.Contains(t, out, `age < 30.00 [prioritized]\nsamples = 7\nyes: 5, no: 2`)
+	assert.Contains(t, out, `color in ['green', '\"blue\"'] [prioritized]`)
+	assert.Contains(t, out, `yes: 4, no: 0.50\nclass = yes`)
+	assert.Equal(t, 6, strings.Count(out, "fillcolor=\"#"), "every node is colored, plus the default color")
+	assert.Equal(t, 2, strings.Count(out, "[style=bold]"))
+
+	// limit depth
+	buf.Reset()
+	assert.NoError(t, WriteDot(&buf, tr, &GraphOptions{MaxDepth: 2}))
+	assert.Contains(t, buf.String(), "... (2 more nodes)")
+	assert.Equal(t, 2, strings.Count(buf.String(), "->"))
+}
+
+func TestWriteMermaid(t *testing.T) {
+	tr := newTestTree()
+	var buf bytes.Buffer
+	assert.NoError(t, WriteMermaid(&buf, tr, &GraphOptions{ColorByClass: true}))
+	out := buf.String()
+	t.Log("\n" + out)
+
+	assert.True(t, strings.HasPrefix(out, "flowchart TD"))
+	assert.Contains(t, out, "age #lt; 30.00 [prioritized]<br/>samples = 7")
+	assert.Contains(t, out, "#quot;blue#quot;")
+	assert.Equal(t, 2, strings.Count(out, "==>"))
+	assert.Equal(t, 2, strings.Count(out, "-->"))
+	assert.Contains(t, out, "classDef class0 fill:")
+}
+
+func TestWriteHTML(t *testing.T) {
+	tr := newTestTree()
+	var buf bytes.Buffer
+	instance := newTestInstance(t, tr, map[string]string{"age": "20", "color": "red"})
+	assert.NoError(t, WriteHTML(&buf, tr, &HTMLOptions{Title: "Test <Tree>", Instance: instance}))
+	out := buf.String()
+
+	assert.Contains(t, out, "<title>Test &lt;Tree&gt;</title>")
+	assert.Contains(t, out, `"condition":"color in ['red']","attribute":"color"`)
+	assert.Equal(t, 3, strings.Count(out, `"on_path":true`), "root, age < 30 and color in [red] are on the path")
+	assert.Contains(t, out, "class=\"explanation\"")
+}
+
+func TestWriteGoCode(t *testing.T) {
+	tr := newTestTree()
+	var buf bytes.Buffer
+	assert.NoError(t, WriteGoCode(&buf, tr, &GoCodeOptions{PackageName: "adult"}))
+	out := buf.String()
+	t.Log("\n" + out)
+
+	assert.Contains(t, out, "package adult")
+	assert.Contains(t, out, "Age   *float64 `json:\"age\"`")
+	assert.Contains(t, out, "if *v >= 30.0 {")
+	assert.Contains(t, out, `case "red": // color in ['red']`)
+	// every leaf is written once, prioritized children only as the fallback
+	assert.Equal(t, 3, strings.Count(out, "return "))
+}
+
+func TestGoIdentifier(t *testing.T) {
+	assert.Equal(t, "EducationNum", goIdentifier("education-num"))
+	assert.Equal(t, "Attribute3", goIdentifier("Attribute 3"))
+	assert.Equal(t, "F1stAttr", goIdentifier("1st attr"))
+}
+
+func TestWriteSQL(t *testing.T) {
+	tr := newTestTree()
+	var buf bytes.Buffer
+	assert.NoError(t, WriteSQL(&buf, tr, &SQLOptions{Dialect: MySQL, ColumnNames: map[string]string{"age": "user age"}}))
+	assert.Contains(t, buf.String(), "WHEN `user age` >= 30 THEN 'no'")
+	assert.Contains(t, buf.String(), `WHEN `+"`color`"+` IN ('red') THEN 'yes'`)
+	assert.Error(t, WriteSQL(&buf, tr, &SQLOptions{Dialect: "unknown"}))
+}
+
+func TestExportRange(t *testing.T) {
+	var (
+		age       = data.NewContinuousAttribute("age")
+		rangeCond = tree.NewRangeCondition(age, 20, 40)
+	)
+	// range conditions include the lower bound and exclude the upper bound, as GreaterThanEq and LessThan conditions
+	for v, expected := range map[float64]bool{10: false, 20: true, 30: true, 40: false} {
+		assert.Equal(t, expected, rangeCond.IsMet(data.NewContinuousValue(age, v)), "range %v", v)
+	}
+	assert.Equal(t, "20.00 <= age < 40.00", rangeCond.Log())
+
+	g := &sqlGenerator{opts: &SQLOptions{Dialect: ANSI}}
+	expr, err := g.conditionExpr(rangeCond)
+	assert.NoError(t, err)
+	assert.Equal(t, `"age" >= 20 AND "age" < 40`, expr)
+	expr, err = goConditionExpr(rangeCond, "v")
+	assert.NoError(t, err)
+	assert.Equal(t, "v >= 20.0 && v < 40.0", expr)
+}
+
+func TestExportDecisionThreshold(t *testing.T) {
+	tr := newTestTree()
+	// the leaf {yes: 1, no: 1.5} predicts yes, the other leaves keep their class
+	tr.Threshold = &tree.DecisionThreshold{PositiveClass: "yes", Threshold: 0.4}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteSQL(&buf, tr, nil))
+	assert.Contains(t, buf.String(), "ELSE 'yes'")
+	assert.NotContains(t, buf.String(), "ELSE 'no'")
+
+	buf.Reset()
+	assert.NoError(t, WriteGoCode(&buf, tr, nil))
+	assert.Equal(t, 2, strings.Count(buf.String(), `return "yes"`))
+
+	buf.Reset()
+	assert.NoError(t, WritePMML(&buf, tr, nil))
+	assert.Contains(t, buf.String(), `score="yes" recordCount="2.5"`)
+
+	assert.ErrorIs(t, WriteONNX(&buf, tr, nil), errDecisionThreshold)
+}
+
+func TestWriteSQLOnSQLite(t *testing.T) {
+	sqlite, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("sqlite3 is not available")
+	}
+	tr := newTestTree()
+	var expr bytes.Buffer
+	assert.NoError(t, WriteSQL(&expr, tr, &SQLOptions{Dialect: SQLite}))
+	t.Log("\n" + expr.String())
+
+	rows := []map[string]string{
+		{"age": "20", "color": "red"},
+		{"age": "20", "color": "green"},
+		{"age": "20", "color": `"blue"`},
+		{"age": "20", "color": "purple"}, // unseen value
+		{"age": "20"},
+		{"age": "45", "color": "red"},
+		{"color": "red"},
+		{},
+	}
+	var script strings.Builder
+	script.WriteString("CREATE TABLE t (id INTEGER, age REAL, color TEXT);\n")
+	var expected []string
+	for i, row := range rows {
+		age, color := "NULL", "NULL"
+		if v, ok := row["age"]; ok {
+			age = v
+		}
+		if v, ok := row["color"]; ok {
+			color = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		}
+		script.WriteString(fmt.Sprintf("INSERT INTO t VALUES (%d, %s, %s);\n", i, age, color))
+
+		predicted, err := tr.Predict(newTestInstance(t, tr, row))
+		assert.NoError(t, err)
+		expected = append(expected, predicted)
+	}
+	script.WriteString("SELECT " + expr.String() + " FROM t ORDER BY id;\n")
+
+	cmd := exec.Command(sqlite, ":memory:")
+	cmd.Stdin = strings.NewReader(script.String())
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to run sqlite3: %v\n%s", err, output)
+	}
+	assert.Equal(t, expected, strings.Split(strings.TrimSpace(string(output)), "\n"))
+}
+
+func TestWritePMML(t *testing.T) {
+	tr := newTestTree()
+	var buf bytes.Buffer
+	assert.NoError(t, WritePMML(&buf, tr, nil))
+	out := buf.String()
+	t.Log("\n" + out)
+
+	assert.Contains(t, out, `<PMML xmlns="http://www.dmg.org/PMML-4_4" version="4.4">`)
+	assert.Contains(t, out, `<DataField name="age" optype="continuous" dataType="double"></DataField>`)
+	assert.Contains(t, out, `<MiningField name="Class" usageType="target"></MiningField>`)
+	assert.Contains(t, out, `<SimplePredicate field="age" operator="lessThan" value="30"></SimplePredicate>`)
+	assert.Contains(t, out, `<SimplePredicate field="color" operator="equal" value="red"></SimplePredicate>`)
+	assert.Contains(t, out, `<Array n="2" type="string">&#34;green&#34; &#34;\&#34;blue\&#34;&#34;</Array>`)
+	assert.Contains(t, out, `<ScoreDistribution value="yes" recordCount="4" probability="0.8888888888888888"></ScoreDistribution>`)
+	assert.Equal(t, 2, strings.Count(out, "defaultChild="))
+
+	// the prioritized child accepts the values no sibling accepts
+	color := tr.Attributes[1].(*data.NominalAttribute)
+	color.AcceptedValues = append(color.AcceptedValues, "purple")
+	buf.Reset()
+	assert.NoError(t, WritePMML(&buf, tr, nil))
+	assert.Contains(t, buf.String(), `<Array n="3" type="string">&#34;green&#34; &#34;\&#34;blue\&#34;&#34; &#34;purple&#34;</Array>`)
+}
+
+func TestReadPMML(t *testing.T) {
+	tr := newTestTree()
+	var buf bytes.Buffer
+	assert.NoError(t, WritePMML(&buf, tr, nil))
+	imported, err := ReadPMML(&buf)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Class", imported.Class.Name())
+	assert.Equal(t, []string{"yes", "no"}, imported.Class.AcceptedValues)
+	assert.Len(t, imported.Attributes, 2)
+	assert.Equal(t, tr.GetNodeCount(), imported.GetNodeCount())
+	assert.True(t, imported.RootNode.Children[0].IsPrioritized)
+	assert.False(t, imported.RootNode.Children[1].IsPrioritized)
+	assert.Equal(t, tr.RootNode.Children[0].ClassCount, imported.RootNode.Children[0].ClassCount)
+
+	for _, row := range []map[string]string{
+		{"age": "20", "color": "red"},
+		{"age": "20", "color": `"blue"`},
+		{"age": "20"},
+		{"age": "45", "color": "green"},
+		{},
+	} {
+		expected, err := tr.Predict(newTestInstance(t, tr, row))
+		assert.NoError(t, err)
+		actual, err := imported.Predict(newTestInstance(t, imported, row))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "row %v", row)
+	}
+}
+
+func TestReadPMMLErrors(t *testing.T) {
+	const doc = `<PMML version="4.3">
+  <DataDictionary numberOfFields="2">
+    <DataField name="x" optype="continuous" dataType="double"/>
+    <DataField name="y" optype="categorical" dataType="string"/>
+  </DataDictionary>
+  <TreeModel functionName="classification">
+    <MiningSchema><MiningField name="x"/><MiningField name="y" usageType="target"/></MiningSchema>
+    <Node score="a"><True/>
+      <Node score="a"><SimplePredicate field="x" operator="%s" value="1"/></Node>
+      <Node score="b"><SimplePredicate field="x" operator="greaterOrEqual" value="1"/></Node>
+    </Node>
+  </TreeModel>
+</PMML>`
+	imported, err := ReadPMML(strings.NewReader(fmt.Sprintf(doc, "lessThan")))
+	assert.NoError(t, err)
+	assert.Equal(t, "x < 1.00", imported.RootNode.Children[0].Condition.Log())
+
+	_, err = ReadPMML(strings.NewReader(fmt.Sprintf(doc, "lessOrEqual")))
+	assert.ErrorContains(t, err, "unsupported operator 'lessOrEqual'")
+}
+
+func TestPMMLArray(t *testing.T) {
+	values, err := parsePMMLArray(formatPMMLArray([]string{"a b", `c"d`, `e\`}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a b", `c"d`, `e\`}, values)
+
+	values, err = parsePMMLArray(`x  "y z"	w`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x", "y z", "w"}, values)
+
+	_, err = parsePMMLArray(`"x`)
+	assert.Error(t, err)
+}
+
+// newOrdinalTestTree builds the tree:
+// size <= 'medium' (prioritized) -> yes
+// size > 'medium' -> no
+func newOrdinalTestTree() *tree.Tree {
+	var (
+		size      = data.NewOrdinalAttribute("size", []string{"small", "medium", "large"})
+		classAttr = data.NewNominalAttribute("Class", []string{"yes", "no"})
+	)
+	return &tree.Tree{
+		Attributes: []data.Attribute{size},
+		Class:      classAttr,
+		RootNode: &tree.Node{ClassCount: map[string]float64{"yes": 6, "no": 4}, Children: []*tree.Node{
+			{Condition: tree.NewLessEqCondition(size, "medium"), IsPrioritized: true, LeafClass: "yes", ClassCount: map[string]float64{"yes": 6, "no": 1}},
+			{Condition: tree.NewGreaterCondition(size, "medium"), LeafClass: "no", ClassCount: map[string]float64{"no": 3}},
+		}},
+	}
+}
+
+func TestExportOrdinal(t *testing.T) {
+	tr := newOrdinalTestTree()
+	rows := []map[string]string{{"size": "small"}, {"size": "medium"}, {"size": "large"}, {"size": "huge"}, {}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteSQL(&buf, tr, nil))
+	assert.Contains(t, buf.String(), `"size" IN ('large')`)
+
+	buf.Reset()
+	assert.NoError(t, WriteGoCode(&buf, tr, nil))
+	assert.Contains(t, buf.String(), `Size *string`)
+	assert.Contains(t, buf.String(), `if *v == "large" {`)
+
+	// ordinal conditions are compared in the order of the values of the data field
+	buf.Reset()
+	assert.NoError(t, WritePMML(&buf, tr, nil))
+	assert.Contains(t, buf.String(), `<DataField name="size" optype="ordinal" dataType="string">`)
+	assert.Contains(t, buf.String(), `<SimplePredicate field="size" operator="greaterThan" value="medium"></SimplePredicate>`)
+	imported, err := ReadPMML(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, data.Ordinal, imported.Attributes[0].Type())
+	assert.Equal(t, tree.LessEq, imported.RootNode.Children[0].Condition.Type())
+	for _, row := range rows {
+		expected, err := tr.Predict(newTestInstance(t, tr, row))
+		assert.NoError(t, err)
+		actual, err := imported.Predict(newTestInstance(t, imported, row))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "row %v", row)
+	}
+
+	// label encoded levels are compared with a single node, one-hot encoded ones with a chain
+	model := checkONNXModel(t, tr, nil, rows)
+	assert.Equal(t, []string{"BRANCH_LEQ", "LEAF", "LEAF"}, model.ensemble.strings["nodes_modes"])
+	model = checkONNXModel(t, tr, &ONNXOptions{Encoding: OneHotEncoding}, rows)
+	assert.Equal(t, []string{"BRANCH_EQ", "LEAF", "LEAF"}, model.ensemble.strings["nodes_modes"])
+}
+
+// newDateTimeTestTree builds the tree:
+// time < 2021-01-01 (prioritized) -> yes
+// time >= 2021-01-01 -> time.month < 6.5 -> yes, time.month >= 6.5 (prioritized) -> no
+func newDateTimeTestTree() *tree.Tree {
+	var (
+		dateTime  = data.NewDateTimeAttribute("time", nil)
+		month     = data.NewDateTimePartAttribute(dateTime, data.Month)
+		classAttr = data.NewNominalAttribute("Class", []string{"yes", "no"})
+		newYear   = float64(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Unix())
+	)
+	return &tree.Tree{
+		Attributes: []data.Attribute{dateTime},
+		Class:      classAttr,
+		RootNode: &tree.Node{ClassCount: map[string]float64{"yes": 6, "no": 4}, Children: []*tree.Node{
+			{Condition: tree.NewLessThanCondition(dateTime, newYear), IsPrioritized: true, LeafClass: "yes", ClassCount: map[string]float64{"yes": 4}},
+			{Condition: tree.NewGreaterThanEqCondition(dateTime, newYear), ClassCount: map[string]float64{"yes": 2, "no": 4}, Children: []*tree.Node{
+				{Condition: tree.NewLessThanCondition(month, 6.5), LeafClass: "yes", ClassCount: map[string]float64{"yes": 2, "no": 1}},
+				{Condition: tree.NewGreaterThanEqCondition(month, 6.5), IsPrioritized: true, LeafClass: "no", ClassCount: map[string]float64{"no": 3}},
+			}},
+		}},
+	}
+}
+
+func TestExportDateTime(t *testing.T) {
+	tr := newDateTimeTestTree()
+	assert.Equal(t, "time < 2021-01-01", tr.RootNode.Children[0].Condition.Log())
+
+	for dialect, expected := range map[SQLDialect]string{
+		ANSI:      `WHEN EXTRACT(MONTH FROM "time") < 6.5 THEN 'yes'`,
+		SQLite:    `WHEN CAST(strftime('%m', "time") AS INTEGER) < 6.5 THEN 'yes'`,
+		MySQL:     "WHEN MONTH(`time`) < 6.5 THEN 'yes'",
+		SQLServer: "WHEN DATEPART(month, [time]) < 6.5 THEN 'yes'",
+	} {
+		var buf bytes.Buffer
+		assert.NoError(t, WriteSQL(&buf, tr, &SQLOptions{Dialect: dialect}))
+		literal := "'2021-01-01 00:00:00'"
+		if dialect == ANSI {
+			literal = "TIMESTAMP " + literal
+		}
+		assert.Contains(t, buf.String(), literal, "dialect %s", dialect)
+		assert.Contains(t, buf.String(), expected, "dialect %s", dialect)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteGoCode(&buf, tr, nil))
+	assert.Contains(t, buf.String(), `import "time"`)
+	assert.Contains(t, buf.String(), `Time *time.Time`)
+	assert.Contains(t, buf.String(), `if !(*v).Before(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)) {`)
+	assert.Contains(t, buf.String(), `if float64((*v).Month()) < 6.5 {`)
+
+	// the splits by the parts are not supported by PMML
+	assert.Error(t, WritePMML(&bytes.Buffer{}, tr, nil))
+
+	// the month is derived into a column of its own
+	model := checkONNXModel(t, tr, nil, []map[string]string{
+		{"time": "2020-07-01"},
+		{"time": "2021-03-01"},
+		{"time": "2021-09-01"},
+		{},
+	})
+	assert.Equal(t, []*ONNXFeature{{Attribute: "time"}, {Attribute: "time", Part: data.Month}}, model.features)
+}
+
+func TestWriteDateTimeSQLOnSQLite(t *testing.T) {
+	sqlite, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("sqlite3 is not available")
+	}
+	tr := newDateTimeTestTree()
+	var expr bytes.Buffer
+	assert.NoError(t, WriteSQL(&expr, tr, &SQLOptions{Dialect: SQLite}))
+
+	var (
+		script   strings.Builder
+		expected []string
+	)
+	script.WriteString("CREATE TABLE t (id INTEGER, time TEXT);\n")
+	for i, value := range []string{"2020-07-01 00:00:00", "2021-03-01 12:00:00", "2021-09-01 00:00:00", ""} {
+		row, literal := map[string]string{}, "NULL"
+		if value != "" {
+			row["time"], literal = value, "'"+value+"'"
+		}
+		script.WriteString(fmt.Sprintf("INSERT INTO t VALUES (%d, %s);\n", i, literal))
+		predicted, err := tr.Predict(newTestInstance(t, tr, row))
+		assert.NoError(t, err)
+		expected = append(expected, predicted)
+	}
+	script.WriteString("SELECT " + expr.String() + " FROM t ORDER BY id;\n")
+
+	cmd := exec.Command(sqlite, ":memory:")
+	cmd.Stdin = strings.NewReader(script.String())
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to run sqlite3: %v\n%s", err, output)
+	}
+	assert.Equal(t, expected, strings.Split(strings.TrimSpace(string(output)), "\n"))
+}
@@ -0,0 +1,312 @@
+package export
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GoCodeOptions controls the generated Go source code.
+type GoCodeOptions struct {
+	PackageName string // default "model"
+	StructName  string // default "Instance"
+	FuncName    string // default "Predict"
+}
+
+// WriteGoCode compiles the tree into a dependency-free Go source file, which contains a struct holding the
+// attribute values and a function predicting the class with nested if/switch statements. The leaves return their
+// prediction by the decision threshold of the tree, if any.
+// Struct fields are pointers, a nil field is a missing value and follows the prioritized branch.
+func WriteGoCode(w io.Writer, tr *tree.Tree, opts *GoCodeOptions) error {
+	if tr.IsModelTree() {
+		return errModelTree
+	}
+	if opts == nil {
+		opts = &GoCodeOptions{}
+	}
+	g := &goCodeGenerator{
+		tr:         tr,
+		opts:       *opts,
+		fieldNames: make(map[string]string),
+	}
+	if g.opts.PackageName == "" {
+		g.opts.PackageName = "model"
+	}
+	if g.opts.StructName == "" {
+		g.opts.StructName = "Instance"
+	}
+	if g.opts.FuncName == "" {
+		g.opts.FuncName = "Predict"
+	}
+
+	g.writeHeader(tr)
+	if err := g.writeStruct(tr); err != nil {
+		return err
+	}
+	g.printf("// %s predicts the class of the instance, an empty string is returned if the class cannot be decided.\n", g.opts.FuncName)
+	g.printf("func %s(in *%s) string {\n", g.opts.FuncName, g.opts.StructName)
+	if err := g.writeNode(tr.RootNode); err != nil {
+		return err
+	}
+	g.printf("}\n")
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format generated code: %w", err)
+	}
+	if _, err := w.Write(src); err != nil {
+		return fmt.Errorf("failed to write generated code: %w", err)
+	}
+	return nil
+}
+
+type goCodeGenerator struct {
+	tr         *tree.Tree
+	opts       GoCodeOptions
+	buf        bytes.Buffer
+	fieldNames map[string]string // attribute name -> struct field name
+}
+
+func (g *goCodeGenerator) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *goCodeGenerator) writeHeader(tr *tree.Tree) {
+	g.printf("// Code generated by DecisionTree; DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", g.opts.PackageName)
+	for _, attr := range tr.Attributes {
+		if attr.Type() == data.DateTime {
+			g.printf("import \"time\"\n\n")
+			break
+		}
+	}
+	if tr.Class != nil && len(tr.Class.AcceptedValues) > 0 {
+		g.printf("// Classes are the class values that %s may return.\n", g.opts.FuncName)
+		g.printf("var Classes = []string{")
+		for i, class := range tr.Class.AcceptedValues {
+			if i > 0 {
+				g.printf(", ")
+			}
+			g.printf("%s", strconv.Quote(class))
+		}
+		g.printf("}\n\n")
+	}
+}
+
+func (g *goCodeGenerator) writeStruct(tr *tree.Tree) error {
+	used := make(map[string]bool)
+	g.printf("// %s holds the attribute values of an instance, a nil field means the value is missing.\n", g.opts.StructName)
+	g.printf("type %s struct {\n", g.opts.StructName)
+	for _, attr := range tr.Attributes {
+		fieldType, err := goFieldType(attr)
+		if err != nil {
+			return err
+		}
+		name := goIdentifier(attr.Name())
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s%d", goIdentifier(attr.Name()), i)
+		}
+		used[name] = true
+		g.fieldNames[attr.Name()] = name
+		g.printf("%s %s `json:%s`", name, fieldType, strconv.Quote(attr.Name()))
+		if nominalAttr, ok := attr.(*data.NominalAttribute); ok && len(nominalAttr.AcceptedValues) > 0 {
+			g.printf(" // one of: %s", strings.Join(nominalAttr.AcceptedValues, ", "))
+		}
+		g.printf("\n")
+	}
+	g.printf("}\n\n")
+	return nil
+}
+
+func goFieldType(attr data.Attribute) (string, error) {
+	switch attr.Type() {
+	case data.Continuous:
+		return "*float64", nil
+	case data.Nominal, data.Ordinal:
+		return "*string", nil
+	case data.DateTime:
+		return "*time.Time", nil
+	default:
+		return "", fmt.Errorf("unsupported attribute type '%s' of attribute '%s'", attr.Type(), attr.Name())
+	}
+}
+
+// writeNode writes the statements returning the prediction of the subtree.
+// Children other than the prioritized one are checked first, if none of them is met (or the value is missing),
+// the code falls through to the prioritized child.
+func (g *goCodeGenerator) writeNode(node *tree.Node) error {
+	if len(node.Children) == 0 {
+		g.printf("return %s\n", strconv.Quote(g.tr.LeafPrediction(node)))
+		return nil
+	}
+
+	var (
+		attr        = node.Children[0].Condition.Attr()
+		field       = "in." + g.fieldNames[attr.Name()]
+		prioritized *tree.Node
+		others      []*tree.Node
+	)
+	if part, ok := attr.(*data.DateTimePartAttribute); ok {
+		field = "in." + g.fieldNames[part.Parent.Name()]
+	}
+	// the conditions compare *v, or v itself for the weighted sum of the fields of oblique conditions
+	var (
+		open  = fmt.Sprintf("if v := %s; v != nil {\n", field)
+		value = "*v"
+	)
+	if combination, ok := attr.(*data.LinearCombinationAttribute); ok {
+		var (
+			checks []string
+			terms  []string
+		)
+		for i, a := range combination.Attributes {
+			f := "in." + g.fieldNames[a.Name()]
+			checks = append(checks, f+" != nil")
+			terms = append(terms, fmt.Sprintf("%s*(*%s)", goFloat(combination.Weights[i]), f))
+		}
+		open = fmt.Sprintf("if %s {\nv := %s\n", strings.Join(checks, " && "), strings.Join(terms, " + "))
+		value = "v"
+	}
+	for _, child := range node.Children {
+		if child.IsPrioritized && prioritized == nil {
+			prioritized = child
+		} else {
+			others = append(others, child)
+		}
+	}
+
+	if len(others) > 0 {
+		g.printf("%s", open)
+		if attr.Type() == data.Nominal && allIsOneOf(others) {
+			g.printf("switch *v {\n")
+			for _, child := range others {
+				var values []string
+				for _, v := range child.Condition.(*tree.NominalCondition).AcceptedValues() {
+					values = append(values, strconv.Quote(v))
+				}
+				g.printf("case %s: // %s\n", strings.Join(values, ", "), child.Condition.Log())
+				if err := g.writeNode(child); err != nil {
+					return err
+				}
+			}
+			g.printf("}\n")
+		} else {
+			for _, child := range others {
+				expr, err := goConditionExpr(child.Condition, value)
+				if err != nil {
+					return err
+				}
+				g.printf("if %s {\n", expr)
+				if err := g.writeNode(child); err != nil {
+					return err
+				}
+				g.printf("}\n")
+			}
+		}
+		g.printf("}\n")
+	}
+
+	if prioritized == nil {
+		g.printf("return \"\"\n")
+		return nil
+	}
+	g.printf("// %s (prioritized)\n", prioritized.Condition.Log())
+	return g.writeNode(prioritized)
+}
+
+func allIsOneOf(nodes []*tree.Node) bool {
+	for _, node := range nodes {
+		if node.Condition.Type() != tree.IsOneOf {
+			return false
+		}
+	}
+	return true
+}
+
+func goConditionExpr(condition tree.Condition, v string) (string, error) {
+	switch c := condition.(type) {
+	case *tree.ContinuousCondition:
+		if dateTimeAttr, ok := c.Attr().(*data.DateTimeAttribute); ok {
+			switch c.Type() {
+			case tree.LessThan:
+				return fmt.Sprintf("(%s).Before(%s)", v, goTime(dateTimeAttr, c.UpperValue())), nil
+			case tree.GreaterThanEq:
+				return fmt.Sprintf("!(%s).Before(%s)", v, goTime(dateTimeAttr, c.LowerValue())), nil
+			case tree.Range:
+				return fmt.Sprintf("!(%s).Before(%s) && (%s).Before(%s)", v, goTime(dateTimeAttr, c.LowerValue()), v, goTime(dateTimeAttr, c.UpperValue())), nil
+			}
+		}
+		if part, ok := c.Attr().(*data.DateTimePartAttribute); ok {
+			v = fmt.Sprintf("float64((%s).%s())", v, goIdentifier(string(part.Part)))
+		}
+		switch c.Type() {
+		case tree.LessThan:
+			return fmt.Sprintf("%s < %s", v, goFloat(c.UpperValue())), nil
+		case tree.GreaterThanEq:
+			return fmt.Sprintf("%s >= %s", v, goFloat(c.LowerValue())), nil
+		case tree.Range:
+			return fmt.Sprintf("%s >= %s && %s < %s", v, goFloat(c.LowerValue()), v, goFloat(c.UpperValue())), nil
+		}
+	case *tree.ObliqueCondition:
+		switch c.Type() {
+		case tree.ObliqueLessThan:
+			return fmt.Sprintf("%s < %s", v, goFloat(c.Threshold())), nil
+		case tree.ObliqueGreaterThanEq:
+			return fmt.Sprintf("%s >= %s", v, goFloat(c.Threshold())), nil
+		}
+	case tree.ValueSetCondition:
+		var parts []string
+		for _, value := range c.AcceptedValues() {
+			parts = append(parts, fmt.Sprintf("%s == %s", v, strconv.Quote(value)))
+		}
+		if len(parts) == 0 {
+			return "false", nil
+		}
+		return strings.Join(parts, " || "), nil
+	}
+	return "", fmt.Errorf("unsupported condition type '%s'", condition.Type())
+}
+
+// goTime returns the expression of the time of the threshold of the datetime attribute.
+func goTime(attr *data.DateTimeAttribute, v float64) string {
+	t := attr.Time(v)
+	return fmt.Sprintf("time.Date(%d, time.%s, %d, %d, %d, %d, %d, time.UTC)", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
+}
+
+func goFloat(v float64) string {
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// goIdentifier converts an attribute name into an exported Go identifier, e.g. "education-num" -> "EducationNum".
+func goIdentifier(name string) string {
+	var (
+		sb        strings.Builder
+		upperNext = true
+	)
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		sb.WriteRune(r)
+	}
+	res := sb.String()
+	if res == "" || !unicode.IsUpper([]rune(res)[0]) {
+		res = "F" + res
+	}
+	return res
+}
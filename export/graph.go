@@ -0,0 +1,150 @@
+package export
+
+import (
+	"DecisionTree/tree"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// errModelTree is returned by the exporters of the predictions, the leaves of model trees predict by linear models
+// which they cannot express. The graphs still show the nodes of model trees.
+var errModelTree = errors.New("model trees are not supported, their leaves predict by linear models")
+
+// errDecisionThreshold is returned by the exporters that cannot predict by the decision threshold of the tree
+var errDecisionThreshold = errors.New("trees with a decision threshold are not supported, the most probable class would be predicted")
+
+// GraphOptions controls how a tree is rendered as a graph.
+type GraphOptions struct {
+	// MaxDepth limits the depth of rendered nodes, the root node is at depth 1. Nodes at the max depth which have
+	// children are rendered as truncated. 0 means no limit.
+	MaxDepth int
+	// ColorByClass fills each node with the color of its majority class.
+	ColorByClass bool
+}
+
+var classPalette = []string{
+	"#8dd3c7", "#ffffb3", "#bebada", "#fb8072", "#80b1d3",
+	"#fdb462", "#b3de69", "#fccde5", "#d9d9d9", "#bc80bd",
+}
+
+// graphNode is a node of the tree prepared for rendering.
+type graphNode struct {
+	id        string
+	node      *tree.Node
+	parent    *graphNode
+	depth     int
+	truncated bool // has children which are not rendered
+}
+
+// walkGraph visits the nodes to render in depth-first order.
+func walkGraph(tr *tree.Tree, opts *GraphOptions, visit func(n *graphNode)) {
+	var walk func(node *tree.Node, parent *graphNode, depth int)
+	walk = func(node *tree.Node, parent *graphNode, depth int) {
+		n := &graphNode{
+			id:     fmt.Sprintf("n%d", node.UniqId()),
+			node:   node,
+			parent: parent,
+			depth:  depth,
+		}
+		n.truncated = len(node.Children) > 0 && opts.MaxDepth > 0 && depth >= opts.MaxDepth
+		visit(n)
+		if n.truncated {
+			return
+		}
+		for _, child := range node.Children {
+			walk(child, n, depth+1)
+		}
+	}
+	walk(tr.RootNode, nil, 1)
+}
+
+// classOrder returns the class values in the declared order, or sorted class values seen in the tree if the
+// class attribute is unknown.
+func classOrder(tr *tree.Tree) []string {
+	if tr.Class != nil && len(tr.Class.AcceptedValues) > 0 {
+		return tr.Class.AcceptedValues
+	}
+	seen := make(map[string]bool)
+	var collect func(node *tree.Node)
+	collect = func(node *tree.Node) {
+		for class := range node.ClassCount {
+			seen[class] = true
+		}
+		if node.LeafClass != "" {
+			seen[node.LeafClass] = true
+		}
+		for _, child := range node.Children {
+			collect(child)
+		}
+	}
+	collect(tr.RootNode)
+	var res []string
+	for class := range seen {
+		res = append(res, class)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func classColor(classes []string, class string) string {
+	for i, c := range classes {
+		if c == class {
+			return classPalette[i%len(classPalette)]
+		}
+	}
+	return "#ffffff"
+}
+
+// majorityClass returns the leaf class of a leaf node, or the class with the largest count of an internal node.
+func majorityClass(classes []string, node *tree.Node) string {
+	if len(node.Children) == 0 && node.LeafClass != "" {
+		return node.LeafClass
+	}
+	var (
+		res      string
+		maxCount = 0.0
+	)
+	for _, class := range classes {
+		if node.ClassCount[class] > maxCount {
+			res, maxCount = class, node.ClassCount[class]
+		}
+	}
+	return res
+}
+
+// nodeLabelLines returns the text lines describing a node: the condition met to reach it, sample count, class
+// distribution and the leaf class.
+func nodeLabelLines(classes []string, n *graphNode) []string {
+	var lines []string
+	if n.node.Condition != nil {
+		condition := n.node.Condition.Log()
+		if n.node.IsPrioritized {
+			condition += " [prioritized]"
+		}
+		lines = append(lines, condition)
+	}
+	if len(n.node.ClassCount) > 0 {
+		lines = append(lines, "samples = "+formatCount(n.node.GetSampleCount()))
+		var distribution []string
+		for _, class := range classes {
+			distribution = append(distribution, class+": "+formatCount(n.node.ClassCount[class]))
+		}
+		lines = append(lines, strings.Join(distribution, ", "))
+	}
+	switch {
+	case len(n.node.Children) == 0:
+		lines = append(lines, "class = "+n.node.LeafClass)
+	case n.truncated:
+		lines = append(lines, fmt.Sprintf("... (%d more nodes)", n.node.GetNodeCount()-1))
+	}
+	return lines
+}
+
+func formatCount(count float64) string {
+	if count == float64(int64(count)) {
+		return fmt.Sprintf("%d", int64(count))
+	}
+	return fmt.Sprintf("%.2f", count)
+}
@@ -0,0 +1,118 @@
+package export
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+)
+
+//go:embed html_template.html
+var htmlTemplateContent string
+
+var htmlTemplate = template.Must(template.New("tree").Parse(htmlTemplateContent))
+
+// HTMLOptions controls how a tree is rendered as an HTML page.
+type HTMLOptions struct {
+	Title string
+	// Instance is optional, the decision path of the instance is highlighted if it is set.
+	Instance *data.Instance
+	// ExpandDepth is the depth to which nodes are initially expanded, the root node is at depth 1.
+	// 0 means 3. The decision path of the instance is always expanded.
+	ExpandDepth int
+}
+
+type htmlNode struct {
+	Id           int          `json:"id"`
+	Condition    string       `json:"condition,omitempty"`
+	Attribute    string       `json:"attribute,omitempty"` // attribute of the condition
+	Prioritized  bool         `json:"prioritized,omitempty"`
+	Samples      float64      `json:"samples"`
+	Distribution []*htmlCount `json:"distribution,omitempty"`
+	Impurity     float64      `json:"impurity"`
+	LeafClass    string       `json:"leaf_class,omitempty"`
+	Color        string       `json:"color"`
+	OnPath       bool         `json:"on_path,omitempty"`
+	Children     []*htmlNode  `json:"children,omitempty"`
+}
+
+type htmlCount struct {
+	Class string  `json:"class"`
+	Count float64 `json:"count"`
+}
+
+type htmlPage struct {
+	Title       string
+	Tree        *htmlNode
+	Classes     []string
+	Colors      []string
+	ExpandDepth int
+	Explanation string
+}
+
+// WriteHTML renders the tree as a standalone HTML page with collapsible nodes, hover details and search by
+// attribute. It does not require any server or external resource.
+func WriteHTML(w io.Writer, tr *tree.Tree, opts *HTMLOptions) error {
+	if opts == nil {
+		opts = &HTMLOptions{}
+	}
+	page := &htmlPage{
+		Title:       opts.Title,
+		Classes:     classOrder(tr),
+		ExpandDepth: opts.ExpandDepth,
+	}
+	if page.Title == "" {
+		page.Title = "Decision Tree"
+	}
+	if page.ExpandDepth == 0 {
+		page.ExpandDepth = 3
+	}
+	for _, class := range page.Classes {
+		page.Colors = append(page.Colors, classColor(page.Classes, class))
+	}
+
+	onPath := make(map[int]bool)
+	if opts.Instance != nil {
+		explanation, err := tr.Explain(opts.Instance)
+		if err != nil {
+			return fmt.Errorf("failed to explain instance: %w", err)
+		}
+		for _, step := range explanation.Steps {
+			onPath[step.NodeId] = true
+		}
+		page.Explanation = explanation.String()
+	}
+	page.Tree = newHTMLNode(page.Classes, tr.RootNode, onPath)
+
+	if err := htmlTemplate.Execute(w, page); err != nil {
+		return fmt.Errorf("failed to render html: %w", err)
+	}
+	return nil
+}
+
+func newHTMLNode(classes []string, node *tree.Node, onPath map[int]bool) *htmlNode {
+	res := &htmlNode{
+		Id:          node.UniqId(),
+		Prioritized: node.IsPrioritized,
+		Samples:     node.GetSampleCount(),
+		Impurity:    node.Entropy(),
+		LeafClass:   node.LeafClass,
+		Color:       classColor(classes, majorityClass(classes, node)),
+		OnPath:      onPath[node.UniqId()],
+	}
+	if node.Condition != nil {
+		res.Condition = node.Condition.Log()
+		res.Attribute = node.Condition.Attr().Name()
+	}
+	if len(node.ClassCount) > 0 {
+		for _, class := range classes {
+			res.Distribution = append(res.Distribution, &htmlCount{Class: class, Count: node.ClassCount[class]})
+		}
+	}
+	for _, child := range node.Children {
+		res.Children = append(res.Children, newHTMLNode(classes, child, onPath))
+	}
+	return res
+}
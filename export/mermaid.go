@@ -0,0 +1,63 @@
+package export
+
+import (
+	"DecisionTree/tree"
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMermaid renders the tree as a Mermaid flowchart.
+// Prioritized branches are drawn with thick links.
+func WriteMermaid(w io.Writer, tr *tree.Tree, opts *GraphOptions) error {
+	if opts == nil {
+		opts = &GraphOptions{}
+	}
+	var (
+		bw         = bufio.NewWriter(w)
+		classes    = classOrder(tr)
+		classNodes = make(map[string][]string)
+	)
+	_, _ = fmt.Fprintln(bw, "flowchart TD")
+	walkGraph(tr, opts, func(n *graphNode) {
+		var lines []string
+		for _, line := range nodeLabelLines(classes, n) {
+			lines = append(lines, escapeMermaid(line))
+		}
+		_, _ = fmt.Fprintf(bw, "  %s[\"%s\"]\n", n.id, strings.Join(lines, "<br/>"))
+		if n.parent != nil {
+			link := "-->"
+			if n.node.IsPrioritized {
+				link = "==>"
+			}
+			_, _ = fmt.Fprintf(bw, "  %s %s %s\n", n.parent.id, link, n.id)
+		}
+		if opts.ColorByClass {
+			class := majorityClass(classes, n.node)
+			classNodes[class] = append(classNodes[class], n.id)
+		}
+	})
+	if opts.ColorByClass {
+		for i, class := range classes {
+			if len(classNodes[class]) == 0 {
+				continue
+			}
+			_, _ = fmt.Fprintf(bw, "  classDef class%d fill:%s\n", i, classColor(classes, class))
+			_, _ = fmt.Fprintf(bw, "  class %s class%d\n", strings.Join(classNodes[class], ","), i)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write mermaid: %w", err)
+	}
+	return nil
+}
+
+// escapeMermaid replaces characters which have special meanings in Mermaid labels with entity codes.
+func escapeMermaid(s string) string {
+	return strings.NewReplacer(
+		`"`, "#quot;",
+		"<", "#lt;",
+		">", "#gt;",
+	).Replace(s)
+}
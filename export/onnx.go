@@ -0,0 +1,463 @@
+package export
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// ONNXEncoding is how nominal attributes are encoded into the float input tensor of the ONNX model.
+type ONNXEncoding string
+
+const (
+	LabelEncoding  ONNXEncoding = "label"  // one column per nominal attribute holding the index of the value
+	OneHotEncoding ONNXEncoding = "onehot" // one column per nominal value holding 1 if the attribute has the value
+)
+
+// ONNXOptions controls the generated ONNX model.
+type ONNXOptions struct {
+	Encoding  ONNXEncoding // default LabelEncoding
+	GraphName string       // default "DecisionTree"
+}
+
+// ONNXFeature describes a column of the input tensor of the ONNX model.
+type ONNXFeature struct {
+	Attribute string   `json:"attribute"`
+	Value     string   `json:"value,omitempty"`  // one-hot encoded nominal value of the column
+	Labels    []string `json:"labels,omitempty"` // label encoded nominal values, the label of a value is its index
+	// part of the datetime attribute of the column, see data.DateTimePartAttribute
+	Part data.DateTimePart `json:"part,omitempty"`
+}
+
+// ONNXFeatures returns the columns of the input tensor of the ONNX model written for the tree.
+// Nominal values are the accepted values of the attributes, followed by values only seen in the conditions.
+func ONNXFeatures(tr *tree.Tree, opts *ONNXOptions) []*ONNXFeature {
+	encoding := LabelEncoding
+	if opts != nil && opts.Encoding != "" {
+		encoding = opts.Encoding
+	}
+	var (
+		conditionValues = make(map[string][]string)
+		conditionParts  = make(map[string][]data.DateTimePart)
+		collect         func(node *tree.Node)
+	)
+	collect = func(node *tree.Node) {
+		if c, ok := node.Condition.(tree.ValueSetCondition); ok {
+			conditionValues[c.Attr().Name()] = append(conditionValues[c.Attr().Name()], c.AcceptedValues()...)
+		}
+		if node.Condition != nil {
+			if part, ok := node.Condition.Attr().(*data.DateTimePartAttribute); ok && !slices.Contains(conditionParts[part.Parent.Name()], part.Part) {
+				conditionParts[part.Parent.Name()] = append(conditionParts[part.Parent.Name()], part.Part)
+			}
+		}
+		for _, child := range node.Children {
+			collect(child)
+		}
+	}
+	collect(tr.RootNode)
+
+	var features []*ONNXFeature
+	for _, attr := range tr.Attributes {
+		nominalAttr, ok := attr.(*data.NominalAttribute)
+		if !ok {
+			features = append(features, &ONNXFeature{Attribute: attr.Name()})
+			for _, part := range conditionParts[attr.Name()] {
+				features = append(features, &ONNXFeature{Attribute: attr.Name(), Part: part})
+			}
+			continue
+		}
+		var (
+			values []string
+			seen   = make(map[string]bool)
+		)
+		for _, v := range append(append([]string(nil), nominalAttr.AcceptedValues...), conditionValues[attr.Name()]...) {
+			if !seen[v] {
+				seen[v] = true
+				values = append(values, v)
+			}
+		}
+		if encoding == OneHotEncoding {
+			for _, v := range values {
+				features = append(features, &ONNXFeature{Attribute: attr.Name(), Value: v})
+			}
+		} else {
+			features = append(features, &ONNXFeature{Attribute: attr.Name(), Labels: values})
+		}
+	}
+	return features
+}
+
+// EncodeONNXInput encodes the instance into a row of the input tensor. Missing values, and nominal values that
+// are not in the features, are encoded as NaN in every column of the attribute.
+func EncodeONNXInput(features []*ONNXFeature, instance *data.Instance) []float32 {
+	values := make(map[string]data.Value)
+	for _, value := range instance.AttributeValues {
+		values[value.Attribute().Name()] = value
+	}
+	known := make(map[string]bool) // whether the nominal value of the attribute is in the features
+	for _, feature := range features {
+		if v, ok := values[feature.Attribute]; ok && !v.IsMissing() && !v.Attribute().Type().IsNumeric() {
+			known[feature.Attribute] = known[feature.Attribute] || feature.Value == v.Value() || indexOf(feature.Labels, v.Value().(string)) >= 0
+		}
+	}
+
+	nan := float32(math.NaN())
+	row := make([]float32, len(features))
+	for i, feature := range features {
+		v, ok := values[feature.Attribute]
+		if ok && feature.Part != "" {
+			if dateTimeAttr, isDateTime := v.Attribute().(*data.DateTimeAttribute); isDateTime {
+				v = data.NewDateTimePartAttribute(dateTimeAttr, feature.Part).Derive(v)
+			}
+		}
+		if !ok || v.IsMissing() {
+			row[i] = nan
+			continue
+		}
+		switch value := v.Value().(type) {
+		case float64:
+			row[i] = float32(value)
+		case string:
+			switch {
+			case !known[feature.Attribute]:
+				row[i] = nan
+			case feature.Labels != nil:
+				row[i] = float32(indexOf(feature.Labels, value))
+			case feature.Value == value:
+				row[i] = 1
+			}
+		}
+	}
+	return row
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// WriteONNX writes the tree as an ONNX model with a single ai.onnx.ml TreeEnsembleClassifier node, taking the
+// float tensor "X" [N, features] and producing the class labels "Y" [N] and class probabilities "Z" [N, classes].
+// The input columns are described by ONNXFeatures, and stored as JSON in the "features" metadata of the model.
+//
+// ONNX tree nodes are binary, so the children of a node are checked one by one with BRANCH_LT nodes for
+// continuous conditions, BRANCH_LEQ nodes on the label encoded levels for ordinal conditions, and chains of
+// BRANCH_EQ nodes for nominal conditions (and one-hot encoded ordinal conditions), falling through to the prioritized
+// child. Missing values (NaN) are tracked to the prioritized child as well. A subtree reached by a chain of
+// several BRANCH_EQ nodes is written once for each of them, since ONNX tree nodes cannot be shared.
+// ONNX thresholds are float32, so are the input values: datetime values are the seconds since the Unix epoch, of
+// which float32 only keeps about 2 minutes of precision, and their parts are derived into columns of their own.
+// The labels are the most probable classes, so trees with a decision threshold are not supported.
+func WriteONNX(w io.Writer, tr *tree.Tree, opts *ONNXOptions) error {
+	if tr.IsModelTree() {
+		return errModelTree
+	}
+	if tr.Threshold != nil {
+		return errDecisionThreshold
+	}
+	if opts == nil {
+		opts = &ONNXOptions{}
+	}
+	graphName := opts.GraphName
+	if graphName == "" {
+		graphName = "DecisionTree"
+	}
+	switch opts.Encoding {
+	case "", LabelEncoding, OneHotEncoding:
+	default:
+		return fmt.Errorf("unknown onnx encoding '%s'", opts.Encoding)
+	}
+
+	ensemble := newONNXTreeEnsemble(classOrder(tr), ONNXFeatures(tr, opts))
+	if err := ensemble.addTree(tr); err != nil {
+		return err
+	}
+	featuresJson, err := json.Marshal(ensemble.features)
+	if err != nil {
+		return fmt.Errorf("failed to marshal features: %w", err)
+	}
+
+	graph := appendProtoMessage(nil, onnxGraphNode, ensemble.nodeProto())
+	graph = appendProtoString(graph, onnxGraphName, graphName)
+	graph = appendProtoMessage(graph, onnxGraphInput, onnxTensorValueInfo("X", onnxFloat, "N", len(ensemble.features)))
+	graph = appendProtoMessage(graph, onnxGraphOutput, onnxTensorValueInfo("Y", onnxString, "N"))
+	graph = appendProtoMessage(graph, onnxGraphOutput, onnxTensorValueInfo("Z", onnxFloat, "N", len(ensemble.classes)))
+
+	model := appendProtoInt(nil, onnxModelIrVersion, onnxIrVersion)
+	model = appendProtoString(model, onnxModelProducerName, "DecisionTree")
+	model = appendProtoString(model, onnxModelProducerVersion, "1.0")
+	model = appendProtoString(model, onnxModelDocString, "Input columns are described by the features metadata.")
+	model = appendProtoMessage(model, onnxModelGraph, graph)
+	model = appendProtoMessage(model, onnxModelOpsetImport, onnxOpset("", onnxDefaultOpset))
+	model = appendProtoMessage(model, onnxModelOpsetImport, onnxOpset(onnxMLDomain, onnxMLOpset))
+	model = appendProtoMessage(model, onnxModelMetadataProps, onnxMetadataEntry("features", string(featuresJson)))
+
+	if _, err := w.Write(model); err != nil {
+		return fmt.Errorf("failed to write onnx model: %w", err)
+	}
+	return nil
+}
+
+// onnxTreeEnsemble collects the attributes of a TreeEnsembleClassifier node, trees are added with increasing ids.
+type onnxTreeEnsemble struct {
+	tr       *tree.Tree // the current tree
+	classes  []string
+	features []*ONNXFeature
+	nodes    []*onnxNode
+	leaves   []*onnxLeaf
+	treeId   int64
+	nextId   int64 // next node id in the current tree
+}
+
+type onnxNode struct {
+	treeId            int64
+	nodeId            int64
+	mode              string
+	featureId         int64
+	value             float32
+	trueId            int64
+	falseId           int64
+	missingTracksTrue bool
+}
+
+type onnxLeaf struct {
+	treeId  int64
+	nodeId  int64
+	weights []float32 // weight of each class
+}
+
+func newONNXTreeEnsemble(classes []string, features []*ONNXFeature) *onnxTreeEnsemble {
+	return &onnxTreeEnsemble{classes: classes, features: features, treeId: -1}
+}
+
+func (e *onnxTreeEnsemble) addTree(tr *tree.Tree) error {
+	e.tr = tr
+	e.treeId++
+	e.nextId = 0
+	_, err := e.addSubtree(tr.RootNode)
+	return err
+}
+
+func (e *onnxTreeEnsemble) newNode(mode string, featureId int64, value float32) *onnxNode {
+	n := &onnxNode{treeId: e.treeId, nodeId: e.nextId, mode: mode, featureId: featureId, value: value}
+	e.nextId++
+	e.nodes = append(e.nodes, n)
+	return n
+}
+
+// addSubtree adds the nodes of the subtree and returns the id of its root.
+func (e *onnxTreeEnsemble) addSubtree(node *tree.Node) (int64, error) {
+	if len(node.Children) == 0 {
+		return e.addLeaf(node), nil
+	}
+	var (
+		prioritized *tree.Node
+		others      []*tree.Node
+	)
+	for _, child := range node.Children {
+		if child.IsPrioritized && prioritized == nil {
+			prioritized = child
+		} else {
+			others = append(others, child)
+		}
+	}
+	return e.addChain(node, others, prioritized)
+}
+
+// addLeaf adds a leaf predicting the class distribution of the node, calibrated if the tree has a calibrator.
+func (e *onnxTreeEnsemble) addLeaf(node *tree.Node) int64 {
+	n := e.newNode("LEAF", 0, 0)
+	probabilities := e.tr.LeafProbabilities(node)
+	leaf := &onnxLeaf{treeId: n.treeId, nodeId: n.nodeId}
+	for _, class := range e.classes {
+		leaf.weights = append(leaf.weights, float32(probabilities[class]))
+	}
+	e.leaves = append(e.leaves, leaf)
+	return n.nodeId
+}
+
+// addChain checks the conditions of the children one by one, and falls through to the prioritized child.
+// If there is no prioritized child, the node itself becomes the leaf reached by values meeting no condition.
+func (e *onnxTreeEnsemble) addChain(node *tree.Node, others []*tree.Node, prioritized *tree.Node) (int64, error) {
+	if len(others) == 0 {
+		if prioritized == nil {
+			return e.addLeaf(node), nil
+		}
+		return e.addSubtree(prioritized)
+	}
+	child := others[0]
+	return e.addCondition(child.Condition,
+		func() (int64, error) { return e.addSubtree(child) },
+		func() (int64, error) { return e.addChain(node, others[1:], prioritized) },
+	)
+}
+
+// addCondition adds the nodes checking the condition, missing values go to onFalse.
+func (e *onnxTreeEnsemble) addCondition(condition tree.Condition, onTrue, onFalse func() (int64, error)) (int64, error) {
+	attrName := condition.Attr().Name()
+	labelId := e.featureId(attrName, "")
+	if part, ok := condition.Attr().(*data.DateTimePartAttribute); ok {
+		labelId = e.partFeatureId(part)
+	}
+	switch c := condition.(type) {
+	case *tree.ContinuousCondition:
+		if labelId < 0 {
+			return 0, fmt.Errorf("attribute '%s' is not in the features", attrName)
+		}
+		switch c.Type() {
+		case tree.LessThan:
+			return e.addBranch("BRANCH_LT", labelId, c.UpperValue(), false, onTrue, onFalse)
+		case tree.GreaterThanEq:
+			return e.addBranch("BRANCH_LT", labelId, c.LowerValue(), true, onFalse, onTrue)
+		case tree.Range:
+			return e.addBranch("BRANCH_LT", labelId, c.LowerValue(), true, onFalse, func() (int64, error) {
+				return e.addBranch("BRANCH_LT", labelId, c.UpperValue(), false, onTrue, onFalse)
+			})
+		}
+	case *tree.OrdinalCondition:
+		// the labels start with the levels of the attribute in order, unknown values are encoded as NaN
+		if labelId >= 0 && e.features[labelId].Labels != nil {
+			level := indexOf(e.features[labelId].Labels, c.Level())
+			if level < 0 {
+				return 0, fmt.Errorf("level '%s' of attribute '%s' is not in the features", c.Level(), attrName)
+			}
+			switch c.Type() {
+			case tree.LessEq:
+				return e.addBranch("BRANCH_LEQ", labelId, float64(level), false, onTrue, onFalse)
+			case tree.Greater:
+				return e.addBranch("BRANCH_LEQ", labelId, float64(level), true, onFalse, onTrue)
+			}
+		}
+		return e.addValueSet(c, labelId, onTrue, onFalse)
+	case tree.ValueSetCondition:
+		return e.addValueSet(c, labelId, onTrue, onFalse)
+	}
+	return 0, fmt.Errorf("unsupported condition type '%s'", condition.Type())
+}
+
+// addValueSet adds the nodes checking whether the value is one of the accepted values of the condition.
+func (e *onnxTreeEnsemble) addValueSet(c tree.ValueSetCondition, labelId int64, onTrue, onFalse func() (int64, error)) (int64, error) {
+	attrName := c.Attr().Name()
+	var addValue func(i int) (int64, error)
+	addValue = func(i int) (int64, error) {
+		if i >= len(c.AcceptedValues()) {
+			return onFalse()
+		}
+		var (
+			v    = c.AcceptedValues()[i]
+			next = func() (int64, error) { return addValue(i + 1) }
+		)
+		if labelId >= 0 && e.features[labelId].Labels != nil {
+			return e.addBranch("BRANCH_EQ", labelId, float64(indexOf(e.features[labelId].Labels, v)), false, onTrue, next)
+		}
+		featureId := e.featureId(attrName, v)
+		if featureId < 0 {
+			return 0, fmt.Errorf("value '%s' of attribute '%s' is not in the features", v, attrName)
+		}
+		return e.addBranch("BRANCH_EQ", featureId, 1, false, onTrue, next)
+	}
+	return addValue(0)
+}
+
+func (e *onnxTreeEnsemble) addBranch(mode string, featureId int64, value float64, missingTracksTrue bool,
+	onTrue, onFalse func() (int64, error)) (int64, error) {
+	n := e.newNode(mode, featureId, float32(value))
+	n.missingTracksTrue = missingTracksTrue
+	var err error
+	if n.trueId, err = onTrue(); err != nil {
+		return 0, err
+	}
+	if n.falseId, err = onFalse(); err != nil {
+		return 0, err
+	}
+	return n.nodeId, nil
+}
+
+// partFeatureId returns the column of the part of the datetime attribute, -1 if there is no such column.
+func (e *onnxTreeEnsemble) partFeatureId(part *data.DateTimePartAttribute) int64 {
+	for i, feature := range e.features {
+		if feature.Attribute == part.Parent.Name() && feature.Part == part.Part {
+			return int64(i)
+		}
+	}
+	return -1
+}
+
+// featureId returns the column of the attribute, or of the one-hot encoded value of the attribute if value is not
+// empty.
+// -1 is returned if there is no such column.
+func (e *onnxTreeEnsemble) featureId(attrName, value string) int64 {
+	for i, feature := range e.features {
+		if feature.Attribute == attrName && feature.Value == value && feature.Part == "" {
+			return int64(i)
+		}
+	}
+	return -1
+}
+
+// nodeProto returns the TreeEnsembleClassifier NodeProto.
+func (e *onnxTreeEnsemble) nodeProto() []byte {
+	var (
+		treeIds, nodeIds, featureIds, trueIds, falseIds, missingTracksTrue []int64
+		values                                                             []float32
+		modes                                                              []string
+		classTreeIds, classNodeIds, classIds                               []int64
+		classWeights                                                       []float32
+	)
+	for _, n := range e.nodes {
+		treeIds = append(treeIds, n.treeId)
+		nodeIds = append(nodeIds, n.nodeId)
+		featureIds = append(featureIds, n.featureId)
+		values = append(values, n.value)
+		modes = append(modes, n.mode)
+		trueIds = append(trueIds, n.trueId)
+		falseIds = append(falseIds, n.falseId)
+		if n.missingTracksTrue {
+			missingTracksTrue = append(missingTracksTrue, 1)
+		} else {
+			missingTracksTrue = append(missingTracksTrue, 0)
+		}
+	}
+	for _, leaf := range e.leaves {
+		for i, weight := range leaf.weights {
+			classTreeIds = append(classTreeIds, leaf.treeId)
+			classNodeIds = append(classNodeIds, leaf.nodeId)
+			classIds = append(classIds, int64(i))
+			classWeights = append(classWeights, weight)
+		}
+	}
+
+	b := appendProtoString(nil, onnxNodeInput, "X")
+	b = appendProtoString(b, onnxNodeOutput, "Y")
+	b = appendProtoString(b, onnxNodeOutput, "Z")
+	b = appendProtoString(b, onnxNodeName, "TreeEnsembleClassifier")
+	b = appendProtoString(b, onnxNodeOpType, "TreeEnsembleClassifier")
+	b = appendProtoString(b, onnxNodeDomain, onnxMLDomain)
+	for _, attr := range [][]byte{
+		onnxStringsAttribute("classlabels_strings", e.classes),
+		onnxIntsAttribute("nodes_treeids", treeIds),
+		onnxIntsAttribute("nodes_nodeids", nodeIds),
+		onnxIntsAttribute("nodes_featureids", featureIds),
+		onnxFloatsAttribute("nodes_values", values),
+		onnxStringsAttribute("nodes_modes", modes),
+		onnxIntsAttribute("nodes_truenodeids", trueIds),
+		onnxIntsAttribute("nodes_falsenodeids", falseIds),
+		onnxIntsAttribute("nodes_missing_value_tracks_true", missingTracksTrue),
+		onnxIntsAttribute("class_treeids", classTreeIds),
+		onnxIntsAttribute("class_nodeids", classNodeIds),
+		onnxIntsAttribute("class_ids", classIds),
+		onnxFloatsAttribute("class_weights", classWeights),
+		onnxStringAttribute("post_transform", "NONE"),
+	} {
+		b = appendProtoMessage(b, onnxNodeAttribute, attr)
+	}
+	return b
+}
@@ -0,0 +1,158 @@
+package export
+
+import (
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the ONNX protobuf messages written by WriteONNX, see onnx/onnx.proto.
+const (
+	onnxModelIrVersion       protowire.Number = 1
+	onnxModelProducerName    protowire.Number = 2
+	onnxModelProducerVersion protowire.Number = 3
+	onnxModelDocString       protowire.Number = 6
+	onnxModelGraph           protowire.Number = 7
+	onnxModelOpsetImport     protowire.Number = 8
+	onnxModelMetadataProps   protowire.Number = 14
+
+	onnxOpsetDomain  protowire.Number = 1
+	onnxOpsetVersion protowire.Number = 2
+
+	onnxEntryKey   protowire.Number = 1
+	onnxEntryValue protowire.Number = 2
+
+	onnxGraphNode   protowire.Number = 1
+	onnxGraphName   protowire.Number = 2
+	onnxGraphInput  protowire.Number = 11
+	onnxGraphOutput protowire.Number = 12
+
+	onnxNodeInput     protowire.Number = 1
+	onnxNodeOutput    protowire.Number = 2
+	onnxNodeName      protowire.Number = 3
+	onnxNodeOpType    protowire.Number = 4
+	onnxNodeAttribute protowire.Number = 5
+	onnxNodeDomain    protowire.Number = 7
+
+	onnxAttributeName    protowire.Number = 1
+	onnxAttributeS       protowire.Number = 4
+	onnxAttributeFloats  protowire.Number = 7
+	onnxAttributeInts    protowire.Number = 8
+	onnxAttributeStrings protowire.Number = 9
+	onnxAttributeType    protowire.Number = 20
+
+	onnxValueInfoName protowire.Number = 1
+	onnxValueInfoType protowire.Number = 2
+
+	onnxTypeTensorType protowire.Number = 1
+	onnxTensorElemType protowire.Number = 1
+	onnxTensorShape    protowire.Number = 2
+	onnxShapeDim       protowire.Number = 1
+	onnxDimensionValue protowire.Number = 1
+	onnxDimensionParam protowire.Number = 2
+)
+
+const (
+	onnxIrVersion    = 8
+	onnxDefaultOpset = 17
+	onnxMLDomain     = "ai.onnx.ml"
+	onnxMLOpset      = 3
+)
+
+// AttributeProto.AttributeType
+const (
+	onnxAttributeTypeString  = 3
+	onnxAttributeTypeFloats  = 6
+	onnxAttributeTypeInts    = 7
+	onnxAttributeTypeStrings = 8
+)
+
+// TensorProto.DataType
+const (
+	onnxFloat  = 1
+	onnxString = 8
+)
+
+func appendProtoString(b []byte, num protowire.Number, s string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendProtoInt(b []byte, num protowire.Number, v int64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+func appendProtoFloat(b []byte, num protowire.Number, v float32) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed32Type)
+	return protowire.AppendFixed32(b, math.Float32bits(v))
+}
+
+func appendProtoMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+// ONNX is proto2, repeated numeric fields are written unpacked as ONNX itself does.
+
+func onnxStringAttribute(name, value string) []byte {
+	b := appendProtoString(nil, onnxAttributeName, name)
+	b = appendProtoString(b, onnxAttributeS, value)
+	return appendProtoInt(b, onnxAttributeType, onnxAttributeTypeString)
+}
+
+func onnxIntsAttribute(name string, values []int64) []byte {
+	b := appendProtoString(nil, onnxAttributeName, name)
+	for _, v := range values {
+		b = appendProtoInt(b, onnxAttributeInts, v)
+	}
+	return appendProtoInt(b, onnxAttributeType, onnxAttributeTypeInts)
+}
+
+func onnxFloatsAttribute(name string, values []float32) []byte {
+	b := appendProtoString(nil, onnxAttributeName, name)
+	for _, v := range values {
+		b = appendProtoFloat(b, onnxAttributeFloats, v)
+	}
+	return appendProtoInt(b, onnxAttributeType, onnxAttributeTypeFloats)
+}
+
+func onnxStringsAttribute(name string, values []string) []byte {
+	b := appendProtoString(nil, onnxAttributeName, name)
+	for _, v := range values {
+		b = appendProtoString(b, onnxAttributeStrings, v)
+	}
+	return appendProtoInt(b, onnxAttributeType, onnxAttributeTypeStrings)
+}
+
+// onnxTensorValueInfo describes a tensor input or output, a dimension is either an int64 size or a string
+// parameter such as "N".
+func onnxTensorValueInfo(name string, elemType int64, dims ...interface{}) []byte {
+	var shape []byte
+	for _, dim := range dims {
+		var d []byte
+		switch v := dim.(type) {
+		case int:
+			d = appendProtoInt(nil, onnxDimensionValue, int64(v))
+		case string:
+			d = appendProtoString(nil, onnxDimensionParam, v)
+		}
+		shape = appendProtoMessage(shape, onnxShapeDim, d)
+	}
+	tensorType := appendProtoInt(nil, onnxTensorElemType, elemType)
+	tensorType = appendProtoMessage(tensorType, onnxTensorShape, shape)
+	typeProto := appendProtoMessage(nil, onnxTypeTensorType, tensorType)
+
+	b := appendProtoString(nil, onnxValueInfoName, name)
+	return appendProtoMessage(b, onnxValueInfoType, typeProto)
+}
+
+func onnxOpset(domain string, version int64) []byte {
+	b := appendProtoString(nil, onnxOpsetDomain, domain)
+	return appendProtoInt(b, onnxOpsetVersion, version)
+}
+
+func onnxMetadataEntry(key, value string) []byte {
+	b := appendProtoString(nil, onnxEntryKey, key)
+	return appendProtoString(b, onnxEntryValue, value)
+}
@@ -0,0 +1,302 @@
+package export
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protoFields decodes a protobuf message into its fields, a field is a varint, a fixed32 or bytes.
+func protoFields(t *testing.T, b []byte) map[protowire.Number][]interface{} {
+	res := make(map[protowire.Number][]interface{})
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("failed to decode tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		var v interface{}
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var bits uint32
+			bits, n = protowire.ConsumeFixed32(b)
+			v = math.Float32frombits(bits)
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		default:
+			t.Fatalf("unexpected wire type %d of field %d", typ, num)
+		}
+		if n < 0 {
+			t.Fatalf("failed to decode field %d: %v", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+		res[num] = append(res[num], v)
+	}
+	return res
+}
+
+func protoString(v interface{}) string {
+	return string(v.([]byte))
+}
+
+// onnxEnsemble holds the decoded attributes of a TreeEnsembleClassifier node.
+type onnxEnsemble struct {
+	ints    map[string][]int64
+	floats  map[string][]float32
+	strings map[string][]string
+}
+
+type decodedONNXModel struct {
+	fields   map[protowire.Number][]interface{}
+	graph    map[protowire.Number][]interface{}
+	node     map[protowire.Number][]interface{}
+	ensemble *onnxEnsemble
+	features []*ONNXFeature
+}
+
+func decodeONNXModel(t *testing.T, model []byte) *decodedONNXModel {
+	res := &decodedONNXModel{fields: protoFields(t, model)}
+	res.graph = protoFields(t, res.fields[onnxModelGraph][0].([]byte))
+	assert.Len(t, res.graph[onnxGraphNode], 1)
+	res.node = protoFields(t, res.graph[onnxGraphNode][0].([]byte))
+
+	res.ensemble = &onnxEnsemble{
+		ints:    make(map[string][]int64),
+		floats:  make(map[string][]float32),
+		strings: make(map[string][]string),
+	}
+	for _, attr := range res.node[onnxNodeAttribute] {
+		fields := protoFields(t, attr.([]byte))
+		name := protoString(fields[onnxAttributeName][0])
+		switch fields[onnxAttributeType][0].(uint64) {
+		case onnxAttributeTypeInts:
+			for _, v := range fields[onnxAttributeInts] {
+				res.ensemble.ints[name] = append(res.ensemble.ints[name], int64(v.(uint64)))
+			}
+		case onnxAttributeTypeFloats:
+			for _, v := range fields[onnxAttributeFloats] {
+				res.ensemble.floats[name] = append(res.ensemble.floats[name], v.(float32))
+			}
+		case onnxAttributeTypeStrings:
+			for _, v := range fields[onnxAttributeStrings] {
+				res.ensemble.strings[name] = append(res.ensemble.strings[name], protoString(v))
+			}
+		case onnxAttributeTypeString:
+			res.ensemble.strings[name] = []string{protoString(fields[onnxAttributeS][0])}
+		}
+	}
+
+	for _, entry := range res.fields[onnxModelMetadataProps] {
+		fields := protoFields(t, entry.([]byte))
+		if protoString(fields[onnxEntryKey][0]) == "features" {
+			assert.NoError(t, json.Unmarshal(fields[onnxEntryValue][0].([]byte), &res.features))
+		}
+	}
+	return res
+}
+
+// predict evaluates the tree ensemble as an ONNX runtime does, and returns the class weights.
+func (e *onnxEnsemble) predict(t *testing.T, row []float32) []float32 {
+	index := make(map[int64]int)
+	for i, id := range e.ints["nodes_nodeids"] {
+		index[id] = i
+	}
+	i := index[0]
+	for steps := 0; e.strings["nodes_modes"][i] != "LEAF"; steps++ {
+		if steps > len(index) {
+			t.Fatalf("tree has a cycle")
+		}
+		var (
+			v         = row[e.ints["nodes_featureids"][i]]
+			threshold = e.floats["nodes_values"][i]
+			met       bool
+		)
+		switch {
+		case math.IsNaN(float64(v)):
+			met = e.ints["nodes_missing_value_tracks_true"][i] == 1
+		case e.strings["nodes_modes"][i] == "BRANCH_LT":
+			met = v < threshold
+		case e.strings["nodes_modes"][i] == "BRANCH_LEQ":
+			met = v <= threshold
+		case e.strings["nodes_modes"][i] == "BRANCH_EQ":
+			met = v == threshold
+		default:
+			t.Fatalf("unexpected node mode %s", e.strings["nodes_modes"][i])
+		}
+		if met {
+			i = index[e.ints["nodes_truenodeids"][i]]
+		} else {
+			i = index[e.ints["nodes_falsenodeids"][i]]
+		}
+	}
+
+	weights := make([]float32, len(e.strings["classlabels_strings"]))
+	for j, nodeId := range e.ints["class_nodeids"] {
+		if nodeId == e.ints["nodes_nodeids"][i] {
+			weights[e.ints["class_ids"][j]] += e.floats["class_weights"][j]
+		}
+	}
+	return weights
+}
+
+func checkONNXModel(t *testing.T, tr *tree.Tree, opts *ONNXOptions, rows []map[string]string) *decodedONNXModel {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteONNX(&buf, tr, opts))
+	model := decodeONNXModel(t, buf.Bytes())
+	assert.Equal(t, ONNXFeatures(tr, opts), model.features)
+
+	// every array of nodes has the same length, and every node but the root is referenced exactly once
+	e := model.ensemble
+	nodeCount := len(e.ints["nodes_nodeids"])
+	for _, name := range []string{"nodes_treeids", "nodes_featureids", "nodes_truenodeids", "nodes_falsenodeids", "nodes_missing_value_tracks_true"} {
+		assert.Len(t, e.ints[name], nodeCount, name)
+	}
+	assert.Len(t, e.floats["nodes_values"], nodeCount)
+	assert.Len(t, e.strings["nodes_modes"], nodeCount)
+	references := make(map[int64]int)
+	for i, mode := range e.strings["nodes_modes"] {
+		if mode != "LEAF" {
+			references[e.ints["nodes_truenodeids"][i]]++
+			references[e.ints["nodes_falsenodeids"][i]]++
+		}
+	}
+	for _, id := range e.ints["nodes_nodeids"] {
+		if id != 0 {
+			assert.Equal(t, 1, references[id], "references of node %d", id)
+		}
+	}
+
+	classes := e.strings["classlabels_strings"]
+	for _, row := range rows {
+		instance := newTestInstance(t, tr, row)
+		weights := e.predict(t, EncodeONNXInput(model.features, instance))
+		expected, err := tr.PredictProba(instance)
+		assert.NoError(t, err)
+		for i, class := range classes {
+			assert.InDelta(t, expected[class], weights[i], 1e-6, "probability of %s for row %v", class, row)
+		}
+	}
+	return model
+}
+
+var onnxTestRows = []map[string]string{
+	{"age": "20", "color": "red"},
+	{"age": "20", "color": "green"},
+	{"age": "20", "color": `"blue"`},
+	{"age": "20", "color": "purple"}, // not accepted by any condition
+	{"age": "20", "color": "pink"},   // unseen value, missing
+	{"age": "20"},
+	{"age": "45", "color": "red"},
+	{"color": "red"},
+	{},
+}
+
+func TestWriteONNX(t *testing.T) {
+	tr := newTestTree()
+	color := tr.Attributes[1].(*data.NominalAttribute)
+	color.AcceptedValues = append(color.AcceptedValues, "purple")
+
+	model := checkONNXModel(t, tr, nil, onnxTestRows)
+	assert.Equal(t, uint64(onnxIrVersion), model.fields[onnxModelIrVersion][0])
+	var opsets []string
+	for _, opset := range model.fields[onnxModelOpsetImport] {
+		fields := protoFields(t, opset.([]byte))
+		opsets = append(opsets, protoString(fields[onnxOpsetDomain][0]))
+	}
+	assert.Contains(t, opsets, "ai.onnx.ml")
+	assert.Equal(t, "TreeEnsembleClassifier", protoString(model.node[onnxNodeOpType][0]))
+	assert.Equal(t, "ai.onnx.ml", protoString(model.node[onnxNodeDomain][0]))
+	assert.Equal(t, []string{"yes", "no"}, model.ensemble.strings["classlabels_strings"])
+	assert.Len(t, model.graph[onnxGraphInput], 1)
+	assert.Len(t, model.graph[onnxGraphOutput], 2)
+
+	// age >= 30 is checked as not age < 30, so missing ages are tracked to the true branch, the prioritized child
+	assert.Equal(t, []string{"BRANCH_LT", "BRANCH_EQ", "LEAF", "LEAF", "LEAF"}, model.ensemble.strings["nodes_modes"])
+	assert.Equal(t, []int64{1, 0, 0, 0, 0}, model.ensemble.ints["nodes_missing_value_tracks_true"])
+	assert.Equal(t, []*ONNXFeature{
+		{Attribute: "age"},
+		{Attribute: "color", Labels: []string{"red", "green", `"blue"`, "purple"}},
+	}, model.features)
+}
+
+func TestWriteONNXOneHot(t *testing.T) {
+	tr := newTestTree()
+	model := checkONNXModel(t, tr, &ONNXOptions{Encoding: OneHotEncoding}, onnxTestRows)
+	assert.Len(t, model.features, 4)
+	assert.Equal(t, "green", model.features[2].Value)
+
+	// nominal values are one-hot encoded, unseen values are missing
+	row := EncodeONNXInput(model.features, newTestInstance(t, tr, map[string]string{"age": "3", "color": "green"}))
+	assert.Equal(t, []float32{3, 0, 1, 0}, row)
+	row = EncodeONNXInput(model.features, newTestInstance(t, tr, map[string]string{"color": "pink"}))
+	for _, v := range row {
+		assert.True(t, math.IsNaN(float64(v)))
+	}
+
+	assert.Error(t, WriteONNX(&bytes.Buffer{}, tr, &ONNXOptions{Encoding: "binary"}))
+}
+
+func TestWriteONNXDuplicatesSubtrees(t *testing.T) {
+	var (
+		age       = data.NewContinuousAttribute("age")
+		color     = data.NewNominalAttribute("color", []string{"red", "green", "blue"})
+		classAttr = data.NewNominalAttribute("Class", []string{"yes", "no"})
+	)
+	// color in ['red', 'green'] -> 20 <= age < 40 -> yes, age < 20 (prioritized) -> no
+	// color in ['blue'] (prioritized) -> no
+	tr := &tree.Tree{
+		Attributes: []data.Attribute{age, color},
+		Class:      classAttr,
+		RootNode: &tree.Node{Children: []*tree.Node{
+			{Condition: tree.NewIsOneOfCondition(color, []string{"red", "green"}), Children: []*tree.Node{
+				{Condition: tree.NewRangeCondition(age, 20, 40), LeafClass: "yes"},
+				{Condition: tree.NewLessThanCondition(age, 20), LeafClass: "no", IsPrioritized: true},
+				{Condition: tree.NewGreaterThanEqCondition(age, 40), LeafClass: "yes"},
+			}},
+			{Condition: tree.NewIsOneOfCondition(color, []string{"blue"}), LeafClass: "no", IsPrioritized: true},
+		}},
+	}
+	var rows []map[string]string
+	for _, c := range []string{"red", "green", "blue", "pink", ""} {
+		for _, a := range []string{"10", "20", "30", "40", "50", ""} {
+			row := map[string]string{}
+			if c != "" {
+				row["color"] = c
+			}
+			if a != "" {
+				row["age"] = a
+			}
+			rows = append(rows, row)
+		}
+	}
+	model := checkONNXModel(t, tr, nil, rows)
+
+	// the subtree of color in ['red', 'green'] is written for both values, in which the checks falling through to
+	// the prioritized child are written for both bounds of the range: 1 + 2 * 2 leaves
+	modes := model.ensemble.strings["nodes_modes"]
+	count := make(map[string]int)
+	for _, mode := range modes {
+		count[mode]++
+	}
+	assert.Equal(t, 2, count["BRANCH_EQ"])
+	assert.Equal(t, 2*5+1, count["LEAF"])
+}
+
+func TestWriteONNXCalibrated(t *testing.T) {
+	tr := newTestTree()
+	calibrator, err := tree.FitCalibrator(tr, nil, &tree.CalibrationOptions{Method: tree.LaplaceCalibration})
+	assert.NoError(t, err)
+	tr.Calibrator = calibrator
+
+	// the leaf {no: 3} predicts (3 + 1) / (3 + 2) instead of 1
+	model := checkONNXModel(t, tr, nil, onnxTestRows)
+	assert.Contains(t, model.ensemble.floats["class_weights"], float32(0.8))
+}
@@ -0,0 +1,605 @@
+package export
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const pmmlNamespace = "http://www.dmg.org/PMML-4_4"
+
+// PMMLOptions controls the generated PMML document.
+type PMMLOptions struct {
+	ModelName string // default "DecisionTree"
+}
+
+type pmmlDocument struct {
+	XMLName        xml.Name            `xml:"PMML"`
+	Xmlns          string              `xml:"xmlns,attr,omitempty"`
+	Version        string              `xml:"version,attr"`
+	Header         *pmmlHeader         `xml:"Header"`
+	DataDictionary *pmmlDataDictionary `xml:"DataDictionary"`
+	TreeModel      *pmmlTreeModel      `xml:"TreeModel"`
+}
+
+type pmmlHeader struct {
+	Application *pmmlApplication `xml:"Application"`
+}
+
+type pmmlApplication struct {
+	Name string `xml:"name,attr"`
+}
+
+type pmmlDataDictionary struct {
+	NumberOfFields int              `xml:"numberOfFields,attr"`
+	DataFields     []*pmmlDataField `xml:"DataField"`
+}
+
+type pmmlDataField struct {
+	Name     string       `xml:"name,attr"`
+	OpType   string       `xml:"optype,attr"`
+	DataType string       `xml:"dataType,attr"`
+	Values   []*pmmlValue `xml:"Value"`
+}
+
+type pmmlValue struct {
+	Value string `xml:"value,attr"`
+}
+
+type pmmlTreeModel struct {
+	ModelName            string            `xml:"modelName,attr,omitempty"`
+	FunctionName         string            `xml:"functionName,attr"`
+	MissingValueStrategy string            `xml:"missingValueStrategy,attr,omitempty"`
+	NoTrueChildStrategy  string            `xml:"noTrueChildStrategy,attr,omitempty"`
+	SplitCharacteristic  string            `xml:"splitCharacteristic,attr,omitempty"`
+	MiningSchema         *pmmlMiningSchema `xml:"MiningSchema"`
+	Node                 *pmmlNode         `xml:"Node"`
+}
+
+type pmmlMiningSchema struct {
+	MiningFields []*pmmlMiningField `xml:"MiningField"`
+}
+
+type pmmlMiningField struct {
+	Name      string `xml:"name,attr"`
+	UsageType string `xml:"usageType,attr,omitempty"` // "active" if empty
+}
+
+type pmmlNode struct {
+	Id                 string                   `xml:"id,attr,omitempty"`
+	Score              string                   `xml:"score,attr,omitempty"`
+	RecordCount        float64                  `xml:"recordCount,attr,omitempty"`
+	DefaultChild       string                   `xml:"defaultChild,attr,omitempty"`
+	True               *struct{}                `xml:"True"`
+	SimplePredicate    *pmmlSimplePredicate     `xml:"SimplePredicate"`
+	SimpleSetPredicate *pmmlSimpleSetPredicate  `xml:"SimpleSetPredicate"`
+	CompoundPredicate  *pmmlCompoundPredicate   `xml:"CompoundPredicate"`
+	ScoreDistributions []*pmmlScoreDistribution `xml:"ScoreDistribution"`
+	Nodes              []*pmmlNode              `xml:"Node"`
+}
+
+type pmmlSimplePredicate struct {
+	Field    string `xml:"field,attr"`
+	Operator string `xml:"operator,attr"`
+	Value    string `xml:"value,attr,omitempty"`
+}
+
+type pmmlSimpleSetPredicate struct {
+	Field           string     `xml:"field,attr"`
+	BooleanOperator string     `xml:"booleanOperator,attr"`
+	Array           *pmmlArray `xml:"Array"`
+}
+
+type pmmlArray struct {
+	N     int    `xml:"n,attr"`
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type pmmlCompoundPredicate struct {
+	BooleanOperator  string                 `xml:"booleanOperator,attr"`
+	SimplePredicates []*pmmlSimplePredicate `xml:"SimplePredicate"`
+}
+
+type pmmlScoreDistribution struct {
+	Value       string  `xml:"value,attr"`
+	RecordCount float64 `xml:"recordCount,attr"`
+	Probability float64 `xml:"probability,attr"`
+}
+
+// WritePMML writes the tree as a PMML 4.4 TreeModel document.
+// The prioritized child of each node is written as the defaultChild, which PMML follows when the value of the
+// split attribute is missing, and its nominal condition is extended with the accepted values of the attribute
+// that no sibling accepts, so that PMML consumers route values met by no condition the same way as the tree.
+// Datetime attributes are dateTime fields in UTC, the splits by their parts are not supported.
+func WritePMML(w io.Writer, tr *tree.Tree, opts *PMMLOptions) error {
+	if tr.IsModelTree() {
+		return errModelTree
+	}
+	if opts == nil {
+		opts = &PMMLOptions{}
+	}
+	modelName := opts.ModelName
+	if modelName == "" {
+		modelName = "DecisionTree"
+	}
+
+	classes := classOrder(tr)
+	className := "Class"
+	if tr.Class != nil {
+		className = tr.Class.Name()
+	}
+
+	dictionary := &pmmlDataDictionary{}
+	schema := &pmmlMiningSchema{}
+	for _, attr := range tr.Attributes {
+		field := &pmmlDataField{Name: attr.Name()}
+		switch a := attr.(type) {
+		case *data.ContinuousAttribute:
+			field.OpType, field.DataType = "continuous", "double"
+		case *data.DateTimeAttribute:
+			field.OpType, field.DataType = "continuous", "dateTime"
+		case *data.NominalAttribute:
+			field.OpType, field.DataType = "categorical", "string"
+			if a.Type() == data.Ordinal {
+				field.OpType = "ordinal"
+			}
+			for _, v := range a.AcceptedValues {
+				field.Values = append(field.Values, &pmmlValue{Value: v})
+			}
+		default:
+			return fmt.Errorf("unsupported attribute type '%s' of attribute '%s'", attr.Type(), attr.Name())
+		}
+		dictionary.DataFields = append(dictionary.DataFields, field)
+		schema.MiningFields = append(schema.MiningFields, &pmmlMiningField{Name: attr.Name(), UsageType: "active"})
+	}
+	classField := &pmmlDataField{Name: className, OpType: "categorical", DataType: "string"}
+	for _, class := range classes {
+		classField.Values = append(classField.Values, &pmmlValue{Value: class})
+	}
+	dictionary.DataFields = append(dictionary.DataFields, classField)
+	dictionary.NumberOfFields = len(dictionary.DataFields)
+	schema.MiningFields = append(schema.MiningFields, &pmmlMiningField{Name: className, UsageType: "target"})
+
+	root, err := newPMMLNode(tr, classes, tr.RootNode, nil)
+	if err != nil {
+		return err
+	}
+	doc := &pmmlDocument{
+		Xmlns:          pmmlNamespace,
+		Version:        "4.4",
+		Header:         &pmmlHeader{Application: &pmmlApplication{Name: "DecisionTree"}},
+		DataDictionary: dictionary,
+		TreeModel: &pmmlTreeModel{
+			ModelName:            modelName,
+			FunctionName:         "classification",
+			MissingValueStrategy: "defaultChild",
+			NoTrueChildStrategy:  "returnLastPrediction",
+			SplitCharacteristic:  "multiSplit",
+			MiningSchema:         schema,
+			Node:                 root,
+		},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write pmml: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write pmml: %w", err)
+	}
+	_, _ = io.WriteString(w, "\n")
+	return nil
+}
+
+// newPMMLNode converts the node, siblings are the children of its parent including the node itself.
+// Leaves score their prediction by the decision threshold of the tree, with the calibrated probabilities if the tree
+// has a calibrator.
+func newPMMLNode(tr *tree.Tree, classes []string, node *tree.Node, siblings []*tree.Node) (*pmmlNode, error) {
+	res := &pmmlNode{
+		Id:          strconv.Itoa(node.UniqId()),
+		Score:       majorityClass(classes, node),
+		RecordCount: node.GetSampleCount(),
+	}
+	if err := setPMMLPredicate(res, node, siblings); err != nil {
+		return nil, err
+	}
+	if total := node.GetSampleCount(); total > 0 {
+		var probabilities map[string]float64
+		if len(node.Children) == 0 {
+			res.Score = tr.LeafPrediction(node)
+			probabilities = tr.LeafProbabilities(node)
+		}
+		for _, class := range classes {
+			probability := node.ClassCount[class] / total
+			if probabilities != nil {
+				probability = probabilities[class]
+			}
+			res.ScoreDistributions = append(res.ScoreDistributions, &pmmlScoreDistribution{
+				Value:       class,
+				RecordCount: node.ClassCount[class],
+				Probability: probability,
+			})
+		}
+	}
+
+	for _, child := range node.Children {
+		pmmlChild, err := newPMMLNode(tr, classes, child, node.Children)
+		if err != nil {
+			return nil, err
+		}
+		if child.IsPrioritized && res.DefaultChild == "" {
+			res.DefaultChild = pmmlChild.Id
+		}
+		res.Nodes = append(res.Nodes, pmmlChild)
+	}
+	return res, nil
+}
+
+func setPMMLPredicate(res *pmmlNode, node *tree.Node, siblings []*tree.Node) error {
+	if node.Condition == nil {
+		res.True = &struct{}{}
+		return nil
+	}
+	field := node.Condition.Attr().Name()
+	switch c := node.Condition.(type) {
+	case *tree.ContinuousCondition:
+		if _, ok := c.Attr().(*data.DateTimePartAttribute); ok {
+			return fmt.Errorf("unsupported condition on the datetime part '%s'", field)
+		}
+		switch c.Type() {
+		case tree.LessThan:
+			res.SimplePredicate = &pmmlSimplePredicate{Field: field, Operator: "lessThan", Value: pmmlThreshold(c.Attr(), c.UpperValue())}
+		case tree.GreaterThanEq:
+			res.SimplePredicate = &pmmlSimplePredicate{Field: field, Operator: "greaterOrEqual", Value: pmmlThreshold(c.Attr(), c.LowerValue())}
+		case tree.Range:
+			res.CompoundPredicate = &pmmlCompoundPredicate{BooleanOperator: "and", SimplePredicates: []*pmmlSimplePredicate{
+				{Field: field, Operator: "greaterOrEqual", Value: pmmlThreshold(c.Attr(), c.LowerValue())},
+				{Field: field, Operator: "lessThan", Value: pmmlThreshold(c.Attr(), c.UpperValue())},
+			}}
+		default:
+			return fmt.Errorf("unsupported condition type '%s'", c.Type())
+		}
+	case *tree.OrdinalCondition:
+		// ordinal fields are compared in the order of the values of their DataField
+		switch c.Type() {
+		case tree.LessEq:
+			res.SimplePredicate = &pmmlSimplePredicate{Field: field, Operator: "lessOrEqual", Value: c.Level()}
+		case tree.Greater:
+			res.SimplePredicate = &pmmlSimplePredicate{Field: field, Operator: "greaterThan", Value: c.Level()}
+		default:
+			return fmt.Errorf("unsupported condition type '%s'", c.Type())
+		}
+	case tree.ValueSetCondition:
+		values := append([]string(nil), c.AcceptedValues()...)
+		if node.IsPrioritized {
+			values = append(values, uncoveredValues(c.Attr(), siblings)...)
+		}
+		if len(values) == 1 {
+			res.SimplePredicate = &pmmlSimplePredicate{Field: field, Operator: "equal", Value: values[0]}
+		} else {
+			res.SimpleSetPredicate = &pmmlSimpleSetPredicate{
+				Field:           field,
+				BooleanOperator: "isIn",
+				Array:           &pmmlArray{N: len(values), Type: "string", Value: formatPMMLArray(values)},
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported condition type '%s'", node.Condition.Type())
+	}
+	return nil
+}
+
+// uncoveredValues returns the accepted values of the nominal attribute that none of the nodes accept.
+func uncoveredValues(attr data.Attribute, nodes []*tree.Node) []string {
+	nominalAttr, ok := attr.(*data.NominalAttribute)
+	if !ok {
+		return nil
+	}
+	covered := make(map[string]bool)
+	for _, node := range nodes {
+		if c, ok := node.Condition.(tree.ValueSetCondition); ok {
+			for _, v := range c.AcceptedValues() {
+				covered[v] = true
+			}
+		}
+	}
+	var res []string
+	for _, v := range nominalAttr.AcceptedValues {
+		if !covered[v] {
+			covered[v] = true
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// pmmlDateTimeLayout is the layout of the values of dateTime fields, without a time zone as they are in UTC.
+const pmmlDateTimeLayout = "2006-01-02T15:04:05.999999999"
+
+// pmmlThreshold returns the value of the threshold of the attribute, a dateTime value of datetime attributes.
+func pmmlThreshold(attr data.Attribute, v float64) string {
+	if dateTimeAttr, ok := attr.(*data.DateTimeAttribute); ok {
+		return dateTimeAttr.Time(v).Format(pmmlDateTimeLayout)
+	}
+	return pmmlFloat(v)
+}
+
+func pmmlFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// formatPMMLArray formats the values as the content of a PMML string Array, values are double-quoted and the
+// quotes and backslashes in them are escaped with a backslash.
+func formatPMMLArray(values []string) string {
+	var quoted []string
+	for _, v := range values {
+		v = strings.ReplaceAll(v, `\`, `\\`)
+		v = strings.ReplaceAll(v, `"`, `\"`)
+		quoted = append(quoted, `"`+v+`"`)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// parsePMMLArray parses the content of a PMML string Array, values are separated by whitespaces and might be
+// double-quoted.
+func parsePMMLArray(content string) ([]string, error) {
+	var (
+		res   []string
+		runes = []rune(content)
+	)
+	for i := 0; i < len(runes); {
+		switch {
+		case runes[i] == ' ' || runes[i] == '\t' || runes[i] == '\n' || runes[i] == '\r':
+			i++
+		case runes[i] == '"':
+			var sb strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated quoted value in array '%s'", content)
+			}
+			i++
+			res = append(res, sb.String())
+		default:
+			start := i
+			for i < len(runes) && !strings.ContainsRune(" \t\n\r", runes[i]) {
+				i++
+			}
+			res = append(res, string(runes[start:i]))
+		}
+	}
+	return res, nil
+}
+
+// ReadPMML reads a PMML TreeModel document as a tree.
+// Only the predicates written by WritePMML are supported: True, lessThan, greaterOrEqual, equal, isIn and the
+// conjunction of a greaterOrEqual and a lessThan predicate. The defaultChild of a node becomes its prioritized child.
+func ReadPMML(r io.Reader) (*tree.Tree, error) {
+	var doc pmmlDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal PMML: %w", err)
+	}
+	if doc.DataDictionary == nil || doc.TreeModel == nil || doc.TreeModel.Node == nil {
+		return nil, fmt.Errorf("PMML document does not contain a DataDictionary and a TreeModel")
+	}
+	if doc.TreeModel.FunctionName != "classification" {
+		return nil, fmt.Errorf("unsupported TreeModel function '%s'", doc.TreeModel.FunctionName)
+	}
+
+	fields := make(map[string]*pmmlDataField)
+	for _, field := range doc.DataDictionary.DataFields {
+		fields[field.Name] = field
+	}
+	var (
+		tr         = &tree.Tree{}
+		attributes = make(map[string]data.Attribute)
+	)
+	if doc.TreeModel.MiningSchema != nil {
+		for _, miningField := range doc.TreeModel.MiningSchema.MiningFields {
+			field, ok := fields[miningField.Name]
+			if !ok {
+				return nil, fmt.Errorf("mining field '%s' is not in the data dictionary", miningField.Name)
+			}
+			var values []string
+			for _, v := range field.Values {
+				values = append(values, v.Value)
+			}
+			switch miningField.UsageType {
+			case "", "active":
+				var attr data.Attribute
+				switch field.OpType {
+				case "continuous":
+					if field.DataType == "dateTime" {
+						attr = data.NewDateTimeAttribute(field.Name, []string{pmmlDateTimeLayout})
+					} else {
+						attr = data.NewContinuousAttribute(field.Name)
+					}
+				case "categorical":
+					attr = data.NewNominalAttribute(field.Name, values)
+				case "ordinal":
+					attr = data.NewOrdinalAttribute(field.Name, values)
+				default:
+					return nil, fmt.Errorf("unsupported optype '%s' of field '%s'", field.OpType, field.Name)
+				}
+				tr.Attributes = append(tr.Attributes, attr)
+				attributes[field.Name] = attr
+			case "target", "predicted":
+				tr.Class = data.NewNominalAttribute(field.Name, values)
+			}
+		}
+	}
+
+	root, err := newTreeNodeFromPMML(attributes, doc.TreeModel.Node)
+	if err != nil {
+		return nil, err
+	}
+	if root.Condition != nil {
+		return nil, fmt.Errorf("the predicate of the root node must be True")
+	}
+	tr.RootNode = root
+	return tr, nil
+}
+
+func newTreeNodeFromPMML(attributes map[string]data.Attribute, pNode *pmmlNode) (*tree.Node, error) {
+	condition, err := pmmlNodeCondition(attributes, pNode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read predicate of node '%s': %w", pNode.Id, err)
+	}
+	node := &tree.Node{Condition: condition}
+	if len(pNode.ScoreDistributions) > 0 {
+		node.ClassCount = make(map[string]float64)
+		for _, sd := range pNode.ScoreDistributions {
+			node.ClassCount[sd.Value] = sd.RecordCount
+		}
+	}
+	if len(pNode.Nodes) == 0 {
+		if pNode.Score == "" {
+			return nil, fmt.Errorf("leaf node '%s' does not have a score", pNode.Id)
+		}
+		node.LeafClass = pNode.Score
+		return node, nil
+	}
+
+	var splitAttr data.Attribute
+	for _, pChild := range pNode.Nodes {
+		child, err := newTreeNodeFromPMML(attributes, pChild)
+		if err != nil {
+			return nil, err
+		}
+		if child.Condition == nil {
+			return nil, fmt.Errorf("child node '%s' of node '%s' does not have a predicate", pChild.Id, pNode.Id)
+		}
+		if splitAttr == nil {
+			splitAttr = child.Condition.Attr()
+		} else if child.Condition.Attr() != splitAttr {
+			return nil, fmt.Errorf("children of node '%s' split on different fields", pNode.Id)
+		}
+		child.IsPrioritized = pNode.DefaultChild != "" && pChild.Id == pNode.DefaultChild
+		node.Children = append(node.Children, child)
+	}
+	return node, nil
+}
+
+// pmmlNodeCondition returns the condition of the node predicate, nil is returned for the True predicate.
+func pmmlNodeCondition(attributes map[string]data.Attribute, pNode *pmmlNode) (tree.Condition, error) {
+	switch {
+	case pNode.True != nil:
+		return nil, nil
+	case pNode.SimplePredicate != nil:
+		return pmmlSimpleCondition(attributes, pNode.SimplePredicate)
+	case pNode.SimpleSetPredicate != nil:
+		p := pNode.SimpleSetPredicate
+		attr, err := pmmlAttribute(attributes, p.Field, data.Nominal)
+		if err != nil {
+			return nil, err
+		}
+		if p.BooleanOperator != "isIn" {
+			return nil, fmt.Errorf("unsupported set operator '%s'", p.BooleanOperator)
+		}
+		if p.Array == nil {
+			return nil, fmt.Errorf("set predicate on field '%s' does not have an array", p.Field)
+		}
+		values, err := parsePMMLArray(p.Array.Value)
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewIsOneOfCondition(attr, values), nil
+	case pNode.CompoundPredicate != nil:
+		p := pNode.CompoundPredicate
+		if p.BooleanOperator != "and" || len(p.SimplePredicates) != 2 {
+			return nil, fmt.Errorf("unsupported compound predicate, only the conjunction of two simple predicates is supported")
+		}
+		lower, err := pmmlSimpleCondition(attributes, p.SimplePredicates[0])
+		if err != nil {
+			return nil, err
+		}
+		upper, err := pmmlSimpleCondition(attributes, p.SimplePredicates[1])
+		if err != nil {
+			return nil, err
+		}
+		l, lok := lower.(*tree.ContinuousCondition)
+		u, uok := upper.(*tree.ContinuousCondition)
+		if !lok || !uok || l.Type() != tree.GreaterThanEq || u.Type() != tree.LessThan || l.Attr() != u.Attr() {
+			return nil, fmt.Errorf("unsupported compound predicate, only greaterOrEqual and lessThan on the same field is supported")
+		}
+		return tree.NewRangeCondition(l.Attr(), l.LowerValue(), u.UpperValue()), nil
+	default:
+		return nil, fmt.Errorf("unsupported predicate")
+	}
+}
+
+func pmmlSimpleCondition(attributes map[string]data.Attribute, p *pmmlSimplePredicate) (tree.Condition, error) {
+	if p.Operator == "equal" {
+		attr, err := pmmlAttribute(attributes, p.Field, data.Nominal)
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewIsOneOfCondition(attr, []string{p.Value}), nil
+	}
+	if attr, ok := attributes[p.Field]; ok && attr.Type() == data.Ordinal {
+		switch p.Operator {
+		case "lessOrEqual":
+			return tree.NewLessEqCondition(attr, p.Value), nil
+		case "greaterThan":
+			return tree.NewGreaterCondition(attr, p.Value), nil
+		default:
+			return nil, fmt.Errorf("unsupported operator '%s' of ordinal field '%s'", p.Operator, p.Field)
+		}
+	}
+
+	if attr, ok := attributes[p.Field]; ok && attr.Type() == data.DateTime {
+		switch p.Operator {
+		case "lessThan", "greaterOrEqual":
+		default:
+			return nil, fmt.Errorf("unsupported operator '%s'", p.Operator)
+		}
+		value, err := attr.Parse(&config.Config{}, p.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse value '%s' of field '%s': %w", p.Value, p.Field, err)
+		}
+		if p.Operator == "lessThan" {
+			return tree.NewLessThanCondition(attr, value.Value().(float64)), nil
+		}
+		return tree.NewGreaterThanEqCondition(attr, value.Value().(float64)), nil
+	}
+
+	attr, err := pmmlAttribute(attributes, p.Field, data.Continuous)
+	if err != nil {
+		return nil, err
+	}
+	v, err := strconv.ParseFloat(p.Value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse value '%s' of field '%s': %w", p.Value, p.Field, err)
+	}
+	switch p.Operator {
+	case "lessThan":
+		return tree.NewLessThanCondition(attr, v), nil
+	case "greaterOrEqual":
+		return tree.NewGreaterThanEqCondition(attr, v), nil
+	default:
+		return nil, fmt.Errorf("unsupported operator '%s'", p.Operator)
+	}
+}
+
+func pmmlAttribute(attributes map[string]data.Attribute, name string, attrType data.AttributeType) (data.Attribute, error) {
+	attr, ok := attributes[name]
+	if !ok {
+		return nil, fmt.Errorf("field '%s' is not an active mining field", name)
+	}
+	if attr.Type() != attrType && !(attrType == data.Nominal && attr.Type() == data.Ordinal) {
+		return nil, fmt.Errorf("field '%s' is %s, expected %s", name, attr.Type(), attrType)
+	}
+	return attr, nil
+}
@@ -0,0 +1,221 @@
+package export
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type SQLDialect string
+
+const (
+	ANSI      SQLDialect = "ansi"      // "column", also used by PostgreSQL
+	SQLite    SQLDialect = "sqlite"    // "column", datetime values are 'YYYY-MM-DD hh:mm:ss' text
+	MySQL     SQLDialect = "mysql"     // `column`
+	SQLServer SQLDialect = "sqlserver" // [column]
+)
+
+// SQLOptions controls the generated SQL expression.
+type SQLOptions struct {
+	Dialect SQLDialect // default ANSI
+	// ColumnNames maps attribute names to column names, attributes not in the map use their own names.
+	ColumnNames map[string]string
+}
+
+// WriteSQL writes the tree as a SQL `CASE WHEN ... THEN ... END` expression evaluating to the predicted class,
+// which is the prediction of the leaves by the decision threshold of the tree, if any.
+// IsOneOf conditions become `IN (...)` and continuous conditions become comparisons.
+// Datetime attributes are compared with TIMESTAMP 'YYYY-MM-DD hh:mm:ss' literals in ANSI, and with 'YYYY-MM-DD hh:mm:ss'
+// strings in the other dialects, which SQLite compares as text. Their parts are extracted by EXTRACT(... FROM column)
+// in ANSI (weekday by DOW, which is PostgreSQL's), by strftime in SQLite, and by the date functions of MySQL and
+// SQL Server.
+// Missing values are NULL: comparisons with NULL are never true, so NULL values fall through to the ELSE branch,
+// which is the prioritized child, the same as the tree does.
+func WriteSQL(w io.Writer, tr *tree.Tree, opts *SQLOptions) error {
+	if tr.IsModelTree() {
+		return errModelTree
+	}
+	if opts == nil {
+		opts = &SQLOptions{}
+	}
+	g := &sqlGenerator{tr: tr, opts: opts, bw: bufio.NewWriter(w)}
+	if g.opts.Dialect == "" {
+		g.opts.Dialect = ANSI
+	}
+	switch g.opts.Dialect {
+	case ANSI, SQLite, MySQL, SQLServer:
+	default:
+		return fmt.Errorf("unknown sql dialect '%s'", g.opts.Dialect)
+	}
+
+	if err := g.writeNode(tr.RootNode, 0); err != nil {
+		return err
+	}
+	_, _ = g.bw.WriteString("\n")
+	if err := g.bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write sql: %w", err)
+	}
+	return nil
+}
+
+type sqlGenerator struct {
+	tr   *tree.Tree
+	opts *SQLOptions
+	bw   *bufio.Writer
+}
+
+func (g *sqlGenerator) writeNode(node *tree.Node, depth int) error {
+	if len(node.Children) == 0 {
+		_, _ = g.bw.WriteString(g.quoteString(g.tr.LeafPrediction(node)))
+		return nil
+	}
+
+	var (
+		indent      = strings.Repeat("  ", depth)
+		prioritized *tree.Node
+	)
+	_, _ = g.bw.WriteString("CASE")
+	for _, child := range node.Children {
+		if child.IsPrioritized && prioritized == nil {
+			prioritized = child
+			continue
+		}
+		expr, err := g.conditionExpr(child.Condition)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(g.bw, "\n%s  WHEN %s THEN ", indent, expr)
+		if err := g.writeNode(child, depth+1); err != nil {
+			return err
+		}
+	}
+	_, _ = fmt.Fprintf(g.bw, "\n%s  ELSE ", indent)
+	if prioritized == nil {
+		_, _ = g.bw.WriteString("NULL")
+	} else if err := g.writeNode(prioritized, depth+1); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(g.bw, "\n%sEND", indent)
+	return nil
+}
+
+func (g *sqlGenerator) conditionExpr(condition tree.Condition) (string, error) {
+	column := g.column(condition.Attr().Name())
+	switch c := condition.(type) {
+	case *tree.ContinuousCondition:
+		if part, ok := c.Attr().(*data.DateTimePartAttribute); ok {
+			column = g.partExpr(part)
+		}
+		switch c.Type() {
+		case tree.LessThan:
+			return fmt.Sprintf("%s < %s", column, g.value(c.Attr(), c.UpperValue())), nil
+		case tree.GreaterThanEq:
+			return fmt.Sprintf("%s >= %s", column, g.value(c.Attr(), c.LowerValue())), nil
+		case tree.Range:
+			return fmt.Sprintf("%s >= %s AND %s < %s", column, g.value(c.Attr(), c.LowerValue()), column, g.value(c.Attr(), c.UpperValue())), nil
+		}
+	case *tree.ObliqueCondition:
+		// the sum is NULL if any of the columns is, which falls through to the prioritized branch
+		sum := g.combinationExpr(c.Combination())
+		switch c.Type() {
+		case tree.ObliqueLessThan:
+			return fmt.Sprintf("%s < %s", sum, sqlFloat(c.Threshold())), nil
+		case tree.ObliqueGreaterThanEq:
+			return fmt.Sprintf("%s >= %s", sum, sqlFloat(c.Threshold())), nil
+		}
+	case tree.ValueSetCondition:
+		if len(c.AcceptedValues()) == 0 {
+			return "1 = 0", nil
+		}
+		var values []string
+		for _, v := range c.AcceptedValues() {
+			values = append(values, g.quoteString(v))
+		}
+		return fmt.Sprintf("%s IN (%s)", column, strings.Join(values, ", ")), nil
+	}
+	return "", fmt.Errorf("unsupported condition type '%s'", condition.Type())
+}
+
+func (g *sqlGenerator) column(attrName string) string {
+	name := attrName
+	if mapped, ok := g.opts.ColumnNames[attrName]; ok {
+		name = mapped
+	}
+	switch g.opts.Dialect {
+	case MySQL:
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	case SQLServer:
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	default:
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	}
+}
+
+func (g *sqlGenerator) quoteString(s string) string {
+	if g.opts.Dialect == MySQL {
+		// backslash is an escape character in MySQL string literals by default
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// value returns the literal of the threshold of the attribute, a datetime literal of datetime attributes.
+func (g *sqlGenerator) value(attr data.Attribute, v float64) string {
+	if dateTimeAttr, ok := attr.(*data.DateTimeAttribute); ok {
+		literal := g.quoteString(dateTimeAttr.Time(v).Format("2006-01-02 15:04:05.999999999"))
+		if g.opts.Dialect == ANSI {
+			return "TIMESTAMP " + literal
+		}
+		return literal
+	}
+	return sqlFloat(v)
+}
+
+// combinationExpr returns the weighted sum of the columns of the linear combination.
+func (g *sqlGenerator) combinationExpr(combination *data.LinearCombinationAttribute) string {
+	var terms []string
+	for i, attr := range combination.Attributes {
+		terms = append(terms, fmt.Sprintf("%s * %s", sqlFloat(combination.Weights[i]), g.column(attr.Name())))
+	}
+	return "(" + strings.Join(terms, " + ") + ")"
+}
+
+// partExpr returns the expression extracting the part of the datetime column, weekdays are 0 (Sunday) to 6.
+func (g *sqlGenerator) partExpr(part *data.DateTimePartAttribute) string {
+	column := g.column(part.Parent.Name())
+	switch g.opts.Dialect {
+	case MySQL:
+		if part.Part == data.Weekday {
+			return fmt.Sprintf("(DAYOFWEEK(%s) - 1)", column)
+		}
+		return fmt.Sprintf("%s(%s)", strings.ToUpper(string(part.Part)), column)
+	case SQLServer:
+		if part.Part == data.Weekday {
+			return fmt.Sprintf("(DATEPART(weekday, %s) - 1)", column)
+		}
+		return fmt.Sprintf("DATEPART(%s, %s)", part.Part, column)
+	case SQLite:
+		return fmt.Sprintf("CAST(strftime('%s', %s) AS INTEGER)", sqliteFormats[part.Part], column)
+	default:
+		if part.Part == data.Weekday {
+			return fmt.Sprintf("EXTRACT(DOW FROM %s)", column)
+		}
+		return fmt.Sprintf("EXTRACT(%s FROM %s)", strings.ToUpper(string(part.Part)), column)
+	}
+}
+
+// sqliteFormats are the strftime formats of the datetime parts.
+var sqliteFormats = map[data.DateTimePart]string{
+	data.Year:    "%Y",
+	data.Month:   "%m",
+	data.Weekday: "%w",
+	data.Hour:    "%H",
+}
+
+func sqlFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -2,14 +2,18 @@ module DecisionTree
 
 go 1.23.2
 
+require (
+	github.com/go-echarts/go-echarts/v2 v2.4.2
+	github.com/gosuri/uiprogress v0.0.1
+	github.com/stretchr/testify v1.9.0
+	google.golang.org/protobuf v1.36.12
+)
+
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/go-echarts/go-echarts/v2 v2.4.2 // indirect
 	github.com/gosuri/uilive v0.0.4 // indirect
-	github.com/gosuri/uiprogress v0.0.1 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.9.0 // indirect
 	golang.org/x/sys v0.6.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,443 @@
+package profile
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Report is the profile of a data set: a summary of every attribute, the class balance and the duplicate rows.
+type Report struct {
+	InstanceCount int                 `json:"instance_count"`
+	Attributes    []*AttributeProfile `json:"attributes"`
+	Class         *ClassProfile       `json:"class"`
+	Duplicates    *DuplicateProfile   `json:"duplicates"`
+}
+
+// AttributeProfile is the summary of an attribute. Values and UnseenValues are only set for nominal attributes, and
+// Summary only for continuous attributes.
+type AttributeProfile struct {
+	Name         string             `json:"name"`
+	Type         data.AttributeType `json:"type"`
+	MissingCount int                `json:"missing_count"`
+	MissingRate  float64            `json:"missing_rate"`
+	Cardinality  int                `json:"cardinality"` // number of distinct non-missing values
+
+	// InformationGain estimates how much the attribute tells about the class: the gain of a multi-way split for
+	// nominal attributes, or of the best binary split for continuous attributes, on the non-missing values and
+	// scaled by the fraction of non-missing values as C4.5 does.
+	InformationGain float64 `json:"information_gain"`
+	// Threshold is the value of the best binary split of a continuous attribute.
+	Threshold float64 `json:"threshold,omitempty"`
+
+	Values       []*ValueFrequency  `json:"values,omitempty"`        // in descending order of count
+	UnseenValues []string           `json:"unseen_values,omitempty"` // accepted values that are never seen
+	Summary      *ContinuousSummary `json:"summary,omitempty"`
+}
+
+type ValueFrequency struct {
+	Value     string  `json:"value"`
+	Count     int     `json:"count"`
+	Frequency float64 `json:"frequency"` // fraction of the non-missing values
+}
+
+type ContinuousSummary struct {
+	Min       float64     `json:"min"`
+	Max       float64     `json:"max"`
+	Mean      float64     `json:"mean"`
+	StdDev    float64     `json:"std_dev"`
+	Quantiles []*Quantile `json:"quantiles"`
+}
+
+type Quantile struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+// ClassProfile is the class balance of the data set.
+type ClassProfile struct {
+	Name           string            `json:"name"`
+	Values         []*ValueFrequency `json:"values"`                  // in descending order of count
+	UnseenValues   []string          `json:"unseen_values,omitempty"` // accepted values that are never seen
+	MissingCount   int               `json:"missing_count"`
+	Entropy        float64           `json:"entropy"`
+	ImbalanceRatio float64           `json:"imbalance_ratio"` // count of the majority class / count of the minority class
+}
+
+// DuplicateProfile counts the rows appearing more than once, and the attributes carrying the same information.
+type DuplicateProfile struct {
+	// DuplicateRows is the number of rows identical to an earlier row, including the class.
+	DuplicateRows int `json:"duplicate_rows"`
+	// ConflictingRows is the number of rows whose attribute values are identical to an earlier row with another
+	// class, such rows can never be all predicted correctly.
+	ConflictingRows int `json:"conflicting_rows"`
+	// Groups are the most frequent duplicate rows.
+	Groups []*DuplicateGroup `json:"groups,omitempty"`
+	// RedundantAttributes are the pairs of attributes whose non-missing values map one-to-one to each other, one of
+	// them can be removed without losing information.
+	RedundantAttributes []*AttributePair `json:"redundant_attributes,omitempty"`
+}
+
+type DuplicateGroup struct {
+	Row   string `json:"row"`
+	Count int    `json:"count"`
+}
+
+type AttributePair struct {
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+type Options struct {
+	Quantiles       []float64 // quantiles of continuous attributes, DefaultQuantiles if nil
+	DuplicateGroups int       // number of the most frequent duplicate rows in the report, 10 if 0
+}
+
+var DefaultQuantiles = []float64{0.05, 0.25, 0.5, 0.75, 0.95}
+
+const defaultDuplicateGroups = 10
+
+// Profile summarizes the data set of the attribute table.
+func Profile(attrTable *data.AttributeTable, valueTable *data.ValueTable, opts *Options) (*Report, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	quantiles := opts.Quantiles
+	if quantiles == nil {
+		quantiles = DefaultQuantiles
+	}
+	for _, q := range quantiles {
+		if q < 0 || q > 1 {
+			return nil, fmt.Errorf("invalid quantile %v", q)
+		}
+	}
+	duplicateGroups := opts.DuplicateGroups
+	if duplicateGroups == 0 {
+		duplicateGroups = defaultDuplicateGroups
+	}
+
+	var (
+		report  = &Report{InstanceCount: len(valueTable.Instances)}
+		classes = make([]string, len(valueTable.Instances))
+		columns = make([][]data.Value, len(attrTable.Attributes))
+	)
+	for i, instance := range valueTable.Instances {
+		if !instance.ClassValue.IsMissing() {
+			classes[i] = instance.ClassValue.Value().(string)
+		}
+		for j, attr := range attrTable.Attributes {
+			value := instance.GetValueByAttr(attr)
+			if value == nil {
+				return nil, fmt.Errorf("instance %d has no value of attribute '%s'", i, attr.Name())
+			}
+			columns[j] = append(columns[j], value)
+		}
+	}
+
+	report.Class = profileClass(attrTable.Class, valueTable.Instances)
+	for j, attr := range attrTable.Attributes {
+		report.Attributes = append(report.Attributes, profileAttribute(attr, columns[j], classes, quantiles))
+	}
+	report.Duplicates = profileDuplicates(attrTable.Attributes, columns, valueTable.Instances, duplicateGroups)
+	return report, nil
+}
+
+func profileClass(classAttr *data.NominalAttribute, instances []*data.Instance) *ClassProfile {
+	res := &ClassProfile{}
+	var (
+		counts     = make(map[string]int)
+		accepted   []string
+		nonMissing int
+	)
+	if classAttr != nil {
+		res.Name = classAttr.Name()
+		accepted = classAttr.AcceptedValues
+	}
+	for _, instance := range instances {
+		if instance.ClassValue.IsMissing() {
+			res.MissingCount++
+			continue
+		}
+		counts[instance.ClassValue.Value().(string)]++
+		nonMissing++
+	}
+	res.Values = valueFrequencies(counts, nonMissing)
+	res.UnseenValues = unseenValues(accepted, counts)
+
+	classCount := make(map[string]float64)
+	for class, count := range counts {
+		classCount[class] = float64(count)
+	}
+	res.Entropy = tree.Entropy(classCount)
+	if len(res.Values) > 0 {
+		res.ImbalanceRatio = float64(res.Values[0].Count) / float64(res.Values[len(res.Values)-1].Count)
+	}
+	return res
+}
+
+func profileAttribute(attr data.Attribute, column []data.Value, classes []string, quantiles []float64) *AttributeProfile {
+	res := &AttributeProfile{Name: attr.Name(), Type: attr.Type()}
+	var known []int // indexes of the non-missing values with a class
+	for i, value := range column {
+		if value.IsMissing() {
+			res.MissingCount++
+		} else if classes[i] != "" {
+			known = append(known, i)
+		}
+	}
+	if len(column) > 0 {
+		res.MissingRate = float64(res.MissingCount) / float64(len(column))
+	}
+
+	switch attr.Type() {
+	case data.Nominal, data.Ordinal:
+		counts := make(map[string]int)
+		for _, value := range column {
+			if !value.IsMissing() {
+				counts[value.Value().(string)]++
+			}
+		}
+		res.Cardinality = len(counts)
+		res.Values = valueFrequencies(counts, len(column)-res.MissingCount)
+		res.UnseenValues = unseenValues(attr.(*data.NominalAttribute).AcceptedValues, counts)
+		res.InformationGain = nominalGain(column, classes, known)
+	case data.Continuous, data.DateTime:
+		var values []float64
+		for _, value := range column {
+			if !value.IsMissing() {
+				values = append(values, value.Value().(float64))
+			}
+		}
+		sort.Float64s(values)
+		res.Cardinality = distinctCount(values)
+		res.Summary = continuousSummary(values, quantiles)
+		res.InformationGain, res.Threshold = continuousGain(column, classes, known)
+	}
+	return res
+}
+
+func valueFrequencies(counts map[string]int, total int) []*ValueFrequency {
+	var res []*ValueFrequency
+	for value, count := range counts {
+		res = append(res, &ValueFrequency{Value: value, Count: count, Frequency: float64(count) / float64(total)})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return res[i].Value < res[j].Value
+	})
+	return res
+}
+
+func unseenValues(accepted []string, counts map[string]int) []string {
+	var res []string
+	for _, value := range accepted {
+		if counts[value] == 0 {
+			res = append(res, value)
+		}
+	}
+	return res
+}
+
+func distinctCount(sorted []float64) int {
+	count := 0
+	for i, v := range sorted {
+		if i == 0 || v != sorted[i-1] {
+			count++
+		}
+	}
+	return count
+}
+
+// continuousSummary summarizes the sorted values, nil is returned if there is no value.
+func continuousSummary(sorted []float64, quantiles []float64) *ContinuousSummary {
+	if len(sorted) == 0 {
+		return nil
+	}
+	res := &ContinuousSummary{Min: sorted[0], Max: sorted[len(sorted)-1]}
+	for _, v := range sorted {
+		res.Mean += v
+	}
+	res.Mean /= float64(len(sorted))
+	for _, v := range sorted {
+		res.StdDev += (v - res.Mean) * (v - res.Mean)
+	}
+	res.StdDev = math.Sqrt(res.StdDev / float64(len(sorted)))
+	for _, q := range quantiles {
+		res.Quantiles = append(res.Quantiles, &Quantile{Quantile: q, Value: quantile(sorted, q)})
+	}
+	return res
+}
+
+// quantile interpolates linearly between the closest ranks of the sorted values.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(math.Floor(pos))
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// nominalGain is the information gain of splitting the known instances by every value, scaled by the fraction of
+// known instances.
+func nominalGain(column []data.Value, classes []string, known []int) float64 {
+	if len(known) == 0 {
+		return 0
+	}
+	var (
+		total      = make(map[string]float64)
+		byValue    = make(map[string]map[string]float64)
+		valueTotal = make(map[string]float64)
+	)
+	for _, i := range known {
+		value := column[i].Value().(string)
+		if byValue[value] == nil {
+			byValue[value] = make(map[string]float64)
+		}
+		byValue[value][classes[i]]++
+		valueTotal[value]++
+		total[classes[i]]++
+	}
+	n := float64(len(known))
+	entropy := 0.0
+	for value, classCount := range byValue {
+		entropy += valueTotal[value] / n * tree.Entropy(classCount)
+	}
+	return (tree.Entropy(total) - entropy) * n / float64(len(column))
+}
+
+// continuousGain is the information gain of the best binary split of the known instances, scaled by the fraction of
+// known instances, with the threshold of the split.
+func continuousGain(column []data.Value, classes []string, known []int) (float64, float64) {
+	if len(known) < 2 {
+		return 0, 0
+	}
+	sorted := append([]int(nil), known...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return column[sorted[i]].Value().(float64) < column[sorted[j]].Value().(float64)
+	})
+	var (
+		left  = make(map[string]float64)
+		right = make(map[string]float64)
+		n     = float64(len(sorted))
+	)
+	for _, i := range sorted {
+		right[classes[i]]++
+	}
+	var (
+		rootEntropy = tree.Entropy(right)
+		bestGain    float64
+		threshold   float64
+	)
+	for k := 1; k < len(sorted); k++ {
+		class := classes[sorted[k-1]]
+		left[class]++
+		right[class]--
+		v1, v2 := column[sorted[k-1]].Value().(float64), column[sorted[k]].Value().(float64)
+		if v1 == v2 {
+			continue
+		}
+		leftCount := float64(k)
+		entropy := (leftCount*tree.Entropy(left) + (n-leftCount)*tree.Entropy(right)) / n
+		if gain := rootEntropy - entropy; gain > bestGain {
+			bestGain = gain
+			threshold = (v1 + v2) / 2
+		}
+	}
+	return bestGain * n / float64(len(column)), threshold
+}
+
+// rowKey joins the values into a string, missing values are written as '?'.
+func rowKey(values []data.Value) string {
+	var sb strings.Builder
+	for i, value := range values {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if value.IsMissing() {
+			sb.WriteString("?")
+		} else {
+			sb.WriteString(fmt.Sprintf("%v", value.Value()))
+		}
+	}
+	return sb.String()
+}
+
+func profileDuplicates(attributes []data.Attribute, columns [][]data.Value, instances []*data.Instance, maxGroups int) *DuplicateProfile {
+	var (
+		res       = &DuplicateProfile{}
+		rowCounts = make(map[string]int)
+		rowOrder  []string
+		attrClass = make(map[string]string) // attribute values -> class of the first row
+		row       = make([]data.Value, len(columns))
+	)
+	for i, instance := range instances {
+		for j := range columns {
+			row[j] = columns[j][i]
+		}
+		attrKey := rowKey(row)
+		fullKey := rowKey(append(row, instance.ClassValue))
+		if rowCounts[fullKey] == 0 {
+			rowOrder = append(rowOrder, fullKey)
+		} else {
+			res.DuplicateRows++
+		}
+		rowCounts[fullKey]++
+
+		class := rowKey([]data.Value{instance.ClassValue})
+		if first, ok := attrClass[attrKey]; !ok {
+			attrClass[attrKey] = class
+		} else if first != class {
+			res.ConflictingRows++
+		}
+	}
+
+	for _, key := range rowOrder {
+		if rowCounts[key] > 1 {
+			res.Groups = append(res.Groups, &DuplicateGroup{Row: key, Count: rowCounts[key]})
+		}
+	}
+	sort.SliceStable(res.Groups, func(i, j int) bool {
+		return res.Groups[i].Count > res.Groups[j].Count
+	})
+	if len(res.Groups) > maxGroups {
+		res.Groups = res.Groups[:maxGroups]
+	}
+
+	for a := range attributes {
+		for b := a + 1; b < len(attributes); b++ {
+			if oneToOne(columns[a], columns[b]) {
+				res.RedundantAttributes = append(res.RedundantAttributes, &AttributePair{A: attributes[a].Name(), B: attributes[b].Name()})
+			}
+		}
+	}
+	return res
+}
+
+// oneToOne tells whether the non-missing values of the columns map one-to-one to each other, rows with a missing
+// value in either column are skipped. Columns with a single distinct value are not considered redundant.
+func oneToOne(a, b []data.Value) bool {
+	var (
+		aToB = make(map[interface{}]interface{})
+		bToA = make(map[interface{}]interface{})
+	)
+	for i := range a {
+		if a[i].IsMissing() || b[i].IsMissing() {
+			continue
+		}
+		va, vb := a[i].Value(), b[i].Value()
+		if mapped, ok := aToB[va]; ok && mapped != vb {
+			return false
+		}
+		if mapped, ok := bToA[vb]; ok && mapped != va {
+			return false
+		}
+		aToB[va], bToA[vb] = vb, va
+	}
+	return len(aToB) > 1
+}
@@ -0,0 +1,108 @@
+package profile
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestData(t *testing.T) (*data.AttributeTable, *data.ValueTable) {
+	var (
+		conf      = &config.Config{ConsiderInvalidDataAsMissing: true}
+		age       = data.NewContinuousAttribute("age")
+		color     = data.NewNominalAttribute("color", []string{"red", "green", "blue"})
+		code      = data.NewNominalAttribute("code", []string{"r", "g", "b"})
+		classAttr = data.NewNominalAttribute("Class", []string{"yes", "no", "maybe"})
+		attrTable = &data.AttributeTable{Attributes: []data.Attribute{age, color, code}, Class: classAttr}
+	)
+	rows := []map[string]string{
+		{"age": "10", "color": "red", "code": "r", "Class": "yes"},
+		{"age": "20", "color": "red", "code": "r", "Class": "yes"},
+		{"age": "20", "color": "red", "code": "r", "Class": "yes"}, // duplicate of the row above
+		{"age": "30", "color": "green", "code": "g", "Class": "no"},
+		{"age": "40", "color": "green", "code": "g", "Class": "no"},
+		{"age": "40", "color": "green", "code": "g", "Class": "yes"}, // conflicts with the row above
+		{"age": "?", "color": "?", "code": "g", "Class": "no"},
+		{"age": "50", "color": "green", "code": "?", "Class": "no"},
+	}
+	valueTable := &data.ValueTable{}
+	for _, row := range rows {
+		instance, err := data.ParseInstance(conf, attrTable.Attributes, classAttr, row)
+		if err != nil {
+			t.Fatalf("failed to parse instance: %v", err)
+		}
+		valueTable.Instances = append(valueTable.Instances, instance)
+	}
+	return attrTable, valueTable
+}
+
+func TestProfile(t *testing.T) {
+	attrTable, valueTable := newTestData(t)
+	report, err := Profile(attrTable, valueTable, &Options{Quantiles: []float64{0, 0.5, 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, 8, report.InstanceCount)
+
+	age := report.Attributes[0]
+	assert.Equal(t, data.Continuous, age.Type)
+	assert.Equal(t, 1, age.MissingCount)
+	assert.InDelta(t, 0.125, age.MissingRate, 1e-9)
+	assert.Equal(t, 5, age.Cardinality)
+	assert.Equal(t, &ContinuousSummary{
+		Min:       10,
+		Max:       50,
+		Mean:      30,
+		StdDev:    13.093073414159543,
+		Quantiles: []*Quantile{{Quantile: 0, Value: 10}, {Quantile: 0.5, Value: 30}, {Quantile: 1, Value: 50}},
+	}, age.Summary)
+	// age < 25: 3 yes, age >= 25: 3 no and 1 yes
+	assert.Equal(t, 25.0, age.Threshold)
+	assert.InDelta(t, (0.98522814-4.0/7*0.81127812)*7/8, age.InformationGain, 1e-6)
+
+	color := report.Attributes[1]
+	assert.Equal(t, 2, color.Cardinality)
+	assert.Equal(t, []*ValueFrequency{
+		{Value: "green", Count: 4, Frequency: 4.0 / 7},
+		{Value: "red", Count: 3, Frequency: 3.0 / 7},
+	}, color.Values)
+	assert.Equal(t, []string{"blue"}, color.UnseenValues)
+	assert.Nil(t, color.Summary)
+	assert.InDelta(t, age.InformationGain, color.InformationGain, 1e-9, "color splits as age < 25")
+
+	assert.Equal(t, "Class", report.Class.Name)
+	assert.Equal(t, []string{"maybe"}, report.Class.UnseenValues)
+	assert.InDelta(t, 1.0, report.Class.ImbalanceRatio, 1e-9)
+	assert.InDelta(t, 1.0, report.Class.Entropy, 1e-9)
+
+	assert.Equal(t, 1, report.Duplicates.DuplicateRows)
+	assert.Equal(t, 1, report.Duplicates.ConflictingRows)
+	assert.Equal(t, []*DuplicateGroup{{Row: "20, red, r, yes", Count: 2}}, report.Duplicates.Groups)
+	assert.Equal(t, []*AttributePair{{A: "color", B: "code"}}, report.Duplicates.RedundantAttributes)
+
+	_, err = Profile(attrTable, valueTable, &Options{Quantiles: []float64{1.5}})
+	assert.Error(t, err)
+}
+
+func TestWriteProfile(t *testing.T) {
+	attrTable, valueTable := newTestData(t)
+	report, err := Profile(attrTable, valueTable, nil)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteJson(&buf, report))
+	var decoded Report
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, report, &decoded)
+
+	buf.Reset()
+	assert.NoError(t, WriteText(&buf, report))
+	text := buf.String()
+	assert.Contains(t, text, "Instances: 8\n")
+	assert.Contains(t, text, "p5 13, p25 20, p50 30, p75 40, p95 47")
+	assert.Contains(t, text, "never seen: blue\n")
+	assert.Contains(t, text, "Duplicate rows: 1, conflicting rows (same attributes, another class): 1\n")
+	assert.Contains(t, text, "Redundant attributes: color and code map one-to-one\n")
+}
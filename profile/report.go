@@ -0,0 +1,93 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// maxTextValues is the number of the most frequent values of a nominal attribute written by WriteText.
+const maxTextValues = 10
+
+// WriteJson writes the report as indented JSON.
+func WriteJson(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false) // class values like "<=50K"
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	return nil
+}
+
+// WriteText writes the report as human-readable text: a table of all attributes in descending order of information
+// gain, followed by the details of each attribute, the class balance and the duplicates.
+func WriteText(w io.Writer, report *Report) error {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Instances: %d\n\n", report.InstanceCount))
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Attribute\tType\tMissing\tCardinality\tInformation gain")
+	for _, attr := range sortedByGain(report.Attributes) {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%.2f%%\t%d\t%.4f\n", attr.Name, attr.Type, attr.MissingRate*100, attr.Cardinality, attr.InformationGain)
+	}
+	_ = tw.Flush()
+
+	for _, attr := range report.Attributes {
+		sb.WriteString(fmt.Sprintf("\n[%s] %s, missing %d (%.2f%%), %d distinct values\n", attr.Name, attr.Type, attr.MissingCount, attr.MissingRate*100, attr.Cardinality))
+		if s := attr.Summary; s != nil {
+			sb.WriteString(fmt.Sprintf("  min %g, max %g, mean %.4g, std dev %.4g\n", s.Min, s.Max, s.Mean, s.StdDev))
+			var quantiles []string
+			for _, q := range s.Quantiles {
+				quantiles = append(quantiles, fmt.Sprintf("p%g %.6g", q.Quantile*100, q.Value))
+			}
+			sb.WriteString("  " + strings.Join(quantiles, ", ") + "\n")
+			sb.WriteString(fmt.Sprintf("  best split at %g\n", attr.Threshold))
+		}
+		writeValueFrequencies(&sb, attr.Values, attr.UnseenValues)
+	}
+
+	if c := report.Class; c != nil {
+		sb.WriteString(fmt.Sprintf("\nClass [%s], missing %d, entropy %.4f, imbalance ratio %.2f\n", c.Name, c.MissingCount, c.Entropy, c.ImbalanceRatio))
+		writeValueFrequencies(&sb, c.Values, c.UnseenValues)
+	}
+
+	if d := report.Duplicates; d != nil {
+		sb.WriteString(fmt.Sprintf("\nDuplicate rows: %d, conflicting rows (same attributes, another class): %d\n", d.DuplicateRows, d.ConflictingRows))
+		for _, group := range d.Groups {
+			sb.WriteString(fmt.Sprintf("  %d x %s\n", group.Count, group.Row))
+		}
+		for _, pair := range d.RedundantAttributes {
+			sb.WriteString(fmt.Sprintf("Redundant attributes: %s and %s map one-to-one\n", pair.A, pair.B))
+		}
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	return nil
+}
+
+func writeValueFrequencies(sb *strings.Builder, values []*ValueFrequency, unseen []string) {
+	for i, v := range values {
+		if i == maxTextValues {
+			sb.WriteString(fmt.Sprintf("  ... %d more values\n", len(values)-maxTextValues))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("  %-24s %8d  %6.2f%%\n", v.Value, v.Count, v.Frequency*100))
+	}
+	if len(unseen) > 0 {
+		sb.WriteString("  never seen: " + strings.Join(unseen, ", ") + "\n")
+	}
+}
+
+func sortedByGain(attributes []*AttributeProfile) []*AttributeProfile {
+	res := append([]*AttributeProfile(nil), attributes...)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].InformationGain > res[j].InformationGain
+	})
+	return res
+}
@@ -0,0 +1,368 @@
+package rules
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"cmp"
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// ExtractRules converts the tree into an ordered rule set, the way C4.5rules does:
+//  1. Every root-to-leaf path becomes a rule, whose class is the leaf class.
+//  2. Conditions on the same attribute are merged into one condition.
+//  3. Conditions whose removal does not increase the pessimistic error of the rule on the training data are dropped.
+//  4. Duplicated rules are removed, and the rest are ordered by confidence.
+//
+// The default class is the majority class of the training instances not covered by any rule.
+// The training data is transformed by the pipeline of the tree if any, which the rule set keeps to transform the
+// instances it predicts.
+func ExtractRules(tr *tree.Tree, trainData *data.ValueTable) (*RuleSet, error) {
+	trainData, err := tr.TransformTable(trainData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform training data: %w", err)
+	}
+	var instances []*data.Instance
+	for _, instance := range trainData.Instances {
+		if !instance.ClassValue.IsMissing() {
+			instances = append(instances, instance)
+		}
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("no valid instances")
+	}
+
+	var (
+		ruleSet = &RuleSet{Attributes: tr.Attributes, Pipeline: tr.Pipeline}
+		seen    = make(map[string]bool)
+	)
+	for _, rule := range collectPathRules(tr.RootNode, nil, nil) {
+		rule.Conditions = mergeConditions(rule.Conditions)
+		generalizeRule(rule, instances)
+		if rule.Coverage == 0 || seen[rule.Log()] {
+			continue
+		}
+		seen[rule.Log()] = true
+		ruleSet.Rules = append(ruleSet.Rules, rule)
+	}
+
+	slices.SortStableFunc(ruleSet.Rules, func(a, b *Rule) int {
+		switch {
+		case a.Confidence != b.Confidence:
+			return cmp.Compare(b.Confidence, a.Confidence)
+		default:
+			return b.Coverage - a.Coverage
+		}
+	})
+
+	ruleSet.DefaultClass = majorityClassOfUncovered(ruleSet, instances)
+	return ruleSet, nil
+}
+
+func collectPathRules(node *tree.Node, siblings []*tree.Node, conditions []*RuleCondition) []*Rule {
+	if node.Condition != nil {
+		conditions = append(slices.Clip(conditions), newRuleCondition(node, siblings))
+	}
+	if len(node.Children) == 0 {
+		return []*Rule{{Conditions: conditions, Class: node.LeafClass}}
+	}
+	var res []*Rule
+	for _, child := range node.Children {
+		res = append(res, collectPathRules(child, node.Children, conditions)...)
+	}
+	return res
+}
+
+// newRuleCondition returns the condition of reaching the node from its parent, siblings are the children of the
+// parent including the node itself. The tree routes the values meeting no condition of the children to the
+// prioritized child: its nominal condition is extended with the accepted values of the attribute that no sibling
+// accepts, as the PMML export does, and its other conditions keep the conditions of the siblings, unless the two
+// children are the sides of a threshold and meet every value.
+func newRuleCondition(node *tree.Node, siblings []*tree.Node) *RuleCondition {
+	res := &RuleCondition{Condition: node.Condition, MatchMissing: node.IsPrioritized}
+	if !node.IsPrioritized || isThresholdSplit(siblings) {
+		return res
+	}
+	if c, ok := node.Condition.(*tree.NominalCondition); ok && c.Type() == tree.IsOneOf {
+		if attr, ok := c.Attr().(*data.NominalAttribute); ok && len(attr.AcceptedValues) > 0 {
+			acceptedValues := append(slices.Clone(c.AcceptedValues()), uncoveredValues(attr, siblings)...)
+			res.Condition = tree.NewIsOneOfCondition(attr, acceptedValues)
+			return res
+		}
+	}
+	for _, sibling := range siblings {
+		if sibling != node {
+			res.Otherwise = append(res.Otherwise, sibling.Condition)
+		}
+	}
+	return res
+}
+
+// isThresholdSplit tells if the children are the two sides of the same threshold, one of which every value meets.
+func isThresholdSplit(children []*tree.Node) bool {
+	if len(children) != 2 {
+		return false
+	}
+	switch a := children[0].Condition.(type) {
+	case *tree.ContinuousCondition:
+		b, ok := children[1].Condition.(*tree.ContinuousCondition)
+		if !ok || a.Attr() != b.Attr() {
+			return false
+		}
+		switch {
+		case a.Type() == tree.LessThan && b.Type() == tree.GreaterThanEq:
+			return a.UpperValue() == b.LowerValue()
+		case a.Type() == tree.GreaterThanEq && b.Type() == tree.LessThan:
+			return a.LowerValue() == b.UpperValue()
+		}
+	case *tree.ObliqueCondition:
+		b, ok := children[1].Condition.(*tree.ObliqueCondition)
+		return ok && a.Attr().Name() == b.Attr().Name() && a.Threshold() == b.Threshold() && a.Type() != b.Type()
+	}
+	return false
+}
+
+// uncoveredValues returns the accepted values of the attribute that no condition of the nodes accepts.
+func uncoveredValues(attr *data.NominalAttribute, nodes []*tree.Node) []string {
+	covered := make(map[string]bool)
+	for _, node := range nodes {
+		if c, ok := node.Condition.(tree.ValueSetCondition); ok {
+			for _, v := range c.AcceptedValues() {
+				covered[v] = true
+			}
+		}
+	}
+	var res []string
+	for _, v := range attr.AcceptedValues {
+		if !covered[v] {
+			covered[v] = true
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// mergeConditions merges the conditions on the same attribute:
+// continuous conditions are merged into the tightest LessThan, GreaterThanEq or Range condition, and
+// nominal conditions are merged into one IsOneOf condition with the intersection of the accepted values.
+// Other conditions, and conditions met by the values meeting none of the conditions of their siblings, are kept as
+// they are.
+func mergeConditions(conditions []*RuleCondition) []*RuleCondition {
+	var (
+		attrOrder []string
+		groups    = make(map[string][]*RuleCondition)
+	)
+	for _, c := range conditions {
+		name := c.Condition.Attr().Name()
+		if _, ok := groups[name]; !ok {
+			attrOrder = append(attrOrder, name)
+		}
+		groups[name] = append(groups[name], c)
+	}
+
+	var res []*RuleCondition
+	for _, name := range attrOrder {
+		group := groups[name]
+		if merged := mergeContinuousConditions(group); merged != nil {
+			res = append(res, merged)
+		} else if merged := mergeNominalConditions(group); merged != nil {
+			res = append(res, merged)
+		} else {
+			res = append(res, group...)
+		}
+	}
+	return res
+}
+
+func mergeContinuousConditions(group []*RuleCondition) *RuleCondition {
+	var (
+		lower        = math.Inf(-1)
+		upper        = math.Inf(1)
+		matchMissing = true
+	)
+	for _, c := range group {
+		cond, ok := c.Condition.(*tree.ContinuousCondition)
+		if !ok || len(c.Otherwise) > 0 {
+			return nil
+		}
+		switch cond.Type() {
+		case tree.LessThan:
+			upper = min(upper, cond.UpperValue())
+		case tree.GreaterThanEq:
+			lower = max(lower, cond.LowerValue())
+		case tree.Range:
+			upper = min(upper, cond.UpperValue())
+			lower = max(lower, cond.LowerValue())
+		default:
+			return nil
+		}
+		matchMissing = matchMissing && c.MatchMissing
+	}
+
+	attr := group[0].Condition.Attr()
+	res := &RuleCondition{MatchMissing: matchMissing}
+	switch {
+	case !math.IsInf(lower, -1) && !math.IsInf(upper, 1):
+		res.Condition = tree.NewRangeCondition(attr, lower, upper)
+	case !math.IsInf(upper, 1):
+		res.Condition = tree.NewLessThanCondition(attr, upper)
+	default:
+		res.Condition = tree.NewGreaterThanEqCondition(attr, lower)
+	}
+	return res
+}
+
+func mergeNominalConditions(group []*RuleCondition) *RuleCondition {
+	var (
+		acceptedValues []string
+		matchMissing   = true
+	)
+	for i, c := range group {
+		cond, ok := c.Condition.(*tree.NominalCondition)
+		if !ok || cond.Type() != tree.IsOneOf || len(c.Otherwise) > 0 {
+			return nil
+		}
+		if i == 0 {
+			acceptedValues = cond.AcceptedValues()
+		} else {
+			acceptedValues = slices.DeleteFunc(slices.Clone(acceptedValues), func(v string) bool {
+				return !slices.Contains(cond.AcceptedValues(), v)
+			})
+		}
+		matchMissing = matchMissing && c.MatchMissing
+	}
+	return &RuleCondition{
+		Condition:    tree.NewIsOneOfCondition(group[0].Condition.Attr(), acceptedValues),
+		MatchMissing: matchMissing,
+	}
+}
+
+// generalizeRule greedily drops the condition whose removal results in the lowest pessimistic error, as long as
+// the pessimistic error does not increase. The coverage, error count and confidence of the rule are updated.
+func generalizeRule(rule *Rule, instances []*data.Instance) {
+	if len(rule.Conditions) > 64 {
+		// too many conditions to track with a bit mask, keep the rule as it is
+		rule.Coverage, rule.ErrorCount = evaluateRule(rule, instances)
+		rule.Confidence = 1 - calculatePessimisticError(rule.ErrorCount, rule.Coverage)
+		return
+	}
+
+	// failMasks[i] has bit j set if instance i does not meet condition j
+	failMasks := make([]uint64, len(instances))
+	correct := make([]bool, len(instances))
+	for i, instance := range instances {
+		for j, c := range rule.Conditions {
+			if !c.IsMet(instance) {
+				failMasks[i] |= 1 << j
+			}
+		}
+		correct[i] = instance.ClassValue.Value().(string) == rule.Class
+	}
+	evaluate := func(active uint64) (int, int) {
+		coverage, errorCount := 0, 0
+		for i, mask := range failMasks {
+			if mask&active != 0 {
+				continue
+			}
+			coverage++
+			if !correct[i] {
+				errorCount++
+			}
+		}
+		return coverage, errorCount
+	}
+
+	active := uint64(1)<<len(rule.Conditions) - 1
+	coverage, errorCount := evaluate(active)
+	pessimisticError := calculatePessimisticError(errorCount, coverage)
+	for active != 0 {
+		var (
+			next     = active
+			improved = false
+		)
+		for remaining := active; remaining != 0; remaining &= remaining - 1 {
+			candidate := active &^ (1 << bits.TrailingZeros64(remaining))
+			candidateCoverage, candidateErrorCount := evaluate(candidate)
+			candidateError := calculatePessimisticError(candidateErrorCount, candidateCoverage)
+			if candidateError <= pessimisticError {
+				next, pessimisticError, improved = candidate, candidateError, true
+				coverage, errorCount = candidateCoverage, candidateErrorCount
+			}
+		}
+		if !improved {
+			break
+		}
+		active = next
+	}
+
+	var conditions []*RuleCondition
+	for j, c := range rule.Conditions {
+		if active&(1<<j) != 0 {
+			conditions = append(conditions, c)
+		}
+	}
+	rule.Conditions = conditions
+	rule.Coverage = coverage
+	rule.ErrorCount = errorCount
+	rule.Confidence = 1 - pessimisticError
+}
+
+func evaluateRule(rule *Rule, instances []*data.Instance) (int, int) {
+	coverage, errorCount := 0, 0
+	for _, instance := range instances {
+		if !rule.IsMet(instance) {
+			continue
+		}
+		coverage++
+		if instance.ClassValue.Value().(string) != rule.Class {
+			errorCount++
+		}
+	}
+	return coverage, errorCount
+}
+
+// calculatePessimisticError follows the definition used by post-pruning, a rule counts as one leaf.
+func calculatePessimisticError(errorCount, coverage int) float64 {
+	if coverage == 0 {
+		return 1
+	}
+	return (float64(errorCount) + 0.5) / float64(coverage)
+}
+
+func majorityClassOfUncovered(ruleSet *RuleSet, instances []*data.Instance) string {
+	var (
+		uncovered = make(map[string]int)
+		all       = make(map[string]int)
+	)
+	for _, instance := range instances {
+		class := instance.ClassValue.Value().(string)
+		all[class]++
+		if ruleSet.matchRule(instance) == nil {
+			uncovered[class]++
+		}
+	}
+	if len(uncovered) == 0 {
+		return majorityClass(all)
+	}
+	return majorityClass(uncovered)
+}
+
+func majorityClass(classCount map[string]int) string {
+	var classes []string
+	for class := range classCount {
+		classes = append(classes, class)
+	}
+	slices.Sort(classes) // deterministic result on ties
+	var (
+		res      string
+		maxCount = -1
+	)
+	for _, class := range classes {
+		if classCount[class] > maxCount {
+			res, maxCount = class, classCount[class]
+		}
+	}
+	return res
+}
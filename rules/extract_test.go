@@ -0,0 +1,155 @@
+package rules
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/transform"
+	"DecisionTree/tree"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractRules(t *testing.T) {
+	var (
+		conf      = &config.Config{ConsiderInvalidDataAsMissing: true}
+		x         = data.NewContinuousAttribute("x")
+		color     = data.NewNominalAttribute("color", []string{"red", "green", "blue"})
+		classAttr = data.NewNominalAttribute("Class", []string{"yes", "no"})
+		attrs     = []data.Attribute{x, color}
+	)
+	// x < 10 -> (x >= 5 -> yes, x < 5 -> no), x >= 10 -> (color in [red] -> yes, color in [green, blue] -> no)
+	tr := &tree.Tree{
+		Attributes: attrs,
+		Class:      classAttr,
+		RootNode: &tree.Node{Children: []*tree.Node{
+			{Condition: tree.NewLessThanCondition(x, 10), IsPrioritized: true, Children: []*tree.Node{
+				{Condition: tree.NewLessThanCondition(x, 5), LeafClass: "no"},
+				{Condition: tree.NewGreaterThanEqCondition(x, 5), LeafClass: "yes", IsPrioritized: true},
+			}},
+			{Condition: tree.NewGreaterThanEqCondition(x, 10), Children: []*tree.Node{
+				{Condition: tree.NewIsOneOfCondition(color, []string{"red"}), LeafClass: "yes"},
+				{Condition: tree.NewIsOneOfCondition(color, []string{"green", "blue"}), LeafClass: "no", IsPrioritized: true},
+			}},
+		}},
+	}
+
+	rows := []map[string]string{
+		{"x": "1", "color": "red", "Class": "no"},
+		{"x": "2", "color": "blue", "Class": "no"},
+		{"x": "6", "color": "red", "Class": "yes"},
+		{"x": "7", "color": "green", "Class": "yes"},
+		{"x": "12", "color": "red", "Class": "yes"},
+		{"x": "15", "color": "green", "Class": "no"},
+		{"x": "20", "color": "blue", "Class": "no"},
+	}
+	trainData := &data.ValueTable{}
+	for _, row := range rows {
+		instance, err := data.ParseInstance(conf, attrs, classAttr, row)
+		if err != nil {
+			t.Fatalf("failed to parse instance: %v", err)
+		}
+		trainData.Instances = append(trainData.Instances, instance)
+	}
+
+	ruleSet, err := ExtractRules(tr, trainData)
+	if err != nil {
+		t.Fatalf("failed to extract rules: %v", err)
+	}
+	t.Log("\n" + ruleSet.String())
+
+	var logs []string
+	for _, rule := range ruleSet.Rules {
+		logs = append(logs, rule.Log())
+	}
+	// x < 10 and x >= 5 are merged into a range
+	assert.Contains(t, logs, "IF 5.00 <= x < 10.00 (or missing) THEN yes")
+	for _, instance := range trainData.Instances {
+		predicted, err := ruleSet.Predict(instance)
+		assert.NoError(t, err)
+		assert.Equal(t, instance.ClassValue.Value(), predicted)
+	}
+
+	// serialize and read back
+	path := filepath.Join(t.TempDir(), "rules.json")
+	assert.NoError(t, WriteRuleSetToFile(ruleSet, path))
+	restored, err := ReadRuleSetFromFile(path)
+	if err != nil {
+		t.Fatalf("failed to read rules: %v", err)
+	}
+	assert.Equal(t, ruleSet.String(), restored.String())
+}
+
+func TestExtractRulesFallback(t *testing.T) {
+	var (
+		conf      = &config.Config{ConsiderInvalidDataAsMissing: true}
+		color     = data.NewNominalAttribute("color", []string{"red", "green", "blue"})
+		shape     = data.NewNominalAttribute("shape", nil) // restored from an older model file, any value is accepted
+		classAttr = data.NewNominalAttribute("Class", []string{"yes", "no"})
+		attrs     = []data.Attribute{color, shape}
+	)
+	// color in [red] -> yes, color in [green] (prioritized) -> (shape in [circle] -> yes, shape in [square] (prioritized) -> no)
+	// blue and triangle meet no condition, and go to the prioritized children
+	tr := &tree.Tree{
+		Attributes: attrs,
+		Class:      classAttr,
+		RootNode: &tree.Node{Children: []*tree.Node{
+			{Condition: tree.NewIsOneOfCondition(color, []string{"red"}), LeafClass: "yes"},
+			{Condition: tree.NewIsOneOfCondition(color, []string{"green"}), IsPrioritized: true, Children: []*tree.Node{
+				{Condition: tree.NewIsOneOfCondition(shape, []string{"circle"}), LeafClass: "yes"},
+				{Condition: tree.NewIsOneOfCondition(shape, []string{"square"}), LeafClass: "no", IsPrioritized: true},
+			}},
+		}},
+	}
+	// the tree is fitted on colour, renamed from color by the pipeline
+	pipeline := transform.NewPipeline(&transform.Rename{Column: "colour", To: "color"})
+	rows := []map[string]string{
+		{"colour": "red", "shape": "circle", "Class": "yes"},
+		{"colour": "green", "shape": "circle", "Class": "yes"},
+		{"colour": "green", "shape": "square", "Class": "no"},
+		{"colour": "blue", "shape": "triangle", "Class": "no"},
+		{"colour": "blue", "shape": "circle", "Class": "yes"},
+	}
+	trainData := &data.ValueTable{}
+	inputAttrs := []data.Attribute{data.NewNominalAttribute("colour", color.AcceptedValues), shape}
+	for _, row := range rows {
+		instance, err := data.ParseInstance(conf, inputAttrs, classAttr, row)
+		if err != nil {
+			t.Fatalf("failed to parse instance: %v", err)
+		}
+		trainData.Instances = append(trainData.Instances, instance)
+	}
+	if _, err := pipeline.Fit(trainData); err != nil {
+		t.Fatalf("failed to fit pipeline: %v", err)
+	}
+	tr.Pipeline = pipeline
+
+	// the values of color that no sibling accepts meet the prioritized condition
+	assert.Equal(t, "color in ['green', 'blue'] (or missing)", newRuleCondition(tr.RootNode.Children[1], tr.RootNode.Children).Log())
+
+	ruleSet, err := ExtractRules(tr, trainData)
+	if err != nil {
+		t.Fatalf("failed to extract rules: %v", err)
+	}
+	t.Log("\n" + ruleSet.String())
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	assert.NoError(t, WriteRuleSetToFile(ruleSet, path))
+	restored, err := ReadRuleSetFromFile(path)
+	if err != nil {
+		t.Fatalf("failed to read rules: %v", err)
+	}
+	assert.Equal(t, ruleSet.String(), restored.String())
+	assert.Equal(t, "colour", restored.InputAttributes()[0].Name())
+
+	for _, instance := range trainData.Instances {
+		expected, err := tr.Predict(instance)
+		assert.NoError(t, err)
+		for _, r := range []*RuleSet{ruleSet, restored} {
+			predicted, err := r.Predict(instance)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, predicted, "%v", instance)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package rules
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/transform"
+	"DecisionTree/tree"
+	"fmt"
+	"strings"
+)
+
+// RuleCondition is a condition of a rule.
+// MatchMissing is set if the condition comes from prioritized branches only, so a missing value meets it,
+// the same way the tree routes missing values.
+// Otherwise holds the conditions of the siblings of a prioritized branch, a value meeting none of them meets the
+// condition too, the same way the tree routes the values meeting no condition of the children.
+type RuleCondition struct {
+	Condition    tree.Condition
+	MatchMissing bool
+	Otherwise    []tree.Condition
+}
+
+func (c *RuleCondition) IsMet(instance *data.Instance) bool {
+	val := instance.GetValueByAttr(c.Condition.Attr())
+	if val == nil || val.IsMissing() {
+		return c.MatchMissing
+	}
+	if c.Condition.IsMet(val) {
+		return true
+	}
+	if len(c.Otherwise) == 0 {
+		return false
+	}
+	for _, other := range c.Otherwise {
+		if other.IsMet(val) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *RuleCondition) Log() string {
+	var alternatives []string
+	if c.MatchMissing {
+		alternatives = append(alternatives, "missing")
+	}
+	if len(c.Otherwise) > 0 {
+		var others []string
+		for _, other := range c.Otherwise {
+			others = append(others, other.Log())
+		}
+		alternatives = append(alternatives, "none of ["+strings.Join(others, "; ")+"]")
+	}
+	if len(alternatives) == 0 {
+		return c.Condition.Log()
+	}
+	return c.Condition.Log() + " (or " + strings.Join(alternatives, ", or ") + ")"
+}
+
+// Rule is an IF-THEN rule: if all conditions are met, predict the class.
+type Rule struct {
+	Conditions []*RuleCondition
+	Class      string
+
+	Coverage   int     // number of training instances meeting all conditions
+	ErrorCount int     // number of covered training instances whose class is not the rule class
+	Confidence float64 // 1 - pessimistic error
+}
+
+func (r *Rule) IsMet(instance *data.Instance) bool {
+	for _, c := range r.Conditions {
+		if !c.IsMet(instance) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Rule) Log() string {
+	if len(r.Conditions) == 0 {
+		return "IF true THEN " + r.Class
+	}
+	var conditions []string
+	for _, c := range r.Conditions {
+		conditions = append(conditions, c.Log())
+	}
+	return "IF " + strings.Join(conditions, " AND ") + " THEN " + r.Class
+}
+
+// RuleSet is an ordered list of rules, the first rule met decides the prediction.
+// If no rule is met, the default class is predicted.
+// The instances are transformed by the pipeline of the tree the rules are extracted from, if any, before the
+// rules are checked.
+type RuleSet struct {
+	Attributes   []data.Attribute
+	Rules        []*Rule
+	DefaultClass string
+	Pipeline     *transform.Pipeline
+}
+
+// InputAttributes returns the attributes of the instances to predict, which are the input attributes of the
+// pipeline if the rule set has one.
+func (r *RuleSet) InputAttributes() []data.Attribute {
+	if r.Pipeline == nil {
+		return r.Attributes
+	}
+	return r.Pipeline.InputAttributes()
+}
+
+// MatchRule returns the first rule met by the instance, or nil if no rule is met.
+func (r *RuleSet) MatchRule(instance *data.Instance) (*Rule, error) {
+	if r.Pipeline != nil {
+		var err error
+		if instance, err = r.Pipeline.Transform(instance); err != nil {
+			return nil, fmt.Errorf("failed to transform instance: %w", err)
+		}
+	}
+	return r.matchRule(instance), nil
+}
+
+// matchRule is MatchRule of an instance already transformed by the pipeline.
+func (r *RuleSet) matchRule(instance *data.Instance) *Rule {
+	for _, rule := range r.Rules {
+		if rule.IsMet(instance) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func (r *RuleSet) Predict(instance *data.Instance) (string, error) {
+	rule, err := r.MatchRule(instance)
+	if err != nil {
+		return "", err
+	}
+	if rule != nil {
+		return rule.Class, nil
+	}
+	if r.DefaultClass == "" {
+		return "", fmt.Errorf("no rule is met and no default class")
+	}
+	return r.DefaultClass, nil
+}
+
+func (r *RuleSet) String() string {
+	var sb strings.Builder
+	for i, rule := range r.Rules {
+		sb.WriteString(fmt.Sprintf("%d. %s (coverage=%d, errors=%d, confidence=%.2f%%)\n", i+1, rule.Log(), rule.Coverage, rule.ErrorCount, rule.Confidence*100))
+	}
+	sb.WriteString(fmt.Sprintf("%d. OTHERWISE %s\n", len(r.Rules)+1, r.DefaultClass))
+	return sb.String()
+}
@@ -0,0 +1,125 @@
+package rules
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/transform"
+	"DecisionTree/tree"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+func ReadRuleSetFromFile(filepath string) (*RuleSet, error) {
+	bytes, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var pr PersistentRuleSet
+	if err := json.Unmarshal(bytes, &pr); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+
+	return pr.ToRuleSet()
+}
+
+func WriteRuleSetToFile(ruleSet *RuleSet, filepath string) error {
+	bytes, err := json.Marshal(NewPersistentRuleSet(ruleSet))
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if err := os.WriteFile(filepath, bytes, 0644); err != nil {
+		return fmt.Errorf("failed to write to file: %w", err)
+	}
+	return nil
+}
+
+type PersistentRuleSet struct {
+	Attributes   []*data.PersistentAttribute `json:"attributes"`
+	Rules        []*PersistentRule           `json:"rules"`
+	DefaultClass string                      `json:"default_class"`
+	Pipeline     *transform.Pipeline         `json:"pipeline,omitempty"`
+}
+
+type PersistentRule struct {
+	Conditions []*PersistentRuleCondition `json:"conditions"`
+	Class      string                     `json:"class"`
+	Coverage   int                        `json:"coverage"`
+	ErrorCount int                        `json:"error_count"`
+	Confidence float64                    `json:"confidence"`
+}
+
+type PersistentRuleCondition struct {
+	Condition    *tree.PersistentCondition   `json:"condition"`
+	MatchMissing bool                        `json:"match_missing,omitempty"`
+	Otherwise    []*tree.PersistentCondition `json:"otherwise,omitempty"`
+}
+
+func NewPersistentRuleSet(ruleSet *RuleSet) *PersistentRuleSet {
+	var attrList []*data.PersistentAttribute
+	for _, attr := range ruleSet.Attributes {
+		attrList = append(attrList, data.NewPersistentAttribute(attr))
+	}
+	res := &PersistentRuleSet{
+		Attributes:   attrList,
+		DefaultClass: ruleSet.DefaultClass,
+		Pipeline:     ruleSet.Pipeline,
+	}
+	for _, rule := range ruleSet.Rules {
+		pRule := &PersistentRule{
+			Class:      rule.Class,
+			Coverage:   rule.Coverage,
+			ErrorCount: rule.ErrorCount,
+			Confidence: rule.Confidence,
+		}
+		for _, c := range rule.Conditions {
+			pCondition := &PersistentRuleCondition{
+				Condition:    tree.NewPersistentCondition(attrList, c.Condition),
+				MatchMissing: c.MatchMissing,
+			}
+			for _, other := range c.Otherwise {
+				pCondition.Otherwise = append(pCondition.Otherwise, tree.NewPersistentCondition(attrList, other))
+			}
+			pRule.Conditions = append(pRule.Conditions, pCondition)
+		}
+		res.Rules = append(res.Rules, pRule)
+	}
+	return res
+}
+
+func (p *PersistentRuleSet) ToRuleSet() (*RuleSet, error) {
+	res := &RuleSet{DefaultClass: p.DefaultClass, Pipeline: p.Pipeline}
+	for _, attr := range p.Attributes {
+		attrInst, err := attr.ToAttribute()
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore attribute '%s': %w", attr.Name, err)
+		}
+		res.Attributes = append(res.Attributes, attrInst)
+	}
+	for i, pRule := range p.Rules {
+		rule := &Rule{
+			Class:      pRule.Class,
+			Coverage:   pRule.Coverage,
+			ErrorCount: pRule.ErrorCount,
+			Confidence: pRule.Confidence,
+		}
+		for _, c := range pRule.Conditions {
+			cond := c.Condition.ToCondition(res.Attributes)
+			if cond == nil {
+				return nil, fmt.Errorf("failed to restore condition of rule %d", i)
+			}
+			ruleCondition := &RuleCondition{Condition: cond, MatchMissing: c.MatchMissing}
+			for _, pOther := range c.Otherwise {
+				other := pOther.ToCondition(res.Attributes)
+				if other == nil {
+					return nil, fmt.Errorf("failed to restore condition of rule %d", i)
+				}
+				ruleCondition.Otherwise = append(ruleCondition.Otherwise, other)
+			}
+			rule.Conditions = append(rule.Conditions, ruleCondition)
+		}
+		res.Rules = append(res.Rules, rule)
+	}
+	return res, nil
+}
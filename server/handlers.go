@@ -0,0 +1,210 @@
+package server
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/drift"
+	"DecisionTree/tree"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type Row map[string]interface{}
+
+type PredictRequest struct {
+	Instance      Row  `json:"instance"`
+	Probabilities bool `json:"probabilities,omitempty"`
+	Path          bool `json:"path,omitempty"`
+	Warnings      bool `json:"warnings,omitempty"` // drift warnings against the training data
+}
+
+type BatchPredictRequest struct {
+	Instances     []Row `json:"instances"`
+	Probabilities bool  `json:"probabilities,omitempty"`
+	Path          bool  `json:"path,omitempty"`
+	Warnings      bool  `json:"warnings,omitempty"`
+}
+
+type PredictResponse struct {
+	Class         string              `json:"class,omitempty"`
+	Probabilities map[string]float64  `json:"probabilities,omitempty"`
+	Path          []*tree.ExplainStep `json:"path,omitempty"`
+	Warnings      []string            `json:"warnings,omitempty"`
+	Error         string              `json:"error,omitempty"` // only used in batch responses
+}
+
+type BatchPredictResponse struct {
+	Predictions []*PredictResponse `json:"predictions"`
+}
+
+type ModelMetadata struct {
+	Name          string                      `json:"name"`
+	Path          string                      `json:"path"`
+	ModifiedAt    time.Time                   `json:"modified_at"`
+	LoadedAt      time.Time                   `json:"loaded_at"`
+	NodeCount     int                         `json:"node_count"`
+	LeafNodeCount int                         `json:"leaf_node_count"`
+	MaxDepth      int                         `json:"max_depth"`
+	Attributes    []*data.PersistentAttribute `json:"attributes"`
+	Class         *data.PersistentAttribute   `json:"class,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"status": "ok",
+		"models": len(s.listModels()),
+	})
+}
+
+func (s *Server) handleListModels(w http.ResponseWriter, _ *http.Request) {
+	var res []*ModelMetadata
+	for _, m := range s.listModels() {
+		res = append(res, m.metadata())
+	}
+	writeJson(w, http.StatusOK, res)
+}
+
+func (s *Server) handleGetModel(w http.ResponseWriter, r *http.Request) {
+	m := s.getModel(r.PathValue("name"))
+	if m == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("model '%s' not found", r.PathValue("name")))
+		return
+	}
+	writeJson(w, http.StatusOK, m.metadata())
+}
+
+func (s *Server) handlePredict(w http.ResponseWriter, r *http.Request) {
+	m := s.getModel(r.PathValue("name"))
+	if m == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("model '%s' not found", r.PathValue("name")))
+		return
+	}
+	var req PredictRequest
+	if !s.decodeRequest(w, r, &req) {
+		return
+	}
+	res, err := s.predict(m.tree, req.Instance, req.Probabilities, req.Path, req.Warnings)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJson(w, http.StatusOK, res)
+}
+
+func (s *Server) handlePredictBatch(w http.ResponseWriter, r *http.Request) {
+	m := s.getModel(r.PathValue("name"))
+	if m == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("model '%s' not found", r.PathValue("name")))
+		return
+	}
+	var req BatchPredictRequest
+	if !s.decodeRequest(w, r, &req) {
+		return
+	}
+	res := &BatchPredictResponse{}
+	for i, row := range req.Instances {
+		pred, err := s.predict(m.tree, row, req.Probabilities, req.Path, req.Warnings)
+		if err != nil {
+			pred = &PredictResponse{Error: fmt.Sprintf("instance %d: %v", i, err)}
+		}
+		res.Predictions = append(res.Predictions, pred)
+	}
+	writeJson(w, http.StatusOK, res)
+}
+
+// decodeRequest decodes the JSON body of the request, which is limited to MaxBodyBytes. If it fails, the error is
+// written and false is returned.
+func (s *Server) decodeRequest(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, s.MaxBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		status := http.StatusBadRequest
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, fmt.Errorf("failed to decode request: %w", err))
+		return false
+	}
+	return true
+}
+
+func (s *Server) predict(tr *tree.Tree, row Row, withProbabilities, withPath, withWarnings bool) (*PredictResponse, error) {
+	instance, err := s.parseRow(tr, row)
+	if err != nil {
+		return nil, err
+	}
+	// transform once, to predict and explain
+	transformed, err := tr.Transform(instance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to predict: %w", err)
+	}
+	path, err := tr.PredictTransformedPath(transformed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to predict: %w", err)
+	}
+	// the leaves of model trees predict by the values of the instance as well
+	res := &PredictResponse{Class: tr.PathPrediction(path, transformed)}
+	if withProbabilities {
+		res.Probabilities = tr.PathProbabilities(path, transformed)
+	}
+	if withPath {
+		explanation, err := tr.ExplainTransformed(transformed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to explain: %w", err)
+		}
+		res.Path = explanation.Steps
+	}
+	if withWarnings {
+		res.Warnings = drift.InstanceWarnings(tr, instance)
+	}
+	return res, nil
+}
+
+// parseRow converts a JSON row into an instance following the input attribute schema of the tree.
+// JSON null and absent attributes are treated as missing values.
+func (s *Server) parseRow(tr *tree.Tree, row Row) (*data.Instance, error) {
+	strRow, err := data.StringRowFromJson(row)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := data.ParseInstance(s.conf, tr.InputAttributes(), tr.Class, strRow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse instance: %w", err)
+	}
+	return instance, nil
+}
+
+func (m *model) metadata() *ModelMetadata {
+	res := &ModelMetadata{
+		Name:          m.name,
+		Path:          m.path,
+		ModifiedAt:    m.modTime,
+		LoadedAt:      m.loadedAt,
+		NodeCount:     m.tree.GetNodeCount(),
+		LeafNodeCount: len(m.tree.GetLeafNodes()),
+		MaxDepth:      m.tree.GetMaxDepth(),
+	}
+	for _, attr := range m.tree.InputAttributes() {
+		res.Attributes = append(res.Attributes, data.NewPersistentAttribute(attr))
+	}
+	if m.tree.Class != nil {
+		res.Class = data.NewPersistentAttribute(m.tree.Class)
+	}
+	return res
+}
+
+func writeJson(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJson(w, status, &errorResponse{Error: err.Error()})
+}
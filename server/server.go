@@ -0,0 +1,156 @@
+package server
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/tree"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBodyBytes is the default limit of the size of request bodies.
+const DefaultMaxBodyBytes = 32 << 20
+
+// timeouts of the HTTP server, the write timeout leaves time to predict large batches
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = time.Minute
+	writeTimeout      = 2 * time.Minute
+	idleTimeout       = 2 * time.Minute
+)
+
+// Server serves predictions of one or more trained trees over HTTP.
+type Server struct {
+	conf *config.Config
+
+	MaxBodyBytes int64 // requests with larger bodies are rejected with 413
+
+	mu     sync.RWMutex
+	models map[string]*model
+}
+
+type model struct {
+	name     string
+	path     string
+	tree     *tree.Tree
+	modTime  time.Time
+	loadedAt time.Time
+}
+
+func NewServer(conf *config.Config) *Server {
+	return &Server{
+		conf:         conf,
+		MaxBodyBytes: DefaultMaxBodyBytes,
+		models:       make(map[string]*model),
+	}
+}
+
+// LoadModel reads a tree from the model file and registers it under the given name.
+// Loading a name which is already registered replaces the old model.
+func (s *Server) LoadModel(name, path string) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat model file: %w", err)
+	}
+	tr, err := tree.ReadTreeFromFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read tree: %w", err)
+	}
+	if tr.RootNode == nil {
+		return fmt.Errorf("model file '%s' does not contain a tree", path)
+	}
+	// the requests predict concurrently, which must only read the node ids
+	tr.AssignUniqIds()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.models[name] = &model{
+		name:     name,
+		path:     path,
+		tree:     tr,
+		modTime:  stat.ModTime(),
+		loadedAt: time.Now(),
+	}
+	return nil
+}
+
+func (s *Server) getModel(name string) *model {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.models[name]
+}
+
+func (s *Server) listModels() []*model {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var res []*model
+	for _, m := range s.models {
+		res = append(res, m)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].name < res[j].name
+	})
+	return res
+}
+
+// WatchModels reloads every model whose file has been modified since it was loaded.
+// The files are checked every interval until the context is done.
+func (s *Server) WatchModels(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reloadModifiedModels()
+		}
+	}
+}
+
+func (s *Server) reloadModifiedModels() {
+	for _, m := range s.listModels() {
+		stat, err := os.Stat(m.path)
+		if err != nil {
+			log.Printf("[Serve] Failed to stat model file of '%s': %v", m.name, err)
+			continue
+		}
+		if stat.ModTime().Equal(m.modTime) {
+			continue
+		}
+		// keep serving the old tree if the new file is broken (e.g. still being written)
+		if err := s.LoadModel(m.name, m.path); err != nil {
+			log.Printf("[Serve] Failed to reload model '%s': %v", m.name, err)
+			continue
+		}
+		log.Printf("[Serve] Reloaded model '%s' from %s", m.name, m.path)
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("GET /models", s.handleListModels)
+	mux.HandleFunc("GET /models/{name}", s.handleGetModel)
+	mux.HandleFunc("POST /models/{name}/predict", s.handlePredict)
+	mux.HandleFunc("POST /models/{name}/predict/batch", s.handlePredictBatch)
+	return mux
+}
+
+func (s *Server) ListenAndServe(addr string) error {
+	log.Printf("[Serve] Listening on %s", addr)
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	return httpServer.ListenAndServe()
+}
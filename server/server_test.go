@@ -0,0 +1,249 @@
+package server
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/tree"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testModel = `{
+  "attributes": [
+    {"name": "age", "type": "continuous"},
+    {"name": "color", "type": "nominal", "accepted_values": ["red", "blue"]}
+  ],
+  "class": {"name": "Class", "type": "nominal", "accepted_values": ["yes", "no"]},
+  "root_node": {
+    "uniq_id": 1,
+    "Condition": null,
+    "class_count": {"yes": 6, "no": 4},
+    "Children": [
+      {
+        "uniq_id": 2,
+        "Condition": {"condition_type": "lt", "attr_id": 0, "upper_value": 30},
+        "is_prioritized": true,
+        "leaf_class": "yes",
+        "class_count": {"yes": 5, "no": 1}
+      },
+      {
+        "uniq_id": 3,
+        "Condition": {"condition_type": "ge", "attr_id": 0, "lower_value": 30},
+        "leaf_class": "no",
+        "class_count": {"yes": 1, "no": 3}
+      }
+    ]
+  }
+}`
+
+func newTestServer(t *testing.T) (*Server, string) {
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := os.WriteFile(path, []byte(testModel), 0644); err != nil {
+		t.Fatalf("failed to write model: %v", err)
+	}
+	s := NewServer(&config.Config{ConsiderInvalidDataAsMissing: true})
+	if err := s.LoadModel("m", path); err != nil {
+		t.Fatalf("failed to load model: %v", err)
+	}
+	return s, path
+}
+
+func doRequest(t *testing.T, h http.Handler, method, url string, body interface{}, res interface{}) int {
+	var reqBody bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&reqBody).Encode(body)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, url, &reqBody))
+	if res != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), res); err != nil {
+			t.Fatalf("failed to decode response %s: %v", rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+func TestPredict(t *testing.T) {
+	s, _ := newTestServer(t)
+	h := s.Handler()
+
+	var res PredictResponse
+	code := doRequest(t, h, "POST", "/models/m/predict", &PredictRequest{
+		Instance:      Row{"age": 45, "color": "red"},
+		Probabilities: true,
+		Path:          true,
+	}, &res)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "no", res.Class)
+	assert.InDelta(t, 0.75, res.Probabilities["no"], 1e-9)
+	assert.Equal(t, 2, len(res.Path))
+	assert.Equal(t, "age >= 30.00", res.Path[1].Condition)
+	assert.Equal(t, "45.000000", res.Path[1].Value)
+
+	// missing value goes along the prioritized branch
+	var batch BatchPredictResponse
+	code = doRequest(t, h, "POST", "/models/m/predict/batch", &BatchPredictRequest{
+		Instances: []Row{{"age": nil}, {"age": "12"}, {"age": []int{1}}},
+	}, &batch)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 3, len(batch.Predictions))
+	assert.Equal(t, "yes", batch.Predictions[0].Class)
+	assert.Equal(t, "yes", batch.Predictions[1].Class)
+	assert.NotEmpty(t, batch.Predictions[2].Error)
+
+	code = doRequest(t, h, "POST", "/models/m/predict", &PredictRequest{Instance: Row{}, Path: true}, &res)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, res.Path[1].MissingValueFallback)
+
+	assert.Equal(t, http.StatusNotFound, doRequest(t, h, "POST", "/models/unknown/predict", &PredictRequest{}, nil))
+
+	// request bodies are limited
+	s.MaxBodyBytes = 64
+	var errRes errorResponse
+	code = doRequest(t, h, "POST", "/models/m/predict/batch", &BatchPredictRequest{
+		Instances: []Row{{"age": 45, "color": "red"}, {"age": 45, "color": "red"}, {"age": 45, "color": "red"}},
+	}, &errRes)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+	assert.Contains(t, errRes.Error, "request body too large")
+}
+
+func TestPredictWarnings(t *testing.T) {
+	s, _ := newTestServer(t)
+	h := s.Handler()
+
+	// the test model has no training stats
+	var res PredictResponse
+	code := doRequest(t, h, "POST", "/models/m/predict", &PredictRequest{Instance: Row{"age": 99, "color": "green"}, Warnings: true}, &res)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, res.Warnings)
+
+	s.getModel("m").tree.TrainingStats = &tree.TrainingStats{
+		Attributes: map[string]*tree.AttributeStats{
+			"age":   {Min: 18, Max: 90},
+			"color": {Values: map[string]float64{"red": 1}},
+		},
+	}
+	code = doRequest(t, h, "POST", "/models/m/predict", &PredictRequest{Instance: Row{"age": 99, "color": "green"}, Warnings: true}, &res)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "no", res.Class)
+	assert.Equal(t, []string{
+		"attribute 'age': value 99 outside the training range [18, 90]",
+		"attribute 'color': invalid value 'green' considered as missing",
+	}, res.Warnings)
+
+	var batch BatchPredictResponse
+	code = doRequest(t, h, "POST", "/models/m/predict/batch", &BatchPredictRequest{
+		Instances: []Row{{"age": 20, "color": "blue"}, {"age": 20, "color": "red"}},
+		Warnings:  true,
+	}, &batch)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"attribute 'color': value 'blue' never seen in training"}, batch.Predictions[0].Warnings)
+	assert.Empty(t, batch.Predictions[1].Warnings)
+}
+
+func TestPredictWithPipeline(t *testing.T) {
+	// the tree splits on "years", which the pipeline renames from "age"
+	model := strings.Replace(testModel, `"attributes": [
+    {"name": "age", "type": "continuous"},`, `"pipeline": {
+    "input": [{"name": "age", "type": "continuous"}, {"name": "color", "type": "nominal", "accepted_values": ["red", "blue"]}],
+    "steps": [{"type": "rename", "params": {"column": "age", "to": "years"}}]
+  },
+  "attributes": [
+    {"name": "years", "type": "continuous"},`, 1)
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := os.WriteFile(path, []byte(model), 0644); err != nil {
+		t.Fatalf("failed to write model: %v", err)
+	}
+	s := NewServer(&config.Config{ConsiderInvalidDataAsMissing: true})
+	if err := s.LoadModel("m", path); err != nil {
+		t.Fatalf("failed to load model: %v", err)
+	}
+	h := s.Handler()
+
+	var res PredictResponse
+	code := doRequest(t, h, "POST", "/models/m/predict", &PredictRequest{Instance: Row{"age": 45}, Path: true}, &res)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "no", res.Class)
+	assert.Equal(t, "years >= 30.00", res.Path[1].Condition)
+
+	var metadata ModelMetadata
+	assert.Equal(t, http.StatusOK, doRequest(t, h, "GET", "/models/m", nil, &metadata))
+	assert.Equal(t, "age", metadata.Attributes[0].Name)
+}
+
+func TestMetadataAndReload(t *testing.T) {
+	s, path := newTestServer(t)
+	h := s.Handler()
+
+	var meta ModelMetadata
+	assert.Equal(t, http.StatusOK, doRequest(t, h, "GET", "/models/m", nil, &meta))
+	assert.Equal(t, 3, meta.NodeCount)
+	assert.Equal(t, 2, meta.LeafNodeCount)
+	assert.Equal(t, []string{"red", "blue"}, meta.Attributes[1].AcceptedValues)
+	assert.Equal(t, "Class", meta.Class.Name)
+
+	// swap the leaf classes and touch the file, the model should be reloaded
+	var pt map[string]interface{}
+	_ = json.Unmarshal([]byte(testModel), &pt)
+	children := pt["root_node"].(map[string]interface{})["Children"].([]interface{})
+	children[0].(map[string]interface{})["leaf_class"] = "no"
+	content, _ := json.Marshal(pt)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("failed to write model: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	_ = os.Chtimes(path, later, later)
+	s.reloadModifiedModels()
+
+	var res PredictResponse
+	doRequest(t, h, "POST", "/models/m/predict", &PredictRequest{Instance: Row{"age": 20}}, &res)
+	assert.Equal(t, "no", res.Class)
+
+	var health map[string]interface{}
+	assert.Equal(t, http.StatusOK, doRequest(t, h, "GET", "/health", nil, &health))
+	assert.Equal(t, "ok", health["status"])
+}
+
+func TestConcurrentPredict(t *testing.T) {
+	// the node ids of a model file without them are assigned when it is loaded, the predictions only read them
+	path := filepath.Join(t.TempDir(), "model.json")
+	model := regexp.MustCompile(`"uniq_id": \d+,`).ReplaceAllString(testModel, "")
+	if err := os.WriteFile(path, []byte(model), 0644); err != nil {
+		t.Fatalf("failed to write model: %v", err)
+	}
+	s := NewServer(&config.Config{ConsiderInvalidDataAsMissing: true})
+	for _, name := range []string{"warm", "m"} {
+		if err := s.LoadModel(name, path); err != nil {
+			t.Fatalf("failed to load model: %v", err)
+		}
+	}
+	h := s.Handler()
+	// warm the caches of the handlers, which would order the requests to the other model
+	assert.Equal(t, http.StatusOK, doRequest(t, h, "POST", "/models/warm/predict", &PredictRequest{Instance: Row{"age": 45}, Path: true}, nil))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var res PredictResponse
+			code := doRequest(t, h, "POST", "/models/m/predict", &PredictRequest{Instance: Row{"age": 45}, Path: true}, &res)
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "no", res.Class)
+			if assert.Equal(t, 2, len(res.Path)) {
+				assert.NotEqual(t, res.Path[0].NodeId, res.Path[1].NodeId)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/dataset"
+	"DecisionTree/tree"
+	"testing"
+)
+
+// buildAdultTree builds a tree on the adult training data with a config fast enough for tests, and returns it with
+// the adult testing data.
+func buildAdultTree(t *testing.T) (*tree.Tree, *data.ValueTable) {
+	var (
+		attributesFile = "../dataset/adult.names"
+		trainDataFile  = "../dataset/adult.data"
+		testDataFile   = "../dataset/adult.test"
+		conf           = &config.Config{
+			ConsiderInvalidDataAsMissing: true,
+			MaxDepth:                     50,
+			MinSamplesSplit:              32,
+			MinSamplesLeaf:               8,
+			MinImpurityDecrease:          0.1,
+			MaxNominalBruteForceScale:    4,
+		}
+	)
+	attrTable, err := data.ReadAttributes(attributesFile)
+	if err != nil {
+		t.Fatalf("failed to read attributes: %v", err)
+	}
+	trainData, err := data.ReadValues(conf, attrTable, trainDataFile)
+	if err != nil {
+		t.Fatalf("failed to read training data: %v", err)
+	}
+	dataset.PreProcessData(trainData)
+	testData, err := data.ReadValues(conf, attrTable, testDataFile)
+	if err != nil {
+		t.Fatalf("failed to read testing data: %v", err)
+	}
+	dataset.PreProcessData(testData)
+
+	tr, err := tree.BuildTree(conf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	return tr, testData
+}
+
+// yesNo is the class values of most of the data sets built by newTestData.
+var yesNo = []string{"yes", "no"}
+
+// newTestData parses the rows, which map the names of the attributes and "Class" to their values, into a data set of
+// the attributes and a class attribute "Class" of the classes. It returns the data set with a config to train small
+// trees on it, down to single instances, which the tests change as they need.
+func newTestData(t *testing.T, attrs []data.Attribute, classes []string, rows []map[string]string) (*data.ValueTable, *config.Config) {
+	var (
+		conf = &config.Config{
+			ConsiderInvalidDataAsMissing: true,
+			MaxDepth:                     5,
+			MinSamplesSplit:              2,
+			MinSamplesLeaf:               1,
+			MaxNominalBruteForceScale:    4,
+		}
+		classAttr = data.NewNominalAttribute("Class", classes)
+		res       = &data.ValueTable{}
+	)
+	for _, row := range rows {
+		instance, err := data.ParseInstance(conf, attrs, classAttr, row)
+		if err != nil {
+			t.Fatalf("failed to parse instance %v: %v", row, err)
+		}
+		res.Instances = append(res.Instances, instance)
+	}
+	return res, conf
+}
+
+// testClass returns the class attribute of a data set built by newTestData.
+func testClass(dataTable *data.ValueTable) *data.NominalAttribute {
+	return dataTable.Instances[0].ClassValue.Attribute().(*data.NominalAttribute)
+}
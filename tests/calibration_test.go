@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/evaluation"
+	"DecisionTree/tree"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// newCalibrationTestData returns the tree: x < 10 (prioritized) -> a {a: 3, b: 1}, x >= 10 -> b {b: 3, c: 1},
+// and a validation data set of 3 instances reaching each leaf.
+func newCalibrationTestData(t *testing.T) (*tree.Tree, *data.ValueTable) {
+	x := data.NewContinuousAttribute("x")
+	validation, _ := newTestData(t, []data.Attribute{x}, []string{"a", "b", "c", "d"}, []map[string]string{
+		{"x": "1", "Class": "a"},
+		{"x": "2", "Class": "b"},
+		{"x": "3", "Class": "b"},
+		{"x": "11", "Class": "b"},
+		{"x": "12", "Class": "c"},
+		{"x": "13", "Class": "d"},
+	})
+	tr := &tree.Tree{
+		Attributes: []data.Attribute{x},
+		Class:      testClass(validation),
+		RootNode: &tree.Node{ClassCount: map[string]float64{"a": 3, "b": 4, "c": 1}, Children: []*tree.Node{
+			{Condition: tree.NewLessThanCondition(x, 10), IsPrioritized: true, LeafClass: "a", ClassCount: map[string]float64{"a": 3, "b": 1}},
+			{Condition: tree.NewGreaterThanEqCondition(x, 10), LeafClass: "b", ClassCount: map[string]float64{"b": 3, "c": 1}},
+		}},
+	}
+	return tr, validation
+}
+
+func assertProbabilities(t *testing.T, expected, actual map[string]float64) {
+	t.Helper()
+	var sum float64
+	for class, p := range actual {
+		if math.Abs(p-expected[class]) > 1e-6 {
+			t.Errorf("probability of %s: expected %v, got %v", class, expected[class], p)
+		}
+		sum += p
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("probabilities sum up to %v", sum)
+	}
+}
+
+func TestLaplaceCalibration(t *testing.T) {
+	tr, validation := newCalibrationTestData(t)
+	if _, err := tree.FitCalibrator(tr, nil, &tree.CalibrationOptions{Method: tree.MEstimateCalibration}); err == nil {
+		t.Errorf("expected an error fitting m-estimate without validation data and m")
+	}
+
+	calibrator, err := tree.FitCalibrator(tr, nil, &tree.CalibrationOptions{Method: tree.LaplaceCalibration})
+	if err != nil {
+		t.Fatalf("failed to fit calibrator: %v", err)
+	}
+	tr.Calibrator = calibrator
+	probabilities, err := tr.PredictProba(validation.Instances[0])
+	if err != nil {
+		t.Fatalf("failed to predict: %v", err)
+	}
+	assertProbabilities(t, map[string]float64{"a": 0.5, "b": 0.25, "c": 0.125, "d": 0.125}, probabilities)
+
+	// priors are the class distribution of the root node
+	calibrator, err = tree.FitCalibrator(tr, nil, &tree.CalibrationOptions{Method: tree.MEstimateCalibration, M: 4})
+	if err != nil {
+		t.Fatalf("failed to fit calibrator: %v", err)
+	}
+	assertProbabilities(t, map[string]float64{"a": 4.5 / 8, "b": 3.0 / 8, "c": 0.5 / 8, "d": 0}, calibrator.Calibrate(tr.RootNode.Children[0]))
+
+	calibrator, err = tree.FitCalibrator(tr, validation, &tree.CalibrationOptions{Method: tree.MEstimateCalibration})
+	if err != nil {
+		t.Fatalf("failed to fit calibrator: %v", err)
+	}
+	if calibrator.M <= 0 {
+		t.Errorf("expected m to be chosen on the validation data, got %v", calibrator.M)
+	}
+}
+
+func TestIsotonicCalibration(t *testing.T) {
+	tr, validation := newCalibrationTestData(t)
+	calibrator, err := tree.FitCalibrator(tr, validation, &tree.CalibrationOptions{Method: tree.IsotonicCalibration})
+	if err != nil {
+		t.Fatalf("failed to fit calibrator: %v", err)
+	}
+	// b has frequency 2/3 at score 0.25 and 1/3 at score 0.75, which are pooled into 1/2
+	assertProbabilities(t, map[string]float64{"a": 1.0 / 3, "b": 0.5, "c": 0, "d": 1.0 / 6}, calibrator.Calibrate(tr.RootNode.Children[0]))
+	if got := calibrator.Isotonic["b"].Predict(0.5); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("expected isotonic regression of b to be flat, got %v at 0.5", got)
+	}
+}
+
+func TestPlattCalibration(t *testing.T) {
+	tr, validation := newCalibrationTestData(t)
+	if _, err := tree.FitCalibrator(tr, nil, &tree.CalibrationOptions{Method: tree.PlattCalibration}); err == nil {
+		t.Errorf("expected an error fitting Platt scaling without validation data")
+	}
+	calibrator, err := tree.FitCalibrator(tr, validation, &tree.CalibrationOptions{Method: tree.PlattCalibration})
+	if err != nil {
+		t.Fatalf("failed to fit calibrator: %v", err)
+	}
+	tr.Calibrator = calibrator
+	for _, leaf := range tr.GetLeafNodes() {
+		probabilities := tr.LeafProbabilities(leaf)
+		var sum float64
+		for _, class := range []string{"a", "b", "c", "d"} {
+			if probabilities[class] <= 0 || probabilities[class] >= 1 {
+				t.Errorf("expected sigmoid probability of %s in (0, 1), got %v", class, probabilities[class])
+			}
+			sum += probabilities[class]
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Errorf("probabilities sum up to %v", sum)
+		}
+	}
+}
+
+func TestCalibratorSerialization(t *testing.T) {
+	tr, validation := newCalibrationTestData(t)
+	calibrator, err := tree.FitCalibrator(tr, validation, &tree.CalibrationOptions{Method: tree.IsotonicCalibration})
+	if err != nil {
+		t.Fatalf("failed to fit calibrator: %v", err)
+	}
+	tr.Calibrator = calibrator
+	tr.Threshold = &tree.DecisionThreshold{PositiveClass: "b", Threshold: 0.5}
+
+	modelFile := filepath.Join(t.TempDir(), "tree.json")
+	if err := tree.WriteTreeToFile(tr, modelFile); err != nil {
+		t.Fatalf("failed to write tree: %v", err)
+	}
+	restored, err := tree.ReadTreeFromFile(modelFile)
+	if err != nil {
+		t.Fatalf("failed to read tree: %v", err)
+	}
+	for i, instance := range validation.Instances {
+		expected, _ := tr.PredictProba(instance)
+		actual, _ := restored.PredictProba(instance)
+		assertProbabilities(t, expected, actual)
+		expectedClass, _ := tr.Predict(instance)
+		actualClass, _ := restored.Predict(instance)
+		if expectedClass != actualClass || expectedClass != "b" {
+			t.Errorf("instance %d: expected b, got %s and %s", i, expectedClass, actualClass)
+		}
+	}
+}
+
+func TestAdultCalibration(t *testing.T) {
+	tr, testData := buildAdultTree(t)
+
+	// instances are grouped by class, alternate them into a validation set and a holdout set
+	validation, holdout := &data.ValueTable{}, &data.ValueTable{}
+	for i, instance := range testData.Instances {
+		if i%2 == 0 {
+			validation.Instances = append(validation.Instances, instance)
+		} else {
+			holdout.Instances = append(holdout.Instances, instance)
+		}
+	}
+
+	raw, err := evaluation.Evaluate(tr, holdout)
+	if err != nil {
+		t.Fatalf("failed to evaluate: %v", err)
+	}
+	for _, method := range []tree.CalibrationMethod{tree.LaplaceCalibration, tree.MEstimateCalibration, tree.PlattCalibration, tree.IsotonicCalibration} {
+		calibrator, err := tree.FitCalibrator(tr, validation, &tree.CalibrationOptions{Method: method})
+		if err != nil {
+			t.Fatalf("failed to fit %s calibrator: %v", method, err)
+		}
+		calibrated := tr.Copy()
+		calibrated.Calibrator = calibrator
+		report, err := evaluation.Evaluate(calibrated, holdout)
+		if err != nil {
+			t.Fatalf("failed to evaluate: %v", err)
+		}
+		t.Logf("%s: log-loss %.4f -> %.4f, Brier score %.4f -> %.4f", method, raw.LogLoss, report.LogLoss, raw.BrierScore, report.BrierScore)
+		if method == tree.PlattCalibration && report.LogLoss >= raw.LogLoss {
+			t.Errorf("expected Platt scaling to reduce log-loss, got %v -> %v", raw.LogLoss, report.LogLoss)
+		}
+	}
+
+	threshold, err := evaluation.SelectThreshold(tr, validation, ">50K", evaluation.MaxF1Criterion, 0)
+	if err != nil {
+		t.Fatalf("failed to select threshold: %v", err)
+	}
+	tuned := tr.Copy()
+	tuned.Threshold = threshold
+	before, err := tree.TestRun(tr, holdout)
+	if err != nil {
+		t.Fatalf("failed to do test run: %v", err)
+	}
+	after, err := tree.TestRun(tuned, holdout)
+	if err != nil {
+		t.Fatalf("failed to do test run: %v", err)
+	}
+	t.Logf("threshold %.4f: F1 of >50K %.4f -> %.4f", threshold.Threshold, before.ClassF1[">50K"], after.ClassF1[">50K"])
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"math"
+	"strconv"
+	"testing"
+)
+
+// newMissingSplitData returns the values 1 to 50 of attribute x, of class "yes" up to 20 and "no" above, and 20
+// instances missing x, half of them "yes", with a config of depth 2.
+func newMissingSplitData(t *testing.T) (*data.ValueTable, *config.Config) {
+	var rows []map[string]string
+	for i := 1; i <= 70; i++ {
+		value, class := strconv.Itoa(i), "yes"
+		switch {
+		case i > 50:
+			value = "?"
+			if i%2 == 0 {
+				class = "no"
+			}
+		case i > 20:
+			class = "no"
+		}
+		rows = append(rows, map[string]string{"x": value, "Class": class})
+	}
+	trainData, conf := newTestData(t, []data.Attribute{data.NewContinuousAttribute("x")}, yesNo, rows)
+	conf.MaxDepth = 2
+	return trainData, conf
+}
+
+func TestContinuousSplitWithMissingValues(t *testing.T) {
+	trainData, conf := newMissingSplitData(t)
+	tr, err := tree.BuildTree(conf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	if len(tr.RootNode.Children) != 2 {
+		t.Fatalf("expected the root to split in two, got %d children", len(tr.RootNode.Children))
+	}
+	condition, ok := tr.RootNode.Children[0].Condition.(*tree.ContinuousCondition)
+	if !ok || condition.UpperValue() != 20.5 {
+		t.Errorf("expected the split x < 20.5, got %s", tr.RootNode.Children[0].Condition.Log())
+	}
+	// the children are pure, so the gain is the entropy of the node (30 yes and 40 no), scaled by the 50 of the 70
+	// instances knowing x
+	p := 30.0 / 70
+	expected := -(p*math.Log2(p) + (1-p)*math.Log2(1-p)) * 50 / 70
+	if math.Abs(tr.RootNode.SplitGain-expected) > 1e-9 {
+		t.Errorf("expected split gain %f, got %f", expected, tr.RootNode.SplitGain)
+	}
+
+	// the instances missing x follow the 20 instances of the left child and the 30 of the right child
+	left, right := tr.RootNode.Children[0], tr.RootNode.Children[1]
+	if !right.IsPrioritized || left.IsPrioritized {
+		t.Errorf("expected the right child to be prioritized")
+	}
+	for node, expected := range map[*tree.Node]map[string]float64{
+		left:  {"yes": 20 + 10*0.4, "no": 10 * 0.4},
+		right: {"yes": 10 * 0.6, "no": 30 + 10*0.6},
+	} {
+		for class, count := range expected {
+			if math.Abs(node.ClassCount[class]-count) > 1e-9 {
+				t.Errorf("%s: expected %.1f %s, got %.2f", node.Condition.Log(), count, class, node.ClassCount[class])
+			}
+		}
+	}
+}
+
+func TestContinuousSplitOfUnsortedInstances(t *testing.T) {
+	// the values 1 to 40 in a scrambled order, of class "yes" up to 15
+	var rows []map[string]string
+	for i := 0; i < 40; i++ {
+		value, class := i*17%40+1, "yes"
+		if value > 15 {
+			class = "no"
+		}
+		rows = append(rows, map[string]string{"x": strconv.Itoa(value), "Class": class})
+	}
+	trainData, conf := newTestData(t, []data.Attribute{data.NewContinuousAttribute("x")}, yesNo, rows)
+	conf.MaxDepth = 2
+
+	tr, err := tree.BuildTree(conf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	if len(tr.RootNode.Children) != 2 {
+		t.Fatalf("expected the root to split in two, got %d children", len(tr.RootNode.Children))
+	}
+	// the thresholds are searched in the order of the values, not of the instances
+	condition, ok := tr.RootNode.Children[0].Condition.(*tree.ContinuousCondition)
+	if !ok || condition.UpperValue() != 15.5 {
+		t.Errorf("expected the split x < 15.5, got %s", tr.RootNode.Children[0].Condition.Log())
+	}
+	if expected := tr.RootNode.Entropy(); math.Abs(tr.RootNode.SplitGain-expected) > 1e-9 {
+		t.Errorf("expected split gain %f, got %f", expected, tr.RootNode.SplitGain)
+	}
+	for _, child := range tr.RootNode.Children {
+		if len(child.Children) != 0 || child.Entropy() != 0 {
+			t.Errorf("%s: expected a pure leaf", child.Condition.Log())
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/export"
+	"DecisionTree/tree"
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newEventData returns an event a day from 2020-01-01 for 2 years, of which the class is given by its time.
+func newEventData(t *testing.T, class func(time.Time) string) (*data.ValueTable, *config.Config) {
+	var (
+		rows  []map[string]string
+		start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+	for day := 0; day < 730; day++ {
+		date := start.AddDate(0, 0, day)
+		rows = append(rows, map[string]string{"time": date.Format("2006-01-02"), "Class": class(date)})
+	}
+	return newTestData(t, []data.Attribute{data.NewDateTimeAttribute("time", nil)}, yesNo, rows)
+}
+
+func TestDateTimeSplit(t *testing.T) {
+	trainData, conf := newEventData(t, func(date time.Time) string {
+		if date.Before(time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC)) {
+			return "yes"
+		}
+		return "no"
+	})
+	tr, err := tree.BuildTree(conf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	// the threshold is the midpoint between the days
+	if log := tr.RootNode.Children[0].Condition.Log(); log != "time < 2021-03-14 12:00:00" {
+		t.Errorf("expected the condition time < 2021-03-14 12:00:00, got %s", log)
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteSQL(&buf, tr, nil); err != nil {
+		t.Fatalf("failed to write sql: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"time" >= TIMESTAMP '2021-03-14 12:00:00'`) {
+		t.Errorf("expected the sql to compare with a datetime literal, got %s", buf.String())
+	}
+	buf.Reset()
+	if err := export.WritePMML(&buf, tr, nil); err != nil {
+		t.Fatalf("failed to write pmml: %v", err)
+	}
+	imported, err := export.ReadPMML(&buf)
+	if err != nil {
+		t.Fatalf("failed to read pmml: %v", err)
+	}
+	for _, instance := range trainData.Instances {
+		expected, _ := tr.Predict(instance)
+		if actual, err := imported.Predict(instance); err != nil || actual != expected {
+			t.Fatalf("expected the imported pmml to predict %s for %s, got %s (%v)", expected, instance.AttributeValues[0].Log(), actual, err)
+		}
+	}
+}
+
+func TestDateTimeParts(t *testing.T) {
+	trainData, conf := newEventData(t, func(date time.Time) string {
+		if date.Month() >= time.June && date.Month() <= time.August {
+			return "yes"
+		}
+		return "no"
+	})
+	conf.DateTimeParts = []string{"year", "month", "weekday"}
+	tr, err := tree.BuildTree(conf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	res, err := tree.TestRun(tr, trainData)
+	if err != nil {
+		t.Fatalf("failed to test run: %v", err)
+	}
+	if res.Accuracy != 1 || len(tr.GetLeafNodes()) != 3 {
+		t.Errorf("expected 3 leaves fitting the summer months, got accuracy %v with %d leaves", res.Accuracy, len(tr.GetLeafNodes()))
+	}
+	if log := tr.RootNode.Children[0].Condition.Log(); !strings.HasPrefix(log, "time.month ") {
+		t.Errorf("expected the root to be split by the month, got %s", log)
+	}
+
+	// the parts are derived from the datetime values of the instances to predict
+	modelFile := filepath.Join(t.TempDir(), "tree.json")
+	if err := tree.WriteTreeToFile(tr, modelFile); err != nil {
+		t.Fatalf("failed to write tree: %v", err)
+	}
+	restored, err := tree.ReadTreeFromFile(modelFile)
+	if err != nil {
+		t.Fatalf("failed to read tree: %v", err)
+	}
+	instance, err := data.ParseInstance(conf, restored.Attributes, restored.Class, map[string]string{"time": "2030-07-04T10:00:00Z"})
+	if err != nil {
+		t.Fatalf("failed to parse instance: %v", err)
+	}
+	if predicted, err := restored.Predict(instance); err != nil || predicted != "yes" {
+		t.Errorf("expected July to be predicted yes, got %s (%v)", predicted, err)
+	}
+
+	// the generated code derives the parts as well
+	var (
+		rows     []map[string]interface{}
+		expected []string
+	)
+	for _, instance := range trainData.Instances[:100] {
+		rows = append(rows, map[string]interface{}{"time": instance.AttributeValues[0].Value().(float64)})
+		predicted, _ := tr.Predict(instance)
+		expected = append(expected, predicted)
+	}
+	for _, row := range rows {
+		row["time"] = tr.Attributes[0].(*data.DateTimeAttribute).Time(row["time"].(float64)).Format(time.RFC3339)
+	}
+	actual := runGoCode(t, tr, rows)
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("row %v: expected %s from the generated code, got %s", rows[i], expected[i], actual[i])
+		}
+	}
+}
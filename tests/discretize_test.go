@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/dataset"
+	"DecisionTree/transform"
+	"DecisionTree/tree"
+	"encoding/json"
+	"strconv"
+	"testing"
+)
+
+// newSplitData returns the values 1 to 10 of attribute x, of class "yes" up to 5 and "no" above.
+func newSplitData(t *testing.T) (*data.ValueTable, *config.Config) {
+	var rows []map[string]string
+	for i := 1; i <= 10; i++ {
+		class := "yes"
+		if i > 5 {
+			class = "no"
+		}
+		rows = append(rows, map[string]string{"x": strconv.Itoa(i), "Class": class})
+	}
+	return newTestData(t, []data.Attribute{data.NewContinuousAttribute("x")}, yesNo, rows)
+}
+
+func TestMDLDiscretize(t *testing.T) {
+	trainData, _ := newSplitData(t)
+	pipeline := transform.NewPipeline(transform.NewDiscretizers(trainData, transform.MDLBins, 0)...)
+	transformed, err := pipeline.Fit(trainData)
+	if err != nil {
+		t.Fatalf("failed to fit pipeline: %v", err)
+	}
+	edges := pipeline.Steps[0].(*transform.MDLDiscretize).Edges
+	if len(edges) != 1 || edges[0] != 5.5 {
+		t.Fatalf("expected the single cut point 5.5, got %v", edges)
+	}
+	if v := transformed.Instances[0].AttributeValues[0].Value(); v != "< 5.5" {
+		t.Errorf("expected x=1 in bin '< 5.5', got %v", v)
+	}
+
+	// the MDL step is restored from JSON like the steps of the transform package
+	bytes, err := json.Marshal(pipeline)
+	if err != nil {
+		t.Fatalf("failed to marshal pipeline: %v", err)
+	}
+	var restored transform.Pipeline
+	if err := json.Unmarshal(bytes, &restored); err != nil {
+		t.Fatalf("failed to unmarshal pipeline: %v", err)
+	}
+	testData, _ := newSplitData(t)
+	res, err := restored.Transform(testData.Instances[9])
+	if err != nil {
+		t.Fatalf("failed to transform: %v", err)
+	}
+	if v := res.AttributeValues[0].Value(); v != ">= 5.5" {
+		t.Errorf("expected x=10 in bin '>= 5.5', got %v", v)
+	}
+
+	// a single class is not worth splitting
+	single, _ := newSplitData(t)
+	single.Instances = single.Instances[:5]
+	step := &transform.MDLDiscretize{Column: "x"}
+	if _, err := transform.NewPipeline(step).Fit(single); err != nil {
+		t.Fatalf("failed to fit pipeline: %v", err)
+	}
+	if len(step.Edges) != 0 {
+		t.Errorf("expected no cut point of a single class, got %v", step.Edges)
+	}
+}
+
+func TestContinuousSplitCandidates(t *testing.T) {
+	for _, tc := range []struct {
+		candidates string
+		threshold  float64
+	}{
+		{"", 5.5},
+		{"mdl", 5.5},
+		{"frequency", 6}, // the median is the single edge of 2 bins
+		{"width", 5.5},
+	} {
+		trainData, conf := newSplitData(t)
+		conf.ContinuousSplitCandidates = tc.candidates
+		conf.ContinuousSplitBins = 2
+		tr, err := tree.BuildTree(conf, trainData)
+		if err != nil {
+			t.Fatalf("%s: failed to build tree: %v", tc.candidates, err)
+		}
+		if len(tr.RootNode.Children) != 2 {
+			t.Fatalf("%s: expected the root to be split in 2, got %d children", tc.candidates, len(tr.RootNode.Children))
+		}
+		if threshold := tr.RootNode.Children[0].Condition.(*tree.ContinuousCondition).UpperValue(); threshold != tc.threshold {
+			t.Errorf("%s: expected threshold %g, got %g", tc.candidates, tc.threshold, threshold)
+		}
+	}
+}
+
+func TestDiscretizeAdult(t *testing.T) {
+	conf := &config.Config{
+		ConsiderInvalidDataAsMissing: true,
+		MaxDepth:                     50,
+		MinSamplesSplit:              32,
+		MinSamplesLeaf:               8,
+		MinImpurityDecrease:          0.1,
+		MaxNominalBruteForceScale:    4,
+	}
+	attrTable, err := data.ReadAttributes("../dataset/adult.names")
+	if err != nil {
+		t.Fatalf("failed to read attributes: %v", err)
+	}
+	trainData, err := data.ReadValues(conf, attrTable, "../dataset/adult.data")
+	if err != nil {
+		t.Fatalf("failed to read training data: %v", err)
+	}
+	dataset.Resample(trainData)
+	testData, err := data.ReadValues(conf, attrTable, "../dataset/adult.test")
+	if err != nil {
+		t.Fatalf("failed to read testing data: %v", err)
+	}
+
+	pipeline := transform.NewPipeline(transform.NewDiscretizers(trainData, transform.MDLBins, 0)...)
+	tr, err := tree.BuildTreeWithPipeline(conf, pipeline, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	for _, attr := range tr.Attributes {
+		if attr.Type() != data.Nominal {
+			t.Errorf("expected attribute '%s' to be discretized, got %s", attr.Name(), attr.Type())
+		}
+	}
+	for _, step := range pipeline.Steps {
+		if mdl := step.(*transform.MDLDiscretize); mdl.Column == "age" && len(mdl.Edges) < 2 {
+			t.Errorf("expected age to have several bins, got edges %v", mdl.Edges)
+		}
+	}
+	res, err := tree.TestRun(tr, testData)
+	if err != nil {
+		t.Fatalf("failed to test run: %v", err)
+	}
+	t.Logf("Accuracy with MDL discretization: %.2f%%", res.Accuracy*100)
+
+	conf.ContinuousSplitCandidates = "mdl"
+	tr, err = tree.BuildTree(conf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree with MDL split candidates: %v", err)
+	}
+	res, err = tree.TestRun(tr, testData)
+	if err != nil {
+		t.Fatalf("failed to test run: %v", err)
+	}
+	t.Logf("Accuracy with MDL split candidates: %.2f%%", res.Accuracy*100)
+}
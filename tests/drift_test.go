@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"DecisionTree/drift"
+	"DecisionTree/tree"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDriftAdult(t *testing.T) {
+	tr, testData := buildAdultTree(t)
+	if tr.TrainingStats == nil {
+		t.Fatalf("expected training stats in the tree")
+	}
+
+	// the training stats are saved with the model
+	modelFile := filepath.Join(t.TempDir(), "tree.json")
+	if err := tree.WriteTreeToFile(tr, modelFile); err != nil {
+		t.Fatalf("failed to write tree: %v", err)
+	}
+	restored, err := tree.ReadTreeFromFile(modelFile)
+	if err != nil {
+		t.Fatalf("failed to read tree: %v", err)
+	}
+	if restored.TrainingStats == nil {
+		t.Fatalf("expected the training stats to be restored")
+	}
+	// leaves reached by no training instance have no occupancy
+	var occupancy float64
+	for _, leaf := range restored.GetLeafNodes() {
+		occupancy += restored.TrainingStats.LeafOccupancy[leaf.UniqId()]
+	}
+	if math.Abs(occupancy-1) > 1e-9 {
+		t.Errorf("expected the leaf occupancy to sum up to 1, got %f", occupancy)
+	}
+
+	report, err := drift.Check(restored, testData, nil)
+	if err != nil {
+		t.Fatalf("failed to check drift: %v", err)
+	}
+	if err := drift.WriteText(os.Stdout, report); err != nil {
+		t.Fatalf("failed to write drift report: %v", err)
+	}
+	// the adult test data is drawn from the same population as the training data
+	for _, attr := range report.Attributes {
+		if attr.Drifted {
+			t.Errorf("expected attribute '%s' not to drift, PSI %.4f", attr.Name, attr.PSI)
+		}
+	}
+	if report.Class == nil || report.Class.MissingRate > 0 {
+		t.Errorf("expected every class value of the test data to be valid")
+	}
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"DecisionTree/export"
+	"DecisionTree/tree"
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const goCodeHarness = `package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"harness/model"
+)
+
+func main() {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var in model.Instance
+		if err := json.Unmarshal(scanner.Bytes(), &in); err != nil {
+			panic(err)
+		}
+		fmt.Println(model.Predict(&in))
+	}
+}
+`
+
+func TestGoCodeAgreesWithTree(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain is not available")
+	}
+	tr, testData := buildAdultTree(t)
+
+	var (
+		rows     []map[string]interface{}
+		expected []string
+	)
+	for _, instance := range testData.Instances {
+		row := make(map[string]interface{})
+		for _, value := range instance.AttributeValues {
+			if value.IsMissing() {
+				row[value.Attribute().Name()] = nil
+			} else {
+				row[value.Attribute().Name()] = value.Value()
+			}
+		}
+		rows = append(rows, row)
+
+		predicted, err := tr.Predict(instance)
+		if err != nil {
+			t.Fatalf("failed to predict: %v", err)
+		}
+		expected = append(expected, predicted)
+	}
+
+	actual := runGoCode(t, tr, rows)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d predictions, got %d", len(expected), len(actual))
+	}
+	mismatch := 0
+	for i := range expected {
+		if actual[i] != expected[i] {
+			mismatch++
+		}
+	}
+	if mismatch > 0 {
+		t.Fatalf("%d of %d predictions of the generated code disagree with the tree", mismatch, len(expected))
+	}
+	t.Logf("generated code agrees with the tree on %d instances", len(expected))
+}
+
+// runGoCode generates a module containing the compiled tree and a harness reading instances from stdin, and returns
+// the predictions of the rows, keyed by attribute name like the JSON fields of the generated struct.
+func runGoCode(t *testing.T, tr *tree.Tree, rows []map[string]interface{}) []string {
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain is not available")
+	}
+
+	dir := t.TempDir()
+	var code bytes.Buffer
+	if err := export.WriteGoCode(&code, tr, &export.GoCodeOptions{PackageName: "model"}); err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+	files := map[string]string{
+		"go.mod":         "module harness\n\ngo 1.21\n",
+		"main.go":        goCodeHarness,
+		"model/model.go": code.String(),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	var input bytes.Buffer
+	for _, row := range rows {
+		line, _ := json.Marshal(row)
+		input.Write(line)
+		input.WriteByte('\n')
+	}
+
+	cmd := exec.Command(goPath, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	cmd.Stdin = &input
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("failed to run generated code: %v\n%s", err, stderr.String())
+	}
+	return strings.Split(strings.TrimSpace(string(output)), "\n")
+}
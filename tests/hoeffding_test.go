@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestHoeffdingTree(t *testing.T) {
+	// yes below x = 30, whatever the color
+	var (
+		attrs = []data.Attribute{data.NewContinuousAttribute("x"), data.NewNominalAttribute("color", []string{"red", "blue"})}
+		rows  []map[string]string
+	)
+	for i := 0; i < 2000; i++ {
+		class := "no"
+		if i*37%100 < 30 {
+			class = "yes"
+		}
+		rows = append(rows, map[string]string{"x": strconv.Itoa(i * 37 % 100), "color": []string{"red", "blue"}[i%2], "Class": class})
+	}
+	trainData, conf := newTestData(t, attrs, yesNo, rows)
+	conf.MaxDepth = 10
+	learner := tree.NewHoeffdingTree(conf, attrs, testClass(trainData), &tree.HoeffdingOptions{GracePeriod: 50})
+	for _, instance := range trainData.Instances {
+		if err := learner.Learn(instance); err != nil {
+			t.Fatalf("failed to learn instance: %v", err)
+		}
+	}
+	tr := learner.Tree()
+	if len(tr.RootNode.Children) != 2 || tr.RootNode.Children[0].Condition.Attr().Name() != "x" {
+		t.Fatalf("expected the root to split on x, got %v", tr.RootNode.Children)
+	}
+	if tr.FeatureImportance.Impurity["x"] <= tr.FeatureImportance.Impurity["color"] {
+		t.Errorf("expected x to be more important than color, got %v", tr.FeatureImportance.Impurity)
+	}
+
+	modelFile := filepath.Join(t.TempDir(), "hoeffding.json")
+	if err := tree.WriteTreeToFile(tr, modelFile); err != nil {
+		t.Fatalf("failed to write tree: %v", err)
+	}
+	restored, err := tree.ReadTreeFromFile(modelFile)
+	if err != nil {
+		t.Fatalf("failed to read tree: %v", err)
+	}
+	for x, expected := range map[string]string{"5": "yes", "25": "yes", "40": "no", "95": "no"} {
+		for _, tr := range []*tree.Tree{tr, restored} {
+			instance, err := data.ParseInstance(conf, tr.Attributes, tr.Class, map[string]string{"x": x, "color": "red"})
+			if err != nil {
+				t.Fatalf("failed to parse instance: %v", err)
+			}
+			if actual, err := tr.Predict(instance); err != nil || actual != expected {
+				t.Errorf("x = %s: expected %s, got %s (%v)", x, expected, actual, err)
+			}
+		}
+	}
+
+	// resumed learning keeps the splits and counts the new instances
+	resumed, err := tree.ResumeHoeffdingTree(conf, restored, nil)
+	if err != nil {
+		t.Fatalf("failed to resume tree: %v", err)
+	}
+	before := restored.RootNode.GetSampleCount()
+	instance, err := data.ParseInstance(conf, attrs, testClass(trainData), map[string]string{"x": "10", "color": "?", "Class": "yes"})
+	if err != nil {
+		t.Fatalf("failed to parse instance: %v", err)
+	}
+	if err := resumed.Learn(instance); err != nil {
+		t.Fatalf("failed to learn instance: %v", err)
+	}
+	if actual := resumed.Tree().RootNode.GetSampleCount(); actual != before+1 {
+		t.Errorf("expected %.0f instances at the root, got %.0f", before+1, actual)
+	}
+}
+
+func TestHoeffdingTreeAdult(t *testing.T) {
+	conf := &config.Config{
+		ConsiderInvalidDataAsMissing: true,
+		MaxDepth:                     20,
+		MinSamplesSplit:              2,
+		MinSamplesLeaf:               1,
+	}
+	attrTable, err := data.ReadAttributes("../dataset/adult.names")
+	if err != nil {
+		t.Fatalf("failed to read attributes: %v", err)
+	}
+	trainData, err := data.ReadValues(conf, attrTable, "../dataset/adult.data")
+	if err != nil {
+		t.Fatalf("failed to read training data: %v", err)
+	}
+	testData, err := data.ReadValues(conf, attrTable, "../dataset/adult.test")
+	if err != nil {
+		t.Fatalf("failed to read testing data: %v", err)
+	}
+
+	learner := tree.NewHoeffdingTree(conf, attrTable.Attributes, attrTable.Class, nil)
+	for _, instance := range trainData.Instances {
+		if err := learner.Learn(instance); err != nil {
+			t.Fatalf("failed to learn instance: %v", err)
+		}
+	}
+	res, err := tree.TestRun(learner.Tree(), testData)
+	if err != nil {
+		t.Fatalf("failed to test run: %v", err)
+	}
+	if res.Accuracy < 0.8 {
+		t.Errorf("expected accuracy at least 80%%, got %.2f%%", res.Accuracy*100)
+	}
+	t.Logf("Accuracy: %.2f%%", res.Accuracy*100)
+}
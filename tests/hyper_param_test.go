@@ -4,6 +4,7 @@ import (
 	"DecisionTree/config"
 	"DecisionTree/data"
 	"DecisionTree/dataset"
+	"DecisionTree/evaluation"
 	"DecisionTree/tree"
 	"DecisionTree/utils"
 	"cmp"
@@ -69,6 +70,7 @@ func TestHyperParams(t *testing.T) {
 			return fmt.Sprintf("%d/%d, %.2f%%, Time Elapsed: %s", b.Current(), b.Total, b.CompletedPercent(), b.TimeElapsedString())
 		})
 		allResults []*HyperParamTestResult
+		actual     []string // classes of the test data
 	)
 
 	for _, maxDepth := range maxDepthList {
@@ -102,12 +104,19 @@ func TestHyperParams(t *testing.T) {
 								log.Printf("failed to test tree %s: %v", utils.Json(conf), err)
 								continue
 							}
+							var predictions []string
+							actual, predictions, err = evaluation.Predict(tr, testData)
+							if err != nil {
+								log.Printf("failed to predict with tree %s: %v", utils.Json(conf), err)
+								continue
+							}
 							allResults = append(allResults, &HyperParamTestResult{
 								Conf:        conf,
 								TrainTime:   trainTime,
 								NNodes:      tr.GetNodeCount(),
 								NLeaf:       len(tr.GetLeafNodes()),
 								TestMetrics: res,
+								Predictions: predictions,
 							})
 						}
 					}
@@ -130,7 +139,25 @@ func TestHyperParams(t *testing.T) {
 	bestResult := allResults[0]
 	fmt.Printf("Best Config: %s\n", utils.JsonPretty(bestResult.Conf))
 	fmt.Printf("Best Result:\n")
-	outputTestResult(nil, bestResult.TestMetrics)
+	report := evaluation.NewReport(bestResult.TestMetrics)
+	report.Intervals = evaluation.BootstrapPredictions(actual, bestResult.Predictions, bestResult.NLeaf, nil)
+	if err := evaluation.WriteReportCsv(os.Stdout, report); err != nil {
+		log.Fatalf("failed to write report: %v", err)
+		return
+	}
+
+	// tell whether the best config is really better than the runner-ups, or the difference could be noise
+	fmt.Printf("Best Config Compared With Runner-ups:\n")
+	for _, other := range allResults[1:min(len(allResults), 6)] {
+		comparison := evaluation.ComparePredictions(actual, bestResult.Predictions, other.Predictions, bestResult.NLeaf, other.NLeaf, nil)
+		accuracy := comparison.Metrics[0]
+		verdict := "not significantly better"
+		if comparison.Better(0.05) == "a" {
+			verdict = "significantly better"
+		}
+		fmt.Printf("vs %s: accuracy %+.4f [%.4f, %.4f], McNemar p = %.4g, %s\n",
+			utils.Json(other.Conf), accuracy.Difference, accuracy.Lower, accuracy.Upper, comparison.McNemar.PValue, verdict)
+	}
 
 	// save all test content to file
 	file, err := os.Create("hyper_param_test.json")
@@ -154,6 +181,7 @@ type HyperParamTestResult struct {
 	NNodes      int
 	NLeaf       int
 	TestMetrics *tree.TestResults
+	Predictions []string `json:"-"` // predicted classes of the test data, for paired comparisons
 }
 
 func aggregateHyperParamTestResults(results []*HyperParamTestResult) *HyperParamTestResult {
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/export"
+	"DecisionTree/tree"
+	"bytes"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newRampData returns 400 instances of x in [0, 200), of which about x / 200 are yes, and a noise attribute.
+func newRampData(t *testing.T) (*data.ValueTable, *config.Config) {
+	var rows []map[string]string
+	for i := 0; i < 400; i++ {
+		x := i % 200
+		class := "no"
+		if (i*37+i/200*101)%200 < x {
+			class = "yes"
+		}
+		rows = append(rows, map[string]string{"x": strconv.Itoa(x), "noise": strconv.Itoa(i * 53 % 17), "Class": class})
+	}
+	return newTestData(t, []data.Attribute{data.NewContinuousAttribute("x"), data.NewContinuousAttribute("noise")}, yesNo, rows)
+}
+
+func TestModelTree(t *testing.T) {
+	trainData, conf := newRampData(t)
+	conf.MaxDepth = 10
+	conf.MinSamplesSplit = 80
+	modelConf := *conf
+	modelConf.ModelTree = true
+	constantTree, err := tree.BuildTree(conf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	tr, err := tree.BuildTree(&modelConf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	if !tr.IsModelTree() || tr.ModelSmoothing != 15 {
+		t.Fatalf("expected a model tree smoothed with k = 15, got smoothing %v", tr.ModelSmoothing)
+	}
+	// a linear model of x replaces the staircase of constant leaves
+	if len(tr.GetLeafNodes()) >= len(constantTree.GetLeafNodes()) {
+		t.Errorf("expected fewer leaves than the %d constant leaves, got %d", len(constantTree.GetLeafNodes()), len(tr.GetLeafNodes()))
+	}
+	if model := tr.RootNode.Model; !strings.Contains(model.String(), "*x") || strings.Contains(model.String(), "*noise") {
+		t.Errorf("expected the root model to depend on x only, got %s", model)
+	}
+
+	// the model is saved with the tree
+	modelFile := filepath.Join(t.TempDir(), "tree.json")
+	if err := tree.WriteTreeToFile(tr, modelFile); err != nil {
+		t.Fatalf("failed to write tree: %v", err)
+	}
+	restored, err := tree.ReadTreeFromFile(modelFile)
+	if err != nil {
+		t.Fatalf("failed to read tree: %v", err)
+	}
+
+	// the probability of yes follows the ramp
+	for _, x := range []float64{20, 100, 180} {
+		instance, err := data.ParseInstance(conf, restored.Attributes, restored.Class, map[string]string{"x": strconv.FormatFloat(x, 'f', -1, 64), "noise": "3"})
+		if err != nil {
+			t.Fatalf("failed to parse instance: %v", err)
+		}
+		probabilities, err := restored.PredictProba(instance)
+		if err != nil {
+			t.Fatalf("failed to predict: %v", err)
+		}
+		if math.Abs(probabilities["yes"]-x/200) > 0.1 {
+			t.Errorf("x = %v: expected the probability of yes around %.2f, got %.2f", x, x/200, probabilities["yes"])
+		}
+		expected := "no"
+		if x > 100 {
+			expected = "yes"
+		}
+		if predicted, err := restored.Predict(instance); err != nil || (x != 100 && predicted != expected) {
+			t.Errorf("x = %v: expected %s, got %s (%v)", x, expected, predicted, err)
+		}
+	}
+
+	// the explanation shows the model of the leaf
+	instance, err := data.ParseInstance(conf, restored.Attributes, restored.Class, map[string]string{"x": "?", "noise": "3"})
+	if err != nil {
+		t.Fatalf("failed to parse instance: %v", err)
+	}
+	explanation, err := restored.Explain(instance)
+	if err != nil {
+		t.Fatalf("failed to explain: %v", err)
+	}
+	if leaf := explanation.Steps[len(explanation.Steps)-1]; leaf.Model == "" || len(explanation.Probabilities) != 2 {
+		t.Errorf("expected the explanation to show the model of the leaf, got:\n%s", explanation)
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteSQL(&buf, restored, nil); err == nil {
+		t.Errorf("expected model trees not to be exported to sql")
+	}
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMultiOutput(t *testing.T) {
+	var (
+		x          = data.NewContinuousAttribute("x")
+		color      = data.NewNominalAttribute("color", []string{"red", "blue"})
+		targetAttr = data.NewNominalAttribute("tag", []string{"P", "Q"})
+		rows       []map[string]string
+	)
+	// x decides the class, and color decides the tag
+	for i := 1; i <= 16; i++ {
+		row := map[string]string{"x": strconv.Itoa(i), "color": "red", "Class": "yes", "tag": "P"}
+		if i > 8 {
+			row["Class"] = "no"
+		}
+		if i%2 == 0 {
+			row["color"], row["tag"] = "blue", "Q"
+		}
+		rows = append(rows, row)
+	}
+	trainData, conf := newTestData(t, []data.Attribute{x, color}, yesNo, rows)
+	conf.MaxDepth = 10
+	for i, instance := range trainData.Instances {
+		var err error
+		instance.TargetValues, err = data.ParseTargetValues(conf, []*data.NominalAttribute{targetAttr}, rows[i])
+		if err != nil {
+			t.Fatalf("failed to parse target values: %v", err)
+		}
+	}
+
+	tr, err := tree.BuildTree(conf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	if len(tr.Targets) != 1 || tr.Targets[0].Name() != "tag" {
+		t.Fatalf("expected target 'tag', got %v", tr.Targets)
+	}
+	res, err := tree.TestRun(tr, trainData)
+	if err != nil {
+		t.Fatalf("failed to test run: %v", err)
+	}
+	if res.Accuracy != 1 || res.Targets["tag"] == nil || res.Targets["tag"].Accuracy != 1 {
+		t.Errorf("expected the tree to fit both the class and the tag, got accuracy %v", res.Accuracy)
+	}
+
+	predicted, err := tr.PredictTargets(trainData.Instances[9])
+	if err != nil {
+		t.Fatalf("failed to predict targets: %v", err)
+	}
+	if predicted["Class"] != "no" || predicted["tag"] != "Q" {
+		t.Errorf("expected Class=no and tag=Q for x=10, got %v", predicted)
+	}
+}
+
+func TestMultiOutputAdult(t *testing.T) {
+	conf := &config.Config{
+		ConsiderInvalidDataAsMissing: true,
+		MaxDepth:                     50,
+		MinSamplesSplit:              32,
+		MinSamplesLeaf:               8,
+		MinImpurityDecrease:          0.1,
+		MaxNominalBruteForceScale:    4,
+	}
+	// predict the sex as well as the income
+	names, err := os.ReadFile("../dataset/adult.names")
+	if err != nil {
+		t.Fatalf("failed to read attributes file: %v", err)
+	}
+	namesFile := filepath.Join(t.TempDir(), "adult.names")
+	if err := os.WriteFile(namesFile, []byte(strings.Replace(string(names), "\nsex:", "\n@target sex:", 1)), 0644); err != nil {
+		t.Fatalf("failed to write attributes file: %v", err)
+	}
+	attrTable, err := data.ReadAttributes(namesFile)
+	if err != nil {
+		t.Fatalf("failed to read attributes: %v", err)
+	}
+	if len(attrTable.Targets) != 1 {
+		t.Fatalf("expected sex to be a target, got %d targets", len(attrTable.Targets))
+	}
+	trainData, err := data.ReadValues(conf, attrTable, "../dataset/adult.data")
+	if err != nil {
+		t.Fatalf("failed to read training data: %v", err)
+	}
+	testData, err := data.ReadValues(conf, attrTable, "../dataset/adult.test")
+	if err != nil {
+		t.Fatalf("failed to read testing data: %v", err)
+	}
+
+	tr, err := tree.BuildTree(conf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	for _, attr := range tr.Attributes {
+		if attr.Name() == "sex" {
+			t.Errorf("expected sex not to be an attribute of the tree")
+		}
+	}
+	res, err := tree.TestRun(tr, testData)
+	if err != nil {
+		t.Fatalf("failed to test run: %v", err)
+	}
+	sexRes := res.Targets["sex"]
+	if sexRes == nil {
+		t.Fatalf("expected test results of target sex")
+	}
+	// the relationship (husband or wife) tells most of the sex
+	if sexRes.Accuracy < 0.75 {
+		t.Errorf("expected accuracy of sex at least 75%%, got %.2f%%", sexRes.Accuracy*100)
+	}
+	t.Logf("Accuracy: income %.2f%%, sex %.2f%%", res.Accuracy*100, sexRes.Accuracy*100)
+
+	// the targets are saved with the model
+	modelFile := filepath.Join(t.TempDir(), "tree.json")
+	if err := tree.WriteTreeToFile(tr, modelFile); err != nil {
+		t.Fatalf("failed to write tree: %v", err)
+	}
+	restored, err := tree.ReadTreeFromFile(modelFile)
+	if err != nil {
+		t.Fatalf("failed to read tree: %v", err)
+	}
+	for i, instance := range testData.Instances[:1000] {
+		expected, err := tr.PredictTargets(instance)
+		if err != nil {
+			t.Fatalf("failed to predict instance %d: %v", i, err)
+		}
+		actual, err := restored.PredictTargets(instance)
+		if err != nil {
+			t.Fatalf("failed to predict instance %d with the restored tree: %v", i, err)
+		}
+		if expected["sex"] == "" || expected["sex"] != actual["sex"] || expected["Class"] != actual["Class"] {
+			t.Fatalf("instance %d: expected %v, got %v from the restored tree", i, expected, actual)
+		}
+	}
+}
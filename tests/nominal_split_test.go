@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// newHighCardinalityData returns instances of 20 values v00..v19, of which the class is classes[i%len(classes)].
+// Every third value has a single instance, so a multi-way split breaks min_samples_leaf = 2.
+// It returns the data set with a config of depth 2 and min_samples_leaf = 2, and the class of every value.
+func newHighCardinalityData(t *testing.T, classes []string) (*data.ValueTable, *config.Config, map[string]string) {
+	var (
+		attr     = data.NewNominalAttribute("code", nil)
+		rows     []map[string]string
+		expected = make(map[string]string)
+	)
+	for i := 0; i < 20; i++ {
+		value := fmt.Sprintf("v%02d", i)
+		attr.AcceptedValues = append(attr.AcceptedValues, value)
+		expected[value] = classes[i%len(classes)]
+		for j := 0; j < i%3+1; j++ {
+			rows = append(rows, map[string]string{"code": value, "Class": expected[value]})
+		}
+	}
+	trainData, conf := newTestData(t, []data.Attribute{attr}, classes, rows)
+	conf.MaxDepth = 2
+	conf.MinSamplesLeaf = 2
+	return trainData, conf, expected
+}
+
+func TestNominalBinarySplitTwoClasses(t *testing.T) {
+	trainData, conf, expected := newHighCardinalityData(t, yesNo)
+	tr, err := tree.BuildTree(conf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	if len(tr.RootNode.Children) != 2 {
+		t.Fatalf("expected the root to be split in 2, got %d children", len(tr.RootNode.Children))
+	}
+	// the 20 values are more than max_nominal_brute_force_scale, the ordered split still separates the classes
+	for value, class := range expected {
+		instance, err := data.ParseInstance(conf, tr.Attributes, tr.Class, map[string]string{"code": value})
+		if err != nil {
+			t.Fatalf("failed to parse instance: %v", err)
+		}
+		if actual, err := tr.Predict(instance); err != nil || actual != class {
+			t.Errorf("code %s: expected %s, got %s (%v)", value, class, actual, err)
+		}
+	}
+}
+
+func TestNominalBinarySplitMultiClass(t *testing.T) {
+	trainData, conf, expected := newHighCardinalityData(t, []string{"a", "b", "c"})
+	tr, err := tree.BuildTree(conf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	if len(tr.RootNode.Children) != 2 {
+		t.Fatalf("expected the root to be split in 2, got %d children", len(tr.RootNode.Children))
+	}
+	// the heuristic orderings find a branch holding exactly the values of one class
+	pure := false
+	for _, child := range tr.RootNode.Children {
+		condition, ok := child.Condition.(*tree.NominalCondition)
+		if !ok {
+			t.Fatalf("expected an is-one-of condition, got %s", child.Condition.Log())
+		}
+		var classes []string
+		for _, value := range condition.AcceptedValues() {
+			if !slices.Contains(classes, expected[value]) {
+				classes = append(classes, expected[value])
+			}
+		}
+		pure = pure || len(classes) == 1
+	}
+	if !pure {
+		t.Errorf("expected a branch of a single class, got %s and %s",
+			tr.RootNode.Children[0].Condition.Log(), tr.RootNode.Children[1].Condition.Log())
+	}
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/export"
+	"DecisionTree/tree"
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newDiagonalData returns the points of a 20x20 grid, of which the class is whether x + 2y < 30, with a config of
+// depth 10.
+func newDiagonalData(t *testing.T) (*data.ValueTable, *config.Config) {
+	var rows []map[string]string
+	for x := 0; x < 20; x++ {
+		for y := 0; y < 20; y++ {
+			class := "no"
+			if x+2*y < 30 {
+				class = "yes"
+			}
+			rows = append(rows, map[string]string{"x": strconv.Itoa(x), "y": strconv.Itoa(y), "Class": class})
+		}
+	}
+	trainData, conf := newTestData(t, []data.Attribute{data.NewContinuousAttribute("x"), data.NewContinuousAttribute("y")}, yesNo, rows)
+	conf.MaxDepth = 10
+	return trainData, conf
+}
+
+func TestObliqueSplit(t *testing.T) {
+	trainData, axisConf := newDiagonalData(t)
+	obliqueConf := *axisConf
+	obliqueConf.ObliqueSplits = true
+	axisTree, err := tree.BuildTree(axisConf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	tr, err := tree.BuildTree(&obliqueConf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	condition, ok := tr.RootNode.Children[0].Condition.(*tree.ObliqueCondition)
+	if !ok {
+		t.Fatalf("expected the root to be split by an oblique condition, got %s", tr.RootNode.Children[0].Condition.Log())
+	}
+	res, err := tree.TestRun(tr, trainData)
+	if err != nil {
+		t.Fatalf("failed to test run: %v", err)
+	}
+	if res.Accuracy != 1 {
+		t.Errorf("expected the oblique tree to fit the training data, got accuracy %v", res.Accuracy)
+	}
+	if len(tr.GetLeafNodes()) >= len(axisTree.GetLeafNodes()) {
+		t.Errorf("expected fewer leaves than the %d of the axis-aligned tree, got %d", len(axisTree.GetLeafNodes()), len(tr.GetLeafNodes()))
+	}
+
+	// a large margin keeps the axis-aligned splits
+	marginConf := obliqueConf
+	marginConf.ObliqueSplitMargin = 1
+	marginTree, err := tree.BuildTree(&marginConf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	if _, ok := marginTree.RootNode.Children[0].Condition.(*tree.ContinuousCondition); !ok {
+		t.Errorf("expected an axis-aligned root split with the margin, got %s", marginTree.RootNode.Children[0].Condition.Log())
+	}
+
+	// the hyperplanes are saved with the model
+	modelFile := filepath.Join(t.TempDir(), "tree.json")
+	if err := tree.WriteTreeToFile(tr, modelFile); err != nil {
+		t.Fatalf("failed to write tree: %v", err)
+	}
+	restored, err := tree.ReadTreeFromFile(modelFile)
+	if err != nil {
+		t.Fatalf("failed to read tree: %v", err)
+	}
+	if log := restored.RootNode.Children[0].Condition.Log(); log != condition.Log() {
+		t.Errorf("expected the restored condition %s, got %s", condition.Log(), log)
+	}
+	for _, instance := range trainData.Instances {
+		expected, _ := tr.Predict(instance)
+		if actual, err := restored.Predict(instance); err != nil || actual != expected {
+			t.Fatalf("expected the restored tree to predict %s for %s, got %s (%v)", expected, instance, actual, err)
+		}
+	}
+
+	// the explanation shows the values of the attributes of the hyperplane
+	instance, err := data.ParseInstance(&obliqueConf, restored.Attributes, restored.Class, map[string]string{"x": "3", "y": "4"})
+	if err != nil {
+		t.Fatalf("failed to parse instance: %v", err)
+	}
+	explanation, err := restored.Explain(instance)
+	if err != nil {
+		t.Fatalf("failed to explain: %v", err)
+	}
+	if explanation.PredictedClass != "yes" || !strings.Contains(explanation.Steps[1].Value, "x = 3.000000, y = 4.000000") {
+		t.Errorf("unexpected explanation:\n%s", explanation)
+	}
+
+	// missing values follow the prioritized branch
+	instance, err = data.ParseInstance(&obliqueConf, restored.Attributes, restored.Class, map[string]string{"x": "3", "y": "?"})
+	if err != nil {
+		t.Fatalf("failed to parse instance: %v", err)
+	}
+	if _, err := restored.Predict(instance); err != nil {
+		t.Errorf("failed to predict an instance with a missing value: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteSQL(&buf, tr, nil); err != nil {
+		t.Fatalf("failed to write sql: %v", err)
+	}
+	if !strings.Contains(buf.String(), `* "x" + `) {
+		t.Errorf("expected the sql to compare a weighted sum, got %s", buf.String())
+	}
+
+	var (
+		rows     []map[string]interface{}
+		expected []string
+	)
+	for _, instance := range trainData.Instances {
+		rows = append(rows, map[string]interface{}{
+			"x": instance.AttributeValues[0].Value(),
+			"y": instance.AttributeValues[1].Value(),
+		})
+		predicted, _ := tr.Predict(instance)
+		expected = append(expected, predicted)
+	}
+	actual := runGoCode(t, tr, rows)
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("row %v: expected %s from the generated code, got %s", rows[i], expected[i], actual[i])
+		}
+	}
+}
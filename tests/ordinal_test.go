@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/tree"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOrdinalSplit(t *testing.T) {
+	size := data.NewOrdinalAttribute("size", []string{"small", "medium", "large", "huge"})
+	trainData, conf := newTestData(t, []data.Attribute{size}, yesNo, []map[string]string{
+		{"size": "small", "Class": "yes"},
+		{"size": "small", "Class": "yes"},
+		{"size": "medium", "Class": "yes"},
+		{"size": "medium", "Class": "yes"},
+		{"size": "large", "Class": "no"},
+		{"size": "huge", "Class": "no"},
+		{"size": "?", "Class": "yes"},
+	})
+
+	tr, err := tree.BuildTree(conf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	if len(tr.RootNode.Children) != 2 {
+		t.Fatalf("expected the root to be split in 2, got %d children", len(tr.RootNode.Children))
+	}
+	condition, ok := tr.RootNode.Children[0].Condition.(*tree.OrdinalCondition)
+	if !ok || condition.Type() != tree.LessEq || condition.Level() != "medium" {
+		t.Fatalf("expected the condition size <= 'medium', got %s", tr.RootNode.Children[0].Condition.Log())
+	}
+	if !tr.RootNode.Children[0].IsPrioritized {
+		t.Errorf("expected the larger branch to be prioritized")
+	}
+
+	// the conditions are saved with the model
+	modelFile := filepath.Join(t.TempDir(), "tree.json")
+	if err := tree.WriteTreeToFile(tr, modelFile); err != nil {
+		t.Fatalf("failed to write tree: %v", err)
+	}
+	restored, err := tree.ReadTreeFromFile(modelFile)
+	if err != nil {
+		t.Fatalf("failed to read tree: %v", err)
+	}
+	if restored.Attributes[0].Type() != data.Ordinal {
+		t.Errorf("expected the restored attribute to be ordinal, got %s", restored.Attributes[0].Type())
+	}
+	for value, expected := range map[string]string{"small": "yes", "medium": "yes", "large": "no", "huge": "no", "?": "yes"} {
+		instance, err := data.ParseInstance(conf, restored.Attributes, restored.Class, map[string]string{"size": value})
+		if err != nil {
+			t.Fatalf("failed to parse instance: %v", err)
+		}
+		if actual, err := restored.Predict(instance); err != nil || actual != expected {
+			t.Errorf("size %s: expected %s, got %s (%v)", value, expected, actual, err)
+		}
+	}
+}
+
+func TestOrdinalAdult(t *testing.T) {
+	conf := &config.Config{
+		ConsiderInvalidDataAsMissing: true,
+		MaxDepth:                     50,
+		MinSamplesSplit:              32,
+		MinSamplesLeaf:               8,
+		MinImpurityDecrease:          0.1,
+		MaxNominalBruteForceScale:    4,
+	}
+	// declare the education levels in order
+	names, err := os.ReadFile("../dataset/adult.names")
+	if err != nil {
+		t.Fatalf("failed to read attributes file: %v", err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(names), "\n") {
+		if strings.HasPrefix(line, "education:") {
+			line = "education: ordinal Preschool, 1st-4th, 5th-6th, 7th-8th, 9th, 10th, 11th, 12th, HS-grad, Some-college, " +
+				"Assoc-voc, Assoc-acdm, Bachelors, Masters, Prof-school, Doctorate."
+		}
+		lines = append(lines, line)
+	}
+	namesFile := filepath.Join(t.TempDir(), "adult.names")
+	if err := os.WriteFile(namesFile, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatalf("failed to write attributes file: %v", err)
+	}
+	attrTable, err := data.ReadAttributes(namesFile)
+	if err != nil {
+		t.Fatalf("failed to read attributes: %v", err)
+	}
+	if attr := attrTable.GetAttrByName("education"); attr == nil || attr.Type() != data.Ordinal {
+		t.Fatalf("expected education to be ordinal")
+	}
+	trainData, err := data.ReadValues(conf, attrTable, "../dataset/adult.data")
+	if err != nil {
+		t.Fatalf("failed to read training data: %v", err)
+	}
+	testData, err := data.ReadValues(conf, attrTable, "../dataset/adult.test")
+	if err != nil {
+		t.Fatalf("failed to read testing data: %v", err)
+	}
+
+	tr, err := tree.BuildTree(conf, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	ordinalCount := 0
+	var count func(node *tree.Node)
+	count = func(node *tree.Node) {
+		if _, ok := node.Condition.(*tree.OrdinalCondition); ok {
+			ordinalCount++
+		}
+		for _, child := range node.Children {
+			count(child)
+		}
+	}
+	count(tr.RootNode)
+	if ordinalCount == 0 {
+		t.Errorf("expected the tree to split on the education levels")
+	}
+	res, err := tree.TestRun(tr, testData)
+	if err != nil {
+		t.Fatalf("failed to test run: %v", err)
+	}
+	if res.Accuracy < 0.8 {
+		t.Errorf("expected accuracy at least 80%%, got %.2f%%", res.Accuracy*100)
+	}
+	t.Logf("Accuracy: %.2f%%, %d ordinal conditions", res.Accuracy*100, ordinalCount)
+}
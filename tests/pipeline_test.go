@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/dataset"
+	"DecisionTree/transform"
+	"DecisionTree/tree"
+	"path/filepath"
+	"testing"
+)
+
+func TestPipelineAdult(t *testing.T) {
+	conf := &config.Config{
+		ConsiderInvalidDataAsMissing: true,
+		MaxDepth:                     50,
+		MinSamplesSplit:              32,
+		MinSamplesLeaf:               8,
+		MinImpurityDecrease:          0.1,
+		MaxNominalBruteForceScale:    4,
+	}
+	attrTable, err := data.ReadAttributes("../dataset/adult.names")
+	if err != nil {
+		t.Fatalf("failed to read attributes: %v", err)
+	}
+	trainData, err := data.ReadValues(conf, attrTable, "../dataset/adult.data")
+	if err != nil {
+		t.Fatalf("failed to read training data: %v", err)
+	}
+	dataset.Resample(trainData)
+	testData, err := data.ReadValues(conf, attrTable, "../dataset/adult.test")
+	if err != nil {
+		t.Fatalf("failed to read testing data: %v", err)
+	}
+
+	pipeline, err := transform.ReadPipelineFromFile("../dataset/adult.pipeline.json")
+	if err != nil {
+		t.Fatalf("failed to read pipeline: %v", err)
+	}
+	tr, err := tree.BuildTreeWithPipeline(conf, pipeline, trainData)
+	if err != nil {
+		t.Fatalf("failed to build tree: %v", err)
+	}
+	for _, attr := range tr.Attributes {
+		if attr.Name() == "education-num" {
+			t.Errorf("expected education-num to be dropped by the pipeline")
+		}
+	}
+	if len(tr.InputAttributes()) != len(attrTable.Attributes) {
+		t.Errorf("expected the tree to take all attributes of the names file, got %d", len(tr.InputAttributes()))
+	}
+
+	// the fitted pipeline is saved with the model, and the restored tree predicts the raw instances the same way
+	modelFile := filepath.Join(t.TempDir(), "tree.json")
+	if err := tree.WriteTreeToFile(tr, modelFile); err != nil {
+		t.Fatalf("failed to write tree: %v", err)
+	}
+	restored, err := tree.ReadTreeFromFile(modelFile)
+	if err != nil {
+		t.Fatalf("failed to read tree: %v", err)
+	}
+	if restored.Pipeline == nil || !restored.Pipeline.IsFitted() {
+		t.Fatalf("expected the fitted pipeline to be restored")
+	}
+	for i, instance := range testData.Instances {
+		expected, err := tr.Predict(instance)
+		if err != nil {
+			t.Fatalf("failed to predict instance %d: %v", i, err)
+		}
+		actual, err := restored.Predict(instance)
+		if err != nil {
+			t.Fatalf("failed to predict instance %d with the restored tree: %v", i, err)
+		}
+		if expected != actual {
+			t.Fatalf("instance %d: expected %s, got %s from the restored tree", i, expected, actual)
+		}
+	}
+
+	res, err := tree.TestRun(restored, testData)
+	if err != nil {
+		t.Fatalf("failed to test run: %v", err)
+	}
+	t.Logf("Accuracy with pipeline: %.2f%%", res.Accuracy*100)
+}
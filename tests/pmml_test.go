@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"DecisionTree/export"
+	"DecisionTree/tree"
+	"bytes"
+	"testing"
+)
+
+func TestPMMLRoundTrip(t *testing.T) {
+	tr, testData := buildAdultTree(t)
+
+	var buf bytes.Buffer
+	if err := export.WritePMML(&buf, tr, nil); err != nil {
+		t.Fatalf("failed to write PMML: %v", err)
+	}
+	imported, err := export.ReadPMML(&buf)
+	if err != nil {
+		t.Fatalf("failed to read PMML: %v", err)
+	}
+	if imported.GetNodeCount() != tr.GetNodeCount() {
+		t.Fatalf("expected %d nodes, got %d", tr.GetNodeCount(), imported.GetNodeCount())
+	}
+
+	expected, err := tree.TestRun(tr, testData)
+	if err != nil {
+		t.Fatalf("failed to do test run: %v", err)
+	}
+	actual, err := tree.TestRun(imported, testData)
+	if err != nil {
+		t.Fatalf("failed to do test run on imported tree: %v", err)
+	}
+	if actual.CorrectCount != expected.CorrectCount {
+		t.Fatalf("expected %d correct predictions, got %d", expected.CorrectCount, actual.CorrectCount)
+	}
+	for i, instance := range testData.Instances {
+		expectedClass, _ := tr.Predict(instance)
+		actualClass, _ := imported.Predict(instance)
+		if expectedClass != actualClass {
+			t.Fatalf("instance %d: expected %s, got %s", i, expectedClass, actualClass)
+		}
+	}
+	t.Logf("imported tree agrees with the tree, accuracy %.2f%%", actual.Accuracy*100)
+}
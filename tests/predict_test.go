@@ -4,9 +4,11 @@ import (
 	"DecisionTree/config"
 	"DecisionTree/data"
 	"DecisionTree/dataset"
+	"DecisionTree/evaluation"
 	"DecisionTree/tree"
 	"fmt"
 	"log"
+	"os"
 	"testing"
 )
 
@@ -50,45 +52,24 @@ func TestPredict(t *testing.T) {
 	}
 	print("OK\n")
 
-	fmt.Printf("=========================== TRAIN DATASET ===========================\n")
+	fmt.Printf("Nodes count: %d\n", tr.GetNodeCount())
+	fmt.Printf("Leaf Nodes count: %d\n", len(tr.GetLeafNodes()))
+	fmt.Printf("Max depth: %d\n", tr.GetMaxDepth())
 
-	// predict all test data, calculate accuracy
-	res, err := tree.TestRun(tr, trainData)
-	if err != nil {
-		t.Fatalf("failed to do test run: %v", err)
-		return
-	}
-	outputTestResult(tr, res)
+	fmt.Printf("=========================== TRAIN DATASET ===========================\n")
+	outputReport(t, tr, trainData)
 
 	fmt.Printf("=========================== TEST DATASET ===========================\n")
-
-	// predict all test data, calculate accuracy
-	res, err = tree.TestRun(tr, testData)
-	if err != nil {
-		t.Fatalf("failed to do test run: %v", err)
-		return
-	}
-	outputTestResult(tr, res)
+	outputReport(t, tr, testData)
 }
 
-func outputTestResult(tr *tree.Tree, res *tree.TestResults) {
-	if tr != nil {
-		fmt.Printf("Nodes count: %d\n", tr.GetNodeCount())
-		fmt.Printf("Leaf Nodes count: %d\n", len(tr.GetLeafNodes()))
-		fmt.Printf("Max depth: %d\n", tr.GetMaxDepth())
-	}
-	fmt.Printf("Accuracy: %.2f%%\n", res.Accuracy*100)
-	fmt.Printf("Avg predict time: %s\n", res.AvgPredictTime.String())
-	fmt.Printf("Pessimistic error: %.2f%%\n", res.PessimisticError*100)
-	for class, count := range res.ClassDataCount {
-		fmt.Printf("Class [%s] data frequency: %.2f%%\n", class, float64(count)/float64(res.TotalDataCount)*100)
-		fmt.Printf("Class [%s] recall: %.2f%%\n", class, res.ClassRecall[class]*100)
-		fmt.Printf("Class [%s] precision: %.2f%%\n", class, res.ClassPrecision[class]*100)
+// outputReport evaluates the tree on the data set and prints the report as CSV.
+func outputReport(t *testing.T, tr *tree.Tree, dataTable *data.ValueTable) {
+	report, err := evaluation.Evaluate(tr, dataTable)
+	if err != nil {
+		t.Fatalf("failed to evaluate: %v", err)
 	}
-	fmt.Printf("Confusion matrix:\n")
-	for actual, row := range res.ConfusionMatrix {
-		for predicted, count := range row {
-			fmt.Printf("Actual [%s] & Predict [%s]: %d\n", actual, predicted, count)
-		}
+	if err := evaluation.WriteReportCsv(os.Stdout, report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
 	}
 }
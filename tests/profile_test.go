@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/profile"
+	"os"
+	"testing"
+)
+
+func TestProfileAdult(t *testing.T) {
+	attrTable, err := data.ReadAttributes("../dataset/adult.names")
+	if err != nil {
+		t.Fatalf("failed to read attributes: %v", err)
+	}
+	trainData, err := data.ReadValues(config.Conf, attrTable, "../dataset/adult.data")
+	if err != nil {
+		t.Fatalf("failed to read training data: %v", err)
+	}
+	report, err := profile.Profile(attrTable, trainData, nil)
+	if err != nil {
+		t.Fatalf("failed to profile: %v", err)
+	}
+	if err := profile.WriteText(os.Stdout, report); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	// education-num is the code of education, as checked by TestCheckDatasetDuplication
+	found := false
+	for _, pair := range report.Duplicates.RedundantAttributes {
+		if pair.A == "education" && pair.B == "education-num" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected education and education-num to be redundant, got %v", report.Duplicates.RedundantAttributes)
+	}
+	if report.Duplicates.DuplicateRows == 0 {
+		t.Errorf("expected duplicate rows in the adult data")
+	}
+}
@@ -0,0 +1,170 @@
+package transform
+
+import (
+	"DecisionTree/data"
+	"DecisionTree/utils"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const MDLStep StepType = "mdl"
+
+// MDLBins is the strategy of NewDiscretizers and of the continuous split candidates for MDLDiscretize.
+const MDLBins BinStrategy = "mdl"
+
+// MDLDiscretize converts a continuous attribute into a nominal one by the supervised discretization of Fayyad and
+// Irani: the values are split recursively at the cut point with the lowest class entropy, as long as the information
+// gain passes the minimum description length criterion. The bins are labeled like those of Bin.
+type MDLDiscretize struct {
+	Column string    `json:"column"`
+	Edges  []float64 `json:"edges,omitempty"` // fitted ascending inner edges, empty if the attribute is not worth splitting
+}
+
+func (m *MDLDiscretize) Type() StepType {
+	return MDLStep
+}
+
+func (m *MDLDiscretize) Fit(attributes []data.Attribute, instances []*data.Instance) error {
+	var attr data.Attribute
+	for _, a := range attributes {
+		if a.Name() == m.Column {
+			attr = a
+		}
+	}
+	if attr == nil {
+		return fmt.Errorf("unknown attribute '%s'", m.Column)
+	}
+	if attr.Type() != data.Continuous {
+		return fmt.Errorf("attribute '%s' is not continuous", m.Column)
+	}
+
+	var points []ClassPoint
+	for _, instance := range instances {
+		value := instance.GetValueByAttr(attr)
+		if value == nil || value.IsMissing() || instance.ClassValue == nil || instance.ClassValue.IsMissing() {
+			continue
+		}
+		points = append(points, ClassPoint{Value: value.Value().(float64), Class: instance.ClassValue.Value().(string), Weight: 1})
+	}
+	if len(points) == 0 {
+		return fmt.Errorf("attribute '%s' has no value with a class to fit", m.Column)
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Value < points[j].Value })
+	m.Edges = MDLEdges(points)
+	return nil
+}
+
+func (m *MDLDiscretize) bin() *Bin {
+	return &Bin{Column: m.Column, Strategy: FixedBins, Edges: m.Edges}
+}
+
+func (m *MDLDiscretize) Attributes(input []data.Attribute) ([]data.Attribute, error) {
+	return m.bin().Attributes(input)
+}
+
+func (m *MDLDiscretize) Apply(instance *data.Instance, output []data.Attribute) (*data.Instance, error) {
+	return m.bin().Apply(instance, output)
+}
+
+// NewDiscretizers returns a step discretizing every continuous attribute of the data set, by MDLBins or by the
+// equal-width or equal-frequency strategy of Bin into the given number of bins.
+func NewDiscretizers(valueTable *data.ValueTable, strategy BinStrategy, bins int) []Step {
+	if len(valueTable.Instances) == 0 {
+		return nil
+	}
+	var res []Step
+	for _, value := range valueTable.Instances[0].AttributeValues {
+		attr := value.Attribute()
+		if attr.Type() != data.Continuous {
+			continue
+		}
+		if strategy == MDLBins {
+			res = append(res, &MDLDiscretize{Column: attr.Name()})
+		} else {
+			res = append(res, &Bin{Column: attr.Name(), Strategy: strategy, Bins: bins})
+		}
+	}
+	return res
+}
+
+// ClassPoint is a (weighted) continuous value with its class value.
+type ClassPoint struct {
+	Value  float64
+	Class  string
+	Weight float64
+}
+
+// MDLEdges returns the ascending MDL cut points of the points sorted by value.
+func MDLEdges(points []ClassPoint) []float64 {
+	var res []float64
+	mdlSplit(points, &res)
+	return res
+}
+
+func mdlSplit(points []ClassPoint, edges *[]float64) {
+	classCount, total := countClasses(points)
+	if total <= 1 {
+		return
+	}
+	entropy := utils.Entropy(classCount)
+	if entropy == 0 {
+		return
+	}
+
+	// the cut point between distinct values with the lowest weighted entropy of both sides
+	var (
+		best        = -1
+		bestEntropy = math.Inf(1)
+		leftCount   = make(map[string]float64)
+		leftTotal   float64
+	)
+	for i := 1; i < len(points); i++ {
+		leftCount[points[i-1].Class] += points[i-1].Weight
+		leftTotal += points[i-1].Weight
+		if points[i-1].Value == points[i].Value {
+			continue
+		}
+		rightCount := make(map[string]float64)
+		for class, count := range classCount {
+			rightCount[class] = count - leftCount[class]
+		}
+		if e := (leftTotal*utils.Entropy(leftCount) + (total-leftTotal)*utils.Entropy(rightCount)) / total; e < bestEntropy {
+			best, bestEntropy = i, e
+		}
+	}
+	if best < 0 {
+		return
+	}
+
+	// accept the cut if gain > (log2(N - 1) + delta) / N, delta = log2(3^k - 2) - (k * E(S) - k1 * E(S1) - k2 * E(S2))
+	var (
+		left, _  = countClasses(points[:best])
+		right, _ = countClasses(points[best:])
+		k        = float64(len(classCount))
+		k1       = float64(len(left))
+		k2       = float64(len(right))
+		delta    = math.Log2(math.Pow(3, k)-2) - (k*entropy - k1*utils.Entropy(left) - k2*utils.Entropy(right))
+	)
+	if entropy-bestEntropy <= (math.Log2(total-1)+delta)/total {
+		return
+	}
+	mdlSplit(points[:best], edges)
+	*edges = append(*edges, (points[best-1].Value+points[best].Value)/2)
+	mdlSplit(points[best:], edges)
+}
+
+// countClasses returns the weight of each class value present in the points, and the total weight.
+func countClasses(points []ClassPoint) (map[string]float64, float64) {
+	var (
+		res   = make(map[string]float64)
+		total float64
+	)
+	for _, point := range points {
+		if point.Weight > 0 {
+			res[point.Class] += point.Weight
+			total += point.Weight
+		}
+	}
+	return res, total
+}
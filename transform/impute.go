@@ -0,0 +1,502 @@
+package transform
+
+import (
+	"DecisionTree/data"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+const KNNImputeStep StepType = "knn_impute"
+
+type ImputeStrategy string
+
+const (
+	MeanImpute         ImputeStrategy = "mean"          // continuous attributes only
+	MedianImpute       ImputeStrategy = "median"        // continuous attributes only
+	MostFrequentImpute ImputeStrategy = "most_frequent" // the most frequent value (mode)
+	ConstantImpute     ImputeStrategy = "constant"      // the given value
+)
+
+// indicatorValues are the values of the "was missing" indicator attributes.
+var indicatorValues = []string{"false", "true"}
+
+// Impute replaces the missing values of an attribute with a value fitted by the strategy.
+//
+// With ByClass, a value is also fitted for each class value, and the training instances are imputed with the value
+// of their class. The instances to predict are always imputed with the overall value, as their class is unknown.
+// With Indicator, a nominal attribute "<column>_missing" tells whether the value was missing.
+type Impute struct {
+	Column    string         `json:"column"`
+	Strategy  ImputeStrategy `json:"strategy"`
+	ByClass   bool           `json:"by_class,omitempty"`
+	Indicator bool           `json:"indicator,omitempty"`
+
+	Value       string            `json:"value,omitempty"`        // fitted, or given for ConstantImpute
+	ClassValues map[string]string `json:"class_values,omitempty"` // fitted for ByClass
+}
+
+func (im *Impute) Type() StepType {
+	return ImputeStep
+}
+
+func (im *Impute) Fit(attributes []data.Attribute, instances []*data.Instance) error {
+	_, attr, err := findAttr(attributes, im.Column)
+	if err != nil {
+		return err
+	}
+	if im.Strategy == ConstantImpute {
+		if im.Value == "" {
+			return fmt.Errorf("no constant value to impute")
+		}
+		return nil
+	}
+	if (im.Strategy == MeanImpute || im.Strategy == MedianImpute) && !attr.Type().IsNumeric() {
+		return fmt.Errorf("strategy '%s' needs a continuous attribute, '%s' is %s", im.Strategy, im.Column, attr.Type())
+	}
+
+	var (
+		values      []data.Value
+		classValues = make(map[string][]data.Value)
+	)
+	for _, instance := range instances {
+		value := instance.GetValueByAttr(attr)
+		if value == nil || value.IsMissing() {
+			continue
+		}
+		values = append(values, value)
+		if im.ByClass && instance.ClassValue != nil && !instance.ClassValue.IsMissing() {
+			class := instance.ClassValue.Value().(string)
+			classValues[class] = append(classValues[class], value)
+		}
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("attribute '%s' has no value to fit", im.Column)
+	}
+
+	im.Value, err = fitImputeValue(im.Strategy, attr.Type(), values)
+	if err != nil {
+		return err
+	}
+	im.ClassValues = nil
+	if im.ByClass {
+		im.ClassValues = make(map[string]string)
+		for class, classValues := range classValues {
+			im.ClassValues[class], _ = fitImputeValue(im.Strategy, attr.Type(), classValues)
+		}
+	}
+	return nil
+}
+
+// fitImputeValue fits the value to impute from the non-missing values.
+func fitImputeValue(strategy ImputeStrategy, attrType data.AttributeType, values []data.Value) (string, error) {
+	var (
+		continuous []float64
+		counts     = make(map[string]int)
+	)
+	for _, value := range values {
+		if attrType.IsNumeric() {
+			continuous = append(continuous, value.Value().(float64))
+			counts[formatFloat(value.Value().(float64))]++
+		} else {
+			counts[value.Value().(string)]++
+		}
+	}
+
+	switch strategy {
+	case MeanImpute:
+		var sum float64
+		for _, v := range continuous {
+			sum += v
+		}
+		return formatFloat(sum / float64(len(continuous))), nil
+	case MedianImpute:
+		sort.Float64s(continuous)
+		n := len(continuous)
+		return formatFloat((continuous[(n-1)/2] + continuous[n/2]) / 2), nil
+	case MostFrequentImpute:
+		return mostFrequent(counts), nil
+	default:
+		return "", fmt.Errorf("unknown impute strategy '%s'", strategy)
+	}
+}
+
+// mostFrequent returns the value with the highest count, ties keep the smallest value so that fits are deterministic.
+func mostFrequent(counts map[string]int) string {
+	best := ""
+	for value, count := range counts {
+		if best == "" || count > counts[best] || count == counts[best] && value < best {
+			best = value
+		}
+	}
+	return best
+}
+
+func (im *Impute) Attributes(input []data.Attribute) ([]data.Attribute, error) {
+	if _, _, err := findAttr(input, im.Column); err != nil {
+		return nil, err
+	}
+	if im.Indicator {
+		return appendIndicators(input, []string{im.Column})
+	}
+	return input, nil
+}
+
+func (im *Impute) Apply(instance *data.Instance, output []data.Attribute) (*data.Instance, error) {
+	return im.apply(instance, output, im.Value)
+}
+
+// ApplyTraining imputes a training instance with the value of its class, if fitted ByClass.
+func (im *Impute) ApplyTraining(instance *data.Instance, output []data.Attribute) (*data.Instance, error) {
+	value := im.Value
+	if instance.ClassValue != nil && !instance.ClassValue.IsMissing() {
+		if classValue, ok := im.ClassValues[instance.ClassValue.Value().(string)]; ok {
+			value = classValue
+		}
+	}
+	return im.apply(instance, output, value)
+}
+
+func (im *Impute) apply(instance *data.Instance, output []data.Attribute, imputed string) (*data.Instance, error) {
+	i, value, err := findValue(instance, im.Column)
+	if err != nil {
+		return nil, err
+	}
+	if !value.IsMissing() && !im.Indicator {
+		return instance, nil
+	}
+	res := copyInstance(instance)
+	if value.IsMissing() {
+		res.AttributeValues[i], err = parseImputed(output[i], imputed)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if im.Indicator {
+		res.AttributeValues = append(res.AttributeValues, indicatorValue(output[len(output)-1], value.IsMissing()))
+	}
+	return res, nil
+}
+
+// KNNImpute replaces the missing values of the attributes with those of the k nearest reference instances, which are
+// sampled from the training data. The distance is the Gower distance over the other attributes: the absolute
+// difference divided by the range for continuous attributes, 0 or 1 for nominal ones, averaged over the attributes
+// known in both instances. A continuous value is imputed with the mean of the neighbors, a nominal one with the
+// most frequent value among them.
+// With Indicator, a nominal attribute "<column>_missing" tells whether the value of each column was missing.
+type KNNImpute struct {
+	Columns       []string `json:"columns"`
+	K             int      `json:"k,omitempty"`              // 5 if 0
+	MaxReferences int      `json:"max_references,omitempty"` // 1000 if 0
+	Indicator     bool     `json:"indicator,omitempty"`
+
+	// fitted
+	Features   []string             `json:"features,omitempty"`   // attributes of the reference instances
+	Types      []data.AttributeType `json:"types,omitempty"`      // type of each feature
+	Ranges     []float64            `json:"ranges,omitempty"`     // range of each continuous feature, 0 for nominal ones
+	References [][]string           `json:"references,omitempty"` // values of the reference instances, "?" if missing
+
+	parseOnce  sync.Once
+	references [][]interface{} // parsed references, nil for missing values
+}
+
+func (k *KNNImpute) Type() StepType {
+	return KNNImputeStep
+}
+
+func (k *KNNImpute) neighborCount() int {
+	if k.K > 0 {
+		return k.K
+	}
+	return 5
+}
+
+func (k *KNNImpute) Fit(attributes []data.Attribute, instances []*data.Instance) error {
+	if len(k.Columns) == 0 {
+		return fmt.Errorf("no column to impute")
+	}
+	for _, column := range k.Columns {
+		if _, _, err := findAttr(attributes, column); err != nil {
+			return err
+		}
+	}
+	if len(instances) == 0 {
+		return fmt.Errorf("no instances")
+	}
+	maxReferences := k.MaxReferences
+	if maxReferences <= 0 {
+		maxReferences = 1000
+	}
+
+	k.Features, k.Types, k.Ranges, k.References = nil, nil, nil, nil
+	for _, attr := range attributes {
+		k.Features = append(k.Features, attr.Name())
+		k.Types = append(k.Types, attr.Type())
+		var (
+			lower = math.Inf(1)
+			upper = math.Inf(-1)
+		)
+		if attr.Type().IsNumeric() {
+			for _, instance := range instances {
+				if value := instance.GetValueByAttr(attr); value != nil && !value.IsMissing() {
+					lower = math.Min(lower, value.Value().(float64))
+					upper = math.Max(upper, value.Value().(float64))
+				}
+			}
+		}
+		if upper > lower {
+			k.Ranges = append(k.Ranges, upper-lower)
+		} else {
+			k.Ranges = append(k.Ranges, 0)
+		}
+	}
+
+	// evenly spaced references, so that the fit is deterministic
+	count := min(maxReferences, len(instances))
+	for r := 0; r < count; r++ {
+		instance := instances[r*len(instances)/count]
+		row := make([]string, len(attributes))
+		for j, attr := range attributes {
+			row[j] = "?"
+			if value := instance.GetValueByAttr(attr); value != nil && !value.IsMissing() {
+				if attr.Type().IsNumeric() {
+					row[j] = formatFloat(value.Value().(float64))
+				} else {
+					row[j] = value.Value().(string)
+				}
+			}
+		}
+		k.References = append(k.References, row)
+	}
+	k.parseOnce = sync.Once{}
+	return nil
+}
+
+func (k *KNNImpute) Attributes(input []data.Attribute) ([]data.Attribute, error) {
+	for _, column := range k.Columns {
+		if _, _, err := findAttr(input, column); err != nil {
+			return nil, err
+		}
+	}
+	if len(k.Types) != len(k.Features) || len(k.Ranges) != len(k.Features) {
+		return nil, fmt.Errorf("%d types and %d ranges of %d features", len(k.Types), len(k.Ranges), len(k.Features))
+	}
+	for _, row := range k.References {
+		if len(row) != len(k.Features) {
+			return nil, fmt.Errorf("%d values of a reference with %d features", len(row), len(k.Features))
+		}
+	}
+	if k.Indicator {
+		return appendIndicators(input, k.Columns)
+	}
+	return input, nil
+}
+
+// parseReferences parses the references once, continuous values into float64 and nominal ones into string.
+func (k *KNNImpute) parseReferences() {
+	k.parseOnce.Do(func() {
+		k.references = make([][]interface{}, len(k.References))
+		for r, row := range k.References {
+			k.references[r] = make([]interface{}, len(row))
+			for j, v := range row {
+				if v == "?" {
+					continue
+				}
+				if !k.Types[j].IsNumeric() {
+					k.references[r][j] = v
+				} else if f, err := strconv.ParseFloat(v, 64); err == nil {
+					k.references[r][j] = f
+				}
+			}
+		}
+	})
+}
+
+func (k *KNNImpute) Apply(instance *data.Instance, output []data.Attribute) (*data.Instance, error) {
+	k.parseReferences()
+
+	var (
+		values  = make([]interface{}, len(k.Features))
+		missing = make(map[string]bool)
+	)
+	for j, name := range k.Features {
+		_, value, err := findValue(instance, name)
+		if err != nil {
+			return nil, err
+		}
+		if !value.IsMissing() {
+			values[j] = value.Value()
+		}
+	}
+	for _, column := range k.Columns {
+		_, value, err := findValue(instance, column)
+		if err != nil {
+			return nil, err
+		}
+		missing[column] = value.IsMissing()
+	}
+
+	res := copyInstance(instance)
+	var distances []float64
+	for _, column := range k.Columns {
+		if !missing[column] {
+			continue
+		}
+		if distances == nil {
+			distances = k.distances(values)
+		}
+		i, _, _ := findValue(res, column)
+		imputed, ok := k.impute(column, distances, output[i].Type())
+		if !ok {
+			continue // no reference knows the value, keep it missing
+		}
+		value, err := parseImputed(output[i], imputed)
+		if err != nil {
+			return nil, err
+		}
+		res.AttributeValues[i] = value
+	}
+	if k.Indicator {
+		for c, column := range k.Columns {
+			attr := output[len(output)-len(k.Columns)+c]
+			res.AttributeValues = append(res.AttributeValues, indicatorValue(attr, missing[column]))
+		}
+	}
+	return res, nil
+}
+
+// distances returns the distance of the values to every reference.
+func (k *KNNImpute) distances(values []interface{}) []float64 {
+	res := make([]float64, len(k.references))
+	for r, reference := range k.references {
+		var sum, count float64
+		for j, v := range values {
+			if v == nil || reference[j] == nil {
+				continue
+			}
+			count++
+			if !k.Types[j].IsNumeric() {
+				if v != reference[j] {
+					sum++
+				}
+			} else if k.Ranges[j] > 0 {
+				sum += math.Min(math.Abs(v.(float64)-reference[j].(float64))/k.Ranges[j], 1)
+			}
+		}
+		if count == 0 {
+			res[r] = 1 // nothing to compare, as far as possible
+		} else {
+			res[r] = sum / count
+		}
+	}
+	return res
+}
+
+// impute returns the value of the column imputed from the nearest references knowing it, false if none knows it.
+func (k *KNNImpute) impute(column string, distances []float64, attrType data.AttributeType) (string, bool) {
+	j := -1
+	for i, name := range k.Features {
+		if name == column {
+			j = i
+		}
+	}
+	var candidates []int
+	for r, reference := range k.references {
+		if reference[j] != nil {
+			candidates = append(candidates, r)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return distances[candidates[a]] < distances[candidates[b]]
+	})
+	neighbors := candidates[:min(k.neighborCount(), len(candidates))]
+
+	if attrType.IsNumeric() {
+		var sum float64
+		for _, r := range neighbors {
+			sum += k.references[r][j].(float64)
+		}
+		return formatFloat(sum / float64(len(neighbors))), true
+	}
+	// ties keep the value of the nearest neighbor
+	var (
+		counts = make(map[string]int)
+		best   string
+	)
+	for _, r := range neighbors {
+		counts[k.references[r][j].(string)]++
+	}
+	for _, r := range neighbors {
+		if v := k.references[r][j].(string); best == "" || counts[v] > counts[best] {
+			best = v
+		}
+	}
+	return best, true
+}
+
+// NewImputers returns an Impute step for every attribute with missing values in the data set. Continuous attributes
+// are imputed by the strategy, and nominal ones with the most frequent value.
+func NewImputers(valueTable *data.ValueTable, continuous ImputeStrategy, byClass, indicator bool) []Step {
+	if len(valueTable.Instances) == 0 {
+		return nil
+	}
+	var res []Step
+	for j, value := range valueTable.Instances[0].AttributeValues {
+		attr := value.Attribute()
+		for _, instance := range valueTable.Instances {
+			if j < len(instance.AttributeValues) && instance.AttributeValues[j].IsMissing() {
+				strategy := MostFrequentImpute
+				if attr.Type().IsNumeric() {
+					strategy = continuous
+				}
+				res = append(res, &Impute{Column: attr.Name(), Strategy: strategy, ByClass: byClass, Indicator: indicator})
+				break
+			}
+		}
+	}
+	return res
+}
+
+// parseImputed returns the imputed value of the attribute.
+func parseImputed(attr data.Attribute, imputed string) (data.Value, error) {
+	switch attr := attr.(type) {
+	case *data.ContinuousAttribute:
+		v, err := strconv.ParseFloat(imputed, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse value '%s' to impute: %w", imputed, err)
+		}
+		return data.NewContinuousValue(attr, v), nil
+	case *data.DateTimeAttribute:
+		v, err := strconv.ParseFloat(imputed, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse value '%s' to impute: %w", imputed, err)
+		}
+		return data.NewDateTimeValue(attr, v), nil
+	case *data.NominalAttribute:
+		return data.NewNominalValue(attr, imputed), nil
+	default:
+		return nil, fmt.Errorf("unknown attribute type '%s'", attr.Type())
+	}
+}
+
+// appendIndicators appends a "was missing" indicator attribute of every column.
+func appendIndicators(input []data.Attribute, columns []string) ([]data.Attribute, error) {
+	res := append([]data.Attribute(nil), input...)
+	for _, column := range columns {
+		name := column + "_missing"
+		if _, _, err := findAttr(res, name); err == nil {
+			return nil, fmt.Errorf("attribute '%s' already exists", name)
+		}
+		res = append(res, data.NewNominalAttribute(name, indicatorValues))
+	}
+	return res, nil
+}
+
+func indicatorValue(attr data.Attribute, missing bool) data.Value {
+	return data.NewNominalValue(attr.(*data.NominalAttribute), strconv.FormatBool(missing))
+}
@@ -0,0 +1,105 @@
+package transform
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImputeByClass(t *testing.T) {
+	trainData := newTestData(t,
+		map[string]string{"age": "20", "gain": "0", "loss": "0", "color": "red", "Class": "yes"},
+		map[string]string{"age": "40", "gain": "0", "loss": "0", "color": "blue", "Class": "no"},
+		map[string]string{"age": "?", "gain": "0", "loss": "0", "color": "red", "Class": "yes"},
+		map[string]string{"age": "?", "gain": "0", "loss": "0", "color": "blue", "Class": "no"},
+		map[string]string{"age": "30", "gain": "0", "loss": "0", "color": "red", "Class": "yes"},
+		map[string]string{"age": "60", "gain": "0", "loss": "0", "color": "?", "Class": "no"},
+	)
+	p := NewPipeline(
+		&Impute{Column: "age", Strategy: MeanImpute, ByClass: true, Indicator: true},
+		&Impute{Column: "color", Strategy: MostFrequentImpute, ByClass: true},
+	)
+	transformed, err := p.Fit(trainData)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"age", "gain", "loss", "color", "age_missing"}, names(p.OutputAttributes()))
+	assert.Equal(t, "37.5", p.Steps[0].(*Impute).Value)
+	assert.Equal(t, map[string]string{"yes": "25", "no": "50"}, p.Steps[0].(*Impute).ClassValues)
+	assert.Equal(t, "red", p.Steps[1].(*Impute).Value)
+	assert.Equal(t, map[string]string{"yes": "red", "no": "blue"}, p.Steps[1].(*Impute).ClassValues)
+
+	// the training instances are imputed with the value of their class
+	assert.Equal(t, 20.0, values(transformed.Instances[0])["age"])
+	assert.Equal(t, "false", values(transformed.Instances[0])["age_missing"])
+	assert.Equal(t, 25.0, values(transformed.Instances[2])["age"])
+	assert.Equal(t, "true", values(transformed.Instances[2])["age_missing"])
+	assert.Equal(t, 50.0, values(transformed.Instances[3])["age"])
+	assert.Equal(t, "blue", values(transformed.Instances[5])["color"])
+
+	// the instances to predict with the overall value, whatever their class
+	instance := newTestData(t, map[string]string{"age": "?", "gain": "0", "loss": "0", "color": "?", "Class": "yes"}).Instances[0]
+	res, err := p.Transform(instance)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"age": 37.5, "gain": 0.0, "loss": 0.0, "color": "red", "age_missing": "true"}, values(res))
+
+	_, err = NewPipeline(&Impute{Column: "gain", Strategy: MeanImpute, Indicator: true}, &Impute{Column: "age", Strategy: MeanImpute, Indicator: true}, &Impute{Column: "gain", Strategy: MeanImpute, Indicator: true}).Fit(trainData)
+	assert.Error(t, err, "duplicate indicator")
+}
+
+func TestKNNImpute(t *testing.T) {
+	trainData := newTestData(t,
+		map[string]string{"age": "20", "gain": "0", "loss": "0", "color": "red"},
+		map[string]string{"age": "22", "gain": "0", "loss": "0", "color": "green"},
+		map[string]string{"age": "60", "gain": "10", "loss": "5", "color": "blue"},
+		map[string]string{"age": "62", "gain": "10", "loss": "5", "color": "blue"},
+		map[string]string{"age": "?", "gain": "10", "loss": "5", "color": "blue"},
+		map[string]string{"age": "21", "gain": "0", "loss": "0", "color": "?"},
+	)
+	p := NewPipeline(&KNNImpute{Columns: []string{"age", "color"}, K: 2, Indicator: true})
+	transformed, err := p.Fit(trainData)
+	assert.NoError(t, err)
+	knn := p.Steps[0].(*KNNImpute)
+	assert.Equal(t, []string{"age", "gain", "loss", "color"}, knn.Features)
+	assert.Equal(t, []float64{42, 10, 5, 0}, knn.Ranges)
+	assert.Len(t, knn.References, 6)
+	assert.Equal(t, []string{"age", "gain", "loss", "color", "age_missing", "color_missing"}, names(p.OutputAttributes()))
+
+	// the mean of the 2 nearest ages, and the color of the nearest neighbor on a tie
+	assert.Equal(t, map[string]interface{}{"age": 61.0, "gain": 10.0, "loss": 5.0, "color": "blue", "age_missing": "true", "color_missing": "false"}, values(transformed.Instances[4]))
+	assert.Equal(t, map[string]interface{}{"age": 21.0, "gain": 0.0, "loss": 0.0, "color": "red", "age_missing": "false", "color_missing": "true"}, values(transformed.Instances[5]))
+
+	instance := newTestData(t, map[string]string{"age": "23", "gain": "0", "loss": "?", "color": "?"}).Instances[0]
+	res, err := p.Transform(instance)
+	assert.NoError(t, err)
+	assert.Equal(t, "green", values(res)["color"])
+
+	// the fitted references are saved with the step
+	bytes, err := json.Marshal(p)
+	assert.NoError(t, err)
+	var restored Pipeline
+	assert.NoError(t, json.Unmarshal(bytes, &restored))
+	actual, err := restored.Transform(instance)
+	assert.NoError(t, err)
+	assert.Equal(t, values(res), values(actual))
+
+	sampled := &KNNImpute{Columns: []string{"age"}, MaxReferences: 3}
+	_, err = NewPipeline(sampled).Fit(trainData)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"20", "0", "0", "red"}, {"60", "10", "5", "blue"}, {"?", "10", "5", "blue"}}, sampled.References)
+
+	for _, step := range []Step{&KNNImpute{}, &KNNImpute{Columns: []string{"unknown"}}} {
+		_, err := NewPipeline(step).Fit(trainData)
+		assert.Error(t, err)
+	}
+}
+
+func TestNewImputers(t *testing.T) {
+	trainData := newTestData(t,
+		map[string]string{"age": "20", "gain": "0", "loss": "?", "color": "red"},
+		map[string]string{"age": "40", "gain": "1", "loss": "0", "color": "?"},
+	)
+	assert.Equal(t, []Step{
+		&Impute{Column: "loss", Strategy: MedianImpute, Indicator: true},
+		&Impute{Column: "color", Strategy: MostFrequentImpute, Indicator: true},
+	}, NewImputers(trainData, MedianImpute, false, true))
+}
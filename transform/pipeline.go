@@ -0,0 +1,260 @@
+package transform
+
+import (
+	"DecisionTree/data"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+type StepType string
+
+// Step is a serializable transformation of the attributes of instances. The steps of a pipeline are fitted in
+// order on the training data, each step on the attributes output by the previous one. The fitted parameters are
+// exported fields, so that the step is saved with its parameters.
+type Step interface {
+	Type() StepType
+	// Fit learns the parameters of the step from the training instances, which have the given attributes.
+	Fit(attributes []data.Attribute, instances []*data.Instance) error
+	// Attributes returns the attributes output by the fitted step, given the attributes it is fitted on.
+	Attributes(input []data.Attribute) ([]data.Attribute, error)
+	// Apply transforms an instance with the input attributes into one with the output attributes, the instance
+	// itself is not modified.
+	Apply(instance *data.Instance, output []data.Attribute) (*data.Instance, error)
+}
+
+var stepTypes = map[StepType]func() Step{
+	DropColumnStep: func() Step { return &DropColumn{} },
+	RenameStep:     func() Step { return &Rename{} },
+	MapValuesStep:  func() Step { return &MapValues{} },
+	ImputeStep:     func() Step { return &Impute{} },
+	KNNImputeStep:  func() Step { return &KNNImpute{} },
+	BinStep:        func() Step { return &Bin{} },
+	DeriveStep:     func() Step { return &Derive{} },
+	MDLStep:        func() Step { return &MDLDiscretize{} },
+}
+
+// trainingApplier is implemented by steps which transform the training instances differently from the instances to
+// predict, e.g. with their class values.
+type trainingApplier interface {
+	ApplyTraining(instance *data.Instance, output []data.Attribute) (*data.Instance, error)
+}
+
+// Pipeline is a sequence of steps, which is fitted on the training data and transforms every instance before it
+// is predicted.
+type Pipeline struct {
+	Steps []Step
+
+	// schemas[i] is the attributes input to step i, the last one is the output of the pipeline, nil if not fitted
+	schemas [][]data.Attribute
+}
+
+func NewPipeline(steps ...Step) *Pipeline {
+	return &Pipeline{Steps: steps}
+}
+
+// ReadPipelineFromFile reads a pipeline from a JSON file. A pipeline written by hand is a spec to fit, e.g.:
+// {"steps": [{"type": "drop", "params": {"columns": ["education-num"]}}]}
+func ReadPipelineFromFile(filepath string) (*Pipeline, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	bytes, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	p := &Pipeline{}
+	if err := json.Unmarshal(bytes, p); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return p, nil
+}
+
+// Fit fits the steps in order on the data set, and returns the transformed data set. The attributes of the
+// pipeline are those of the instances of the data set.
+func (p *Pipeline) Fit(valueTable *data.ValueTable) (*data.ValueTable, error) {
+	if len(valueTable.Instances) == 0 {
+		return nil, fmt.Errorf("no instances")
+	}
+	var attributes []data.Attribute
+	for _, value := range valueTable.Instances[0].AttributeValues {
+		attributes = append(attributes, value.Attribute())
+	}
+
+	p.schemas = [][]data.Attribute{attributes}
+	instances := valueTable.Instances
+	for i, step := range p.Steps {
+		if err := step.Fit(attributes, instances); err != nil {
+			p.schemas = nil
+			return nil, fmt.Errorf("failed to fit step %d (%s): %w", i, step.Type(), err)
+		}
+		output, err := step.Attributes(attributes)
+		if err != nil {
+			p.schemas = nil
+			return nil, fmt.Errorf("failed to get attributes of step %d (%s): %w", i, step.Type(), err)
+		}
+		apply := step.Apply
+		if applier, ok := step.(trainingApplier); ok {
+			apply = applier.ApplyTraining
+		}
+		transformed := make([]*data.Instance, len(instances))
+		for j, instance := range instances {
+			transformed[j], err = apply(instance, output)
+			if err != nil {
+				p.schemas = nil
+				return nil, fmt.Errorf("failed to apply step %d (%s) to instance %d: %w", i, step.Type(), j, err)
+			}
+		}
+		p.schemas = append(p.schemas, output)
+		attributes, instances = output, transformed
+	}
+	return &data.ValueTable{Instances: instances}, nil
+}
+
+func (p *Pipeline) IsFitted() bool {
+	return p.schemas != nil
+}
+
+// InputAttributes returns the attributes of the instances to transform, nil if not fitted.
+func (p *Pipeline) InputAttributes() []data.Attribute {
+	if p.schemas == nil {
+		return nil
+	}
+	return p.schemas[0]
+}
+
+// OutputAttributes returns the attributes of the transformed instances, nil if not fitted.
+func (p *Pipeline) OutputAttributes() []data.Attribute {
+	if p.schemas == nil {
+		return nil
+	}
+	return p.schemas[len(p.schemas)-1]
+}
+
+// Transform transforms an instance with the input attributes, values of other attributes are ignored.
+func (p *Pipeline) Transform(instance *data.Instance) (*data.Instance, error) {
+	if p.schemas == nil {
+		return nil, fmt.Errorf("pipeline is not fitted")
+	}
+	res := &data.Instance{ClassValue: instance.ClassValue, TargetValues: instance.TargetValues}
+	for _, attr := range p.schemas[0] {
+		value := instance.GetValueByAttr(attr)
+		if value == nil {
+			return nil, fmt.Errorf("instance has no attribute '%s'", attr.Name())
+		}
+		res.AttributeValues = append(res.AttributeValues, value)
+	}
+	for i, step := range p.Steps {
+		var err error
+		res, err = step.Apply(res, p.schemas[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply step %d (%s): %w", i, step.Type(), err)
+		}
+	}
+	return res, nil
+}
+
+// TransformTable transforms every instance of the data set into a new data set.
+func (p *Pipeline) TransformTable(valueTable *data.ValueTable) (*data.ValueTable, error) {
+	res := &data.ValueTable{Instances: make([]*data.Instance, len(valueTable.Instances))}
+	for i, instance := range valueTable.Instances {
+		var err error
+		res.Instances[i], err = p.Transform(instance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform instance %d: %w", i, err)
+		}
+	}
+	return res, nil
+}
+
+type PersistentPipeline struct {
+	Input []*data.PersistentAttribute `json:"input,omitempty"` // attributes the pipeline is fitted on, empty if not fitted
+	Steps []*PersistentStep           `json:"steps"`
+}
+
+type PersistentStep struct {
+	Type   StepType        `json:"type"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+func NewPersistentPipeline(p *Pipeline) (*PersistentPipeline, error) {
+	pp := &PersistentPipeline{}
+	for _, attr := range p.InputAttributes() {
+		pp.Input = append(pp.Input, data.NewPersistentAttribute(attr))
+	}
+	for i, step := range p.Steps {
+		params, err := json.Marshal(step)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal step %d (%s): %w", i, step.Type(), err)
+		}
+		pp.Steps = append(pp.Steps, &PersistentStep{Type: step.Type(), Params: params})
+	}
+	return pp, nil
+}
+
+// ToPipeline restores the steps, and the attributes of every step if the pipeline is fitted.
+func (pp *PersistentPipeline) ToPipeline() (*Pipeline, error) {
+	p := &Pipeline{}
+	for i, ps := range pp.Steps {
+		newStep, ok := stepTypes[ps.Type]
+		if !ok {
+			return nil, fmt.Errorf("unknown type '%s' of step %d", ps.Type, i)
+		}
+		step := newStep()
+		if len(ps.Params) > 0 {
+			if err := json.Unmarshal(ps.Params, step); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal step %d (%s): %w", i, ps.Type, err)
+			}
+		}
+		p.Steps = append(p.Steps, step)
+	}
+	if len(pp.Input) == 0 {
+		return p, nil
+	}
+
+	var attributes []data.Attribute
+	for _, pa := range pp.Input {
+		attr, err := pa.ToAttribute()
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore attribute '%s': %w", pa.Name, err)
+		}
+		attributes = append(attributes, attr)
+	}
+	p.schemas = [][]data.Attribute{attributes}
+	for i, step := range p.Steps {
+		output, err := step.Attributes(attributes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get attributes of step %d (%s): %w", i, step.Type(), err)
+		}
+		p.schemas = append(p.schemas, output)
+		attributes = output
+	}
+	return p, nil
+}
+
+func (p *Pipeline) MarshalJSON() ([]byte, error) {
+	pp, err := NewPersistentPipeline(p)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(pp)
+}
+
+func (p *Pipeline) UnmarshalJSON(bytes []byte) error {
+	var pp PersistentPipeline
+	if err := json.Unmarshal(bytes, &pp); err != nil {
+		return err
+	}
+	restored, err := pp.ToPipeline()
+	if err != nil {
+		return err
+	}
+	*p = *restored
+	return nil
+}
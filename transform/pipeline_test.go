@@ -0,0 +1,222 @@
+package transform
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testConf = &config.Config{ConsiderInvalidDataAsMissing: true}
+
+var (
+	testAge       = data.NewContinuousAttribute("age")
+	testGain      = data.NewContinuousAttribute("gain")
+	testLoss      = data.NewContinuousAttribute("loss")
+	testColor     = data.NewNominalAttribute("color", []string{"red", "green", "blue"})
+	testClassAttr = data.NewNominalAttribute("Class", []string{"yes", "no"})
+)
+
+func newTestData(t *testing.T, rows ...map[string]string) *data.ValueTable {
+	res := &data.ValueTable{}
+	for _, row := range rows {
+		instance, err := data.ParseInstance(testConf, []data.Attribute{testAge, testGain, testLoss, testColor}, testClassAttr, row)
+		if err != nil {
+			t.Fatalf("failed to parse instance: %v", err)
+		}
+		res.Instances = append(res.Instances, instance)
+	}
+	return res
+}
+
+func values(instance *data.Instance) map[string]interface{} {
+	res := make(map[string]interface{})
+	for _, value := range instance.AttributeValues {
+		if value.IsMissing() {
+			res[value.Attribute().Name()] = nil
+		} else {
+			res[value.Attribute().Name()] = value.Value()
+		}
+	}
+	return res
+}
+
+func names(attributes []data.Attribute) []string {
+	var res []string
+	for _, attr := range attributes {
+		res = append(res, attr.Name())
+	}
+	return res
+}
+
+func newTestPipeline() *Pipeline {
+	return NewPipeline(
+		&Derive{Name: "net", Op: SubOp, Columns: []string{"gain", "loss"}},
+		&DropColumn{Columns: []string{"gain", "loss"}},
+		&Impute{Column: "age", Strategy: MedianImpute},
+		&Impute{Column: "color", Strategy: MostFrequentImpute},
+		&MapValues{Column: "color", Mapping: map[string]string{"green": "cold", "blue": "cold"}},
+		&Bin{Column: "age", Strategy: EqualFrequencyBins, Bins: 2},
+		&Rename{Column: "net", To: "capital-net"},
+	)
+}
+
+func TestPipeline(t *testing.T) {
+	trainData := newTestData(t,
+		map[string]string{"age": "20", "gain": "10", "loss": "0", "color": "red", "Class": "yes"},
+		map[string]string{"age": "30", "gain": "0", "loss": "5", "color": "blue", "Class": "no"},
+		map[string]string{"age": "40", "gain": "?", "loss": "0", "color": "green", "Class": "no"},
+		map[string]string{"age": "?", "gain": "3", "loss": "1", "color": "green", "Class": "yes"},
+		map[string]string{"age": "50", "gain": "0", "loss": "0", "color": "?", "Class": "yes"},
+	)
+	p := newTestPipeline()
+	assert.False(t, p.IsFitted())
+	transformed, err := p.Fit(trainData)
+	assert.NoError(t, err)
+	assert.True(t, p.IsFitted())
+
+	assert.Equal(t, []string{"age", "gain", "loss", "color"}, names(p.InputAttributes()))
+	assert.Equal(t, []string{"age", "color", "capital-net"}, names(p.OutputAttributes()))
+	assert.Equal(t, []string{"red", "cold"}, p.OutputAttributes()[1].(*data.NominalAttribute).AcceptedValues)
+	assert.Equal(t, []string{"< 35", ">= 35"}, p.OutputAttributes()[0].(*data.NominalAttribute).AcceptedValues)
+
+	// the median of age is 35, green is the most frequent color
+	assert.Equal(t, "35", p.Steps[2].(*Impute).Value)
+	assert.Equal(t, "green", p.Steps[3].(*Impute).Value)
+	assert.Equal(t, []float64{35}, p.Steps[5].(*Bin).Edges)
+
+	assert.Len(t, transformed.Instances, 5)
+	assert.Equal(t, map[string]interface{}{"age": "< 35", "color": "red", "capital-net": 10.0}, values(transformed.Instances[0]))
+	assert.Equal(t, map[string]interface{}{"age": ">= 35", "color": "cold", "capital-net": nil}, values(transformed.Instances[2]))
+	assert.Equal(t, map[string]interface{}{"age": ">= 35", "color": "cold", "capital-net": 2.0}, values(transformed.Instances[3]))
+	assert.Equal(t, "yes", transformed.Instances[3].ClassValue.Value())
+	// the data set itself is not modified
+	assert.Len(t, trainData.Instances[0].AttributeValues, 4)
+	assert.True(t, trainData.Instances[3].AttributeValues[0].IsMissing())
+
+	// other attributes and their order do not matter
+	instance, err := data.ParseInstance(testConf, []data.Attribute{data.NewContinuousAttribute("other"), testColor, testLoss, testGain, testAge}, nil,
+		map[string]string{"other": "1", "color": "purple", "loss": "1", "gain": "5", "age": "35"})
+	assert.NoError(t, err)
+	res, err := p.Transform(instance)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"age": ">= 35", "color": "cold", "capital-net": 4.0}, values(res))
+
+	_, err = p.Transform(&data.Instance{ClassValue: instance.ClassValue})
+	assert.Error(t, err)
+	_, err = NewPipeline(&Impute{Column: "age"}).Transform(instance)
+	assert.Error(t, err, "not fitted")
+}
+
+func TestPipelineErrors(t *testing.T) {
+	trainData := newTestData(t, map[string]string{"age": "20", "gain": "10", "loss": "0", "color": "red"})
+	for _, step := range []Step{
+		&DropColumn{Columns: []string{"unknown"}},
+		&Rename{Column: "age", To: "gain"},
+		&MapValues{Column: "age"},
+		&Impute{Column: "color", Strategy: MeanImpute},
+		&Impute{Column: "age", Strategy: ConstantImpute},
+		&Bin{Column: "color", Strategy: EqualWidthBins, Bins: 2},
+		&Bin{Column: "age", Strategy: EqualWidthBins, Bins: 1},
+		&Bin{Column: "age", Strategy: FixedBins, Edges: []float64{2, 1}},
+		&Derive{Name: "x", Op: Log1pOp, Columns: []string{"age", "gain"}},
+		&Derive{Name: "x", Op: AddOp, Columns: []string{"age", "color"}},
+		&Derive{Name: "x", Op: "pow", Columns: []string{"age", "gain"}},
+	} {
+		_, err := NewPipeline(step).Fit(trainData)
+		assert.Error(t, err, "%s: %s", step.Type(), err)
+	}
+}
+
+func TestSteps(t *testing.T) {
+	trainData := newTestData(t,
+		map[string]string{"age": "0", "gain": "0", "loss": "-1", "color": "red"},
+		map[string]string{"age": "10", "gain": "3", "loss": "0", "color": "red"},
+		map[string]string{"age": "100", "gain": "1", "loss": "4", "color": "blue"},
+	)
+	p := NewPipeline(
+		&Bin{Column: "age", Strategy: EqualWidthBins, Bins: 4},
+		&Impute{Column: "gain", Strategy: MeanImpute},
+		&Derive{Name: "ratio", Op: DivOp, Columns: []string{"gain", "loss"}},
+		&Derive{Name: "log", Op: Log1pOp, Columns: []string{"loss"}},
+		&MapValues{Column: "color", Mapping: map[string]string{"red": "warm"}, Default: "other"},
+	)
+	transformed, err := p.Fit(trainData)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{25, 50, 75}, p.Steps[0].(*Bin).Edges)
+	assert.Equal(t, []string{"< 25", "[25, 50)", "[50, 75)", ">= 75"}, p.OutputAttributes()[0].(*data.NominalAttribute).AcceptedValues)
+	assert.Equal(t, "1.3333333333333333", p.Steps[1].(*Impute).Value)
+	assert.Equal(t, []string{"warm", "other"}, p.OutputAttributes()[3].(*data.NominalAttribute).AcceptedValues)
+
+	// dividing by 0 and the log of -1 are missing
+	assert.Equal(t, map[string]interface{}{"age": "< 25", "gain": 0.0, "loss": -1.0, "color": "warm", "ratio": -0.0, "log": nil}, values(transformed.Instances[0]))
+	assert.Equal(t, map[string]interface{}{"age": "< 25", "gain": 3.0, "loss": 0.0, "color": "warm", "ratio": nil, "log": 0.0}, values(transformed.Instances[1]))
+	assert.Equal(t, ">= 75", values(transformed.Instances[2])["age"])
+	assert.Equal(t, "other", values(transformed.Instances[2])["color"])
+
+	instance := newTestData(t, map[string]string{"age": "50", "gain": "?", "loss": "1", "color": "?"}).Instances[0]
+	res, err := p.Transform(instance)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"age": "[50, 75)", "gain": 1.3333333333333333, "loss": 1.0, "color": nil, "ratio": 1.3333333333333333, "log": 0.6931471805599453}, values(res))
+
+	assert.Equal(t, []string{"all"}, BinLabels(nil))
+}
+
+func TestPersistentPipeline(t *testing.T) {
+	trainData := newTestData(t,
+		map[string]string{"age": "20", "gain": "10", "loss": "0", "color": "red"},
+		map[string]string{"age": "60", "gain": "1", "loss": "0", "color": "green"},
+	)
+	p := newTestPipeline()
+	_, err := p.Fit(trainData)
+	assert.NoError(t, err)
+
+	bytes, err := json.Marshal(p)
+	assert.NoError(t, err)
+	var restored Pipeline
+	assert.NoError(t, json.Unmarshal(bytes, &restored))
+	assert.True(t, restored.IsFitted())
+	assert.Equal(t, p.Steps, restored.Steps)
+	assert.Equal(t, names(p.OutputAttributes()), names(restored.OutputAttributes()))
+
+	instance := newTestData(t, map[string]string{"age": "30", "gain": "5", "loss": "2", "color": "blue"}).Instances[0]
+	expected, err := p.Transform(instance)
+	assert.NoError(t, err)
+	actual, err := restored.Transform(instance)
+	assert.NoError(t, err)
+	assert.Equal(t, values(expected), values(actual))
+
+	// a spec written by hand is not fitted
+	path := filepath.Join(t.TempDir(), "pipeline.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"steps": [{"type": "drop", "params": {"columns": ["gain"]}}, {"type": "impute", "params": {"column": "age", "strategy": "mean"}}]}`), 0644))
+	spec, err := ReadPipelineFromFile(path)
+	assert.NoError(t, err)
+	assert.False(t, spec.IsFitted())
+	assert.Equal(t, []Step{&DropColumn{Columns: []string{"gain"}}, &Impute{Column: "age", Strategy: MeanImpute}}, spec.Steps)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"steps": [{"type": "unknown"}]}`), &restored))
+}
+
+func TestMDLDiscretizeSpec(t *testing.T) {
+	// the mdl step is restored without importing the tree package
+	var spec Pipeline
+	assert.NoError(t, json.Unmarshal([]byte(`{"steps": [{"type": "mdl", "params": {"column": "age"}}]}`), &spec))
+	assert.Equal(t, []Step{&MDLDiscretize{Column: "age"}}, spec.Steps)
+
+	var rows []map[string]string
+	for age := 20; age < 60; age += 2 {
+		class := "no"
+		if age >= 40 {
+			class = "yes"
+		}
+		rows = append(rows, map[string]string{"age": strconv.Itoa(age), "gain": "0", "loss": "0", "color": "red", "Class": class})
+	}
+	_, err := spec.Fit(newTestData(t, rows...))
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{39}, spec.Steps[0].(*MDLDiscretize).Edges)
+}
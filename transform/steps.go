@@ -0,0 +1,455 @@
+package transform
+
+import (
+	"DecisionTree/data"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+const (
+	DropColumnStep StepType = "drop"
+	RenameStep     StepType = "rename"
+	MapValuesStep  StepType = "map"
+	ImputeStep     StepType = "impute"
+	BinStep        StepType = "bin"
+	DeriveStep     StepType = "derive"
+)
+
+// DropColumn removes the attributes.
+type DropColumn struct {
+	Columns []string `json:"columns"`
+}
+
+func (d *DropColumn) Type() StepType {
+	return DropColumnStep
+}
+
+func (d *DropColumn) Fit([]data.Attribute, []*data.Instance) error {
+	return nil
+}
+
+func (d *DropColumn) Attributes(input []data.Attribute) ([]data.Attribute, error) {
+	dropped := make(map[string]bool)
+	for _, column := range d.Columns {
+		if _, _, err := findAttr(input, column); err != nil {
+			return nil, err
+		}
+		dropped[column] = true
+	}
+	var res []data.Attribute
+	for _, attr := range input {
+		if !dropped[attr.Name()] {
+			res = append(res, attr)
+		}
+	}
+	return res, nil
+}
+
+func (d *DropColumn) Apply(instance *data.Instance, output []data.Attribute) (*data.Instance, error) {
+	return selectValues(instance, output)
+}
+
+// Rename renames an attribute.
+type Rename struct {
+	Column string `json:"column"`
+	To     string `json:"to"`
+}
+
+func (r *Rename) Type() StepType {
+	return RenameStep
+}
+
+func (r *Rename) Fit([]data.Attribute, []*data.Instance) error {
+	return nil
+}
+
+func (r *Rename) Attributes(input []data.Attribute) ([]data.Attribute, error) {
+	i, attr, err := findAttr(input, r.Column)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := findAttr(input, r.To); err == nil {
+		return nil, fmt.Errorf("attribute '%s' already exists", r.To)
+	}
+	res := append([]data.Attribute(nil), input...)
+	if nominalAttr, ok := attr.(*data.NominalAttribute); ok && nominalAttr.Type() == data.Ordinal {
+		res[i] = data.NewOrdinalAttribute(r.To, nominalAttr.AcceptedValues)
+	} else if ok {
+		res[i] = data.NewNominalAttribute(r.To, nominalAttr.AcceptedValues)
+	} else if dateTimeAttr, ok := attr.(*data.DateTimeAttribute); ok {
+		res[i] = data.NewDateTimeAttribute(r.To, dateTimeAttr.Layouts)
+	} else {
+		res[i] = data.NewContinuousAttribute(r.To)
+	}
+	return res, nil
+}
+
+func (r *Rename) Apply(instance *data.Instance, output []data.Attribute) (*data.Instance, error) {
+	i, value, err := findValue(instance, r.Column)
+	if err != nil {
+		return nil, err
+	}
+	_, attr, err := findAttr(output, r.To)
+	if err != nil {
+		return nil, err
+	}
+	res := copyInstance(instance)
+	switch {
+	case value.IsMissing():
+		res.AttributeValues[i] = data.NewMissingValue(attr)
+	case attr.Type() == data.Continuous:
+		res.AttributeValues[i] = data.NewContinuousValue(attr.(*data.ContinuousAttribute), value.Value().(float64))
+	case attr.Type() == data.DateTime:
+		res.AttributeValues[i] = data.NewDateTimeValue(attr.(*data.DateTimeAttribute), value.Value().(float64))
+	default:
+		res.AttributeValues[i] = data.NewNominalValue(attr.(*data.NominalAttribute), value.Value().(string))
+	}
+	return res, nil
+}
+
+// MapValues maps the values of a nominal attribute, e.g. to merge rare values. Values absent from the mapping are
+// mapped to the default value, or kept if there is no default value.
+type MapValues struct {
+	Column  string            `json:"column"`
+	Mapping map[string]string `json:"mapping"`
+	Default string            `json:"default,omitempty"`
+}
+
+func (m *MapValues) Type() StepType {
+	return MapValuesStep
+}
+
+func (m *MapValues) Fit([]data.Attribute, []*data.Instance) error {
+	return nil
+}
+
+func (m *MapValues) Attributes(input []data.Attribute) ([]data.Attribute, error) {
+	i, attr, err := findAttr(input, m.Column)
+	if err != nil {
+		return nil, err
+	}
+	nominalAttr, ok := attr.(*data.NominalAttribute)
+	if !ok {
+		return nil, fmt.Errorf("attribute '%s' is not nominal", m.Column)
+	}
+
+	var (
+		values []string
+		seen   = make(map[string]bool)
+	)
+	addValue := func(value string) {
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	if len(nominalAttr.AcceptedValues) > 0 {
+		for _, value := range nominalAttr.AcceptedValues {
+			addValue(m.mapValue(value))
+		}
+	} else {
+		// attributes restored from older model files do not know their accepted values
+		var mapped []string
+		for _, value := range m.Mapping {
+			mapped = append(mapped, value)
+		}
+		sort.Strings(mapped)
+		for _, value := range mapped {
+			addValue(value)
+		}
+	}
+	res := append([]data.Attribute(nil), input...)
+	if nominalAttr.Type() == data.Ordinal {
+		// the mapped levels are ordered by the lowest level mapped to them
+		res[i] = data.NewOrdinalAttribute(m.Column, values)
+	} else {
+		res[i] = data.NewNominalAttribute(m.Column, values)
+	}
+	return res, nil
+}
+
+func (m *MapValues) mapValue(value string) string {
+	if mapped, ok := m.Mapping[value]; ok {
+		return mapped
+	}
+	if m.Default != "" {
+		return m.Default
+	}
+	return value
+}
+
+func (m *MapValues) Apply(instance *data.Instance, output []data.Attribute) (*data.Instance, error) {
+	i, value, err := findValue(instance, m.Column)
+	if err != nil {
+		return nil, err
+	}
+	res := copyInstance(instance)
+	if value.IsMissing() {
+		res.AttributeValues[i] = data.NewMissingValue(output[i])
+	} else {
+		res.AttributeValues[i] = data.NewNominalValue(output[i].(*data.NominalAttribute), m.mapValue(value.Value().(string)))
+	}
+	return res, nil
+}
+
+type BinStrategy string
+
+const (
+	EqualWidthBins     BinStrategy = "width"     // bins of the same width between the min and max values
+	EqualFrequencyBins BinStrategy = "frequency" // bins of (about) the same number of values
+	FixedBins          BinStrategy = "edges"     // the given edges
+)
+
+// Bin converts a continuous attribute into a nominal one, of which the values are labels of the bins like
+// "< 30", "[30, 45)" and ">= 45".
+type Bin struct {
+	Column   string      `json:"column"`
+	Strategy BinStrategy `json:"strategy"`
+	Bins     int         `json:"bins,omitempty"`  // number of bins to fit
+	Edges    []float64   `json:"edges,omitempty"` // ascending inner edges, fitted or given for FixedBins
+}
+
+func (b *Bin) Type() StepType {
+	return BinStep
+}
+
+func (b *Bin) Fit(attributes []data.Attribute, instances []*data.Instance) error {
+	_, attr, err := findAttr(attributes, b.Column)
+	if err != nil {
+		return err
+	}
+	if attr.Type() != data.Continuous {
+		return fmt.Errorf("attribute '%s' is not continuous", b.Column)
+	}
+	if b.Strategy == FixedBins {
+		if !sort.Float64sAreSorted(b.Edges) {
+			return fmt.Errorf("edges are not in ascending order")
+		}
+		return nil
+	}
+	if b.Bins < 2 {
+		return fmt.Errorf("at least 2 bins are required, got %d", b.Bins)
+	}
+
+	var values []float64
+	for _, instance := range instances {
+		if value := instance.GetValueByAttr(attr); value != nil && !value.IsMissing() {
+			values = append(values, value.Value().(float64))
+		}
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("attribute '%s' has no value to fit", b.Column)
+	}
+	sort.Float64s(values)
+
+	b.Edges, err = BinEdges(b.Strategy, values, b.Bins)
+	return err
+}
+
+// BinEdges returns the ascending inner edges of the bins of the sorted values, by the equal-width or equal-frequency
+// strategy. Ties of the values might merge bins, so there might be fewer bins than asked for.
+func BinEdges(strategy BinStrategy, values []float64, bins int) ([]float64, error) {
+	var res []float64
+	for i := 1; i < bins; i++ {
+		var edge float64
+		switch strategy {
+		case EqualWidthBins:
+			edge = values[0] + float64(i)*(values[len(values)-1]-values[0])/float64(bins)
+		case EqualFrequencyBins:
+			edge = values[i*len(values)/bins]
+		default:
+			return nil, fmt.Errorf("unknown bin strategy '%s'", strategy)
+		}
+		if edge > values[0] && (len(res) == 0 || edge > res[len(res)-1]) {
+			res = append(res, edge)
+		}
+	}
+	return res, nil
+}
+
+func (b *Bin) Attributes(input []data.Attribute) ([]data.Attribute, error) {
+	i, _, err := findAttr(input, b.Column)
+	if err != nil {
+		return nil, err
+	}
+	res := append([]data.Attribute(nil), input...)
+	res[i] = data.NewNominalAttribute(b.Column, BinLabels(b.Edges))
+	return res, nil
+}
+
+func (b *Bin) Apply(instance *data.Instance, output []data.Attribute) (*data.Instance, error) {
+	i, value, err := findValue(instance, b.Column)
+	if err != nil {
+		return nil, err
+	}
+	res := copyInstance(instance)
+	if value.IsMissing() {
+		res.AttributeValues[i] = data.NewMissingValue(output[i])
+		return res, nil
+	}
+	v := value.Value().(float64)
+	bin := sort.Search(len(b.Edges), func(j int) bool { return b.Edges[j] > v })
+	res.AttributeValues[i] = data.NewNominalValue(output[i].(*data.NominalAttribute), BinLabels(b.Edges)[bin])
+	return res, nil
+}
+
+// BinLabels returns the labels of the bins split by the ascending edges, a value equal to an edge falls into the
+// bin above it.
+func BinLabels(edges []float64) []string {
+	if len(edges) == 0 {
+		return []string{"all"}
+	}
+	res := []string{"< " + formatFloat(edges[0])}
+	for i := 1; i < len(edges); i++ {
+		res = append(res, fmt.Sprintf("[%s, %s)", formatFloat(edges[i-1]), formatFloat(edges[i])))
+	}
+	return append(res, ">= "+formatFloat(edges[len(edges)-1]))
+}
+
+type DeriveOp string
+
+const (
+	AddOp   DeriveOp = "add"   // a + b
+	SubOp   DeriveOp = "sub"   // a - b
+	MulOp   DeriveOp = "mul"   // a * b
+	DivOp   DeriveOp = "div"   // a / b, missing if b is 0
+	Log1pOp DeriveOp = "log1p" // ln(1 + a), missing if a <= -1
+)
+
+// Derive appends a continuous attribute computed from continuous attributes, the derived value is missing if any
+// of the values is missing.
+type Derive struct {
+	Name    string   `json:"name"`
+	Op      DeriveOp `json:"op"`
+	Columns []string `json:"columns"`
+}
+
+func (d *Derive) Type() StepType {
+	return DeriveStep
+}
+
+func (d *Derive) Fit([]data.Attribute, []*data.Instance) error {
+	return nil
+}
+
+func (d *Derive) Attributes(input []data.Attribute) ([]data.Attribute, error) {
+	arity := 2
+	switch d.Op {
+	case AddOp, SubOp, MulOp, DivOp:
+	case Log1pOp:
+		arity = 1
+	default:
+		return nil, fmt.Errorf("unknown derive op '%s'", d.Op)
+	}
+	if len(d.Columns) != arity {
+		return nil, fmt.Errorf("op '%s' takes %d columns, got %d", d.Op, arity, len(d.Columns))
+	}
+	for _, column := range d.Columns {
+		_, attr, err := findAttr(input, column)
+		if err != nil {
+			return nil, err
+		}
+		if attr.Type() != data.Continuous {
+			return nil, fmt.Errorf("attribute '%s' is not continuous", column)
+		}
+	}
+	if _, _, err := findAttr(input, d.Name); err == nil {
+		return nil, fmt.Errorf("attribute '%s' already exists", d.Name)
+	}
+	return append(append([]data.Attribute(nil), input...), data.NewContinuousAttribute(d.Name)), nil
+}
+
+func (d *Derive) Apply(instance *data.Instance, output []data.Attribute) (*data.Instance, error) {
+	var (
+		operands []float64
+		missing  bool
+	)
+	for _, column := range d.Columns {
+		_, value, err := findValue(instance, column)
+		if err != nil {
+			return nil, err
+		}
+		if value.IsMissing() {
+			missing = true
+			break
+		}
+		operands = append(operands, value.Value().(float64))
+	}
+
+	var v float64
+	if !missing {
+		switch d.Op {
+		case AddOp:
+			v = operands[0] + operands[1]
+		case SubOp:
+			v = operands[0] - operands[1]
+		case MulOp:
+			v = operands[0] * operands[1]
+		case DivOp:
+			missing = operands[1] == 0
+			if !missing {
+				v = operands[0] / operands[1]
+			}
+		case Log1pOp:
+			missing = operands[0] <= -1
+			if !missing {
+				v = math.Log1p(operands[0])
+			}
+		}
+	}
+
+	attr := output[len(output)-1]
+	res := copyInstance(instance)
+	if missing {
+		res.AttributeValues = append(res.AttributeValues, data.NewMissingValue(attr))
+	} else {
+		res.AttributeValues = append(res.AttributeValues, data.NewContinuousValue(attr.(*data.ContinuousAttribute), v))
+	}
+	return res, nil
+}
+
+func findAttr(attributes []data.Attribute, name string) (int, data.Attribute, error) {
+	for i, attr := range attributes {
+		if attr.Name() == name {
+			return i, attr, nil
+		}
+	}
+	return -1, nil, fmt.Errorf("no attribute '%s'", name)
+}
+
+func findValue(instance *data.Instance, name string) (int, data.Value, error) {
+	for i, value := range instance.AttributeValues {
+		if value.Attribute().Name() == name {
+			return i, value, nil
+		}
+	}
+	return -1, nil, fmt.Errorf("instance has no attribute '%s'", name)
+}
+
+// copyInstance copies the instance with its own list of values, the values themselves are shared.
+func copyInstance(instance *data.Instance) *data.Instance {
+	return &data.Instance{
+		AttributeValues: append([]data.Value(nil), instance.AttributeValues...),
+		ClassValue:      instance.ClassValue,
+		TargetValues:    instance.TargetValues,
+	}
+}
+
+// selectValues returns an instance with the values of the attributes, in their order.
+func selectValues(instance *data.Instance, attributes []data.Attribute) (*data.Instance, error) {
+	res := &data.Instance{ClassValue: instance.ClassValue, TargetValues: instance.TargetValues}
+	for _, attr := range attributes {
+		_, value, err := findValue(instance, attr.Name())
+		if err != nil {
+			return nil, err
+		}
+		res.AttributeValues = append(res.AttributeValues, value)
+	}
+	return res, nil
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
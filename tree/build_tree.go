@@ -3,6 +3,7 @@ package tree
 import (
 	"DecisionTree/config"
 	"DecisionTree/data"
+	"DecisionTree/transform"
 	"fmt"
 
 	"github.com/gosuri/uiprogress"
@@ -32,10 +33,19 @@ func BuildTree(conf *config.Config, valueTable *data.ValueTable) (*Tree, error)
 
 	tree := &Tree{
 		Attributes: attributes,
+		Class:      instances[0].Instance.ClassValue.Attribute().(*data.NominalAttribute),
 		RootNode: &Node{
 			instances: instances,
 		},
 	}
+	for _, value := range instances[0].Instance.TargetValues {
+		tree.Targets = append(tree.Targets, value.Attribute().(*data.NominalAttribute))
+	}
+	for i, instance := range instances {
+		if len(instance.Instance.TargetValues) != len(tree.Targets) {
+			return nil, fmt.Errorf("instance %d has %d target values, expected %d", i, len(instance.Instance.TargetValues), len(tree.Targets))
+		}
+	}
 
 	// split node
 	bar := config.GetUiProgress().AddBar(1).PrependFunc(func(b *uiprogress.Bar) string {
@@ -59,6 +69,34 @@ func BuildTree(conf *config.Config, valueTable *data.ValueTable) (*Tree, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to post prune tree: %w", err)
 	}
+	// then model trees replace the subtrees that their models predict as well
+	if conf.ModelTree {
+		if _, err := pruneModelTree(conf, tree.RootNode); err != nil {
+			return nil, fmt.Errorf("failed to prune model tree: %w", err)
+		}
+		tree.ModelSmoothing = modelSmoothing(conf)
+	}
+
+	// pruned nodes no longer contribute to the importance, so calculate it at last
+	tree.FeatureImportance = &FeatureImportance{
+		Impurity: tree.ImpurityImportance(),
+	}
+	tree.TrainingStats = NewTrainingStats(tree, valueTable)
 
 	return tree, nil
 }
+
+// BuildTreeWithPipeline fits the pipeline on the training data, builds a tree on the transformed data, and saves the
+// pipeline in the tree, so that the instances to predict are transformed the same way.
+func BuildTreeWithPipeline(conf *config.Config, pipeline *transform.Pipeline, valueTable *data.ValueTable) (*Tree, error) {
+	transformed, err := pipeline.Fit(valueTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fit pipeline: %w", err)
+	}
+	tree, err := BuildTree(conf, transformed)
+	if err != nil {
+		return nil, err
+	}
+	tree.Pipeline = pipeline
+	return tree, nil
+}
@@ -0,0 +1,416 @@
+package tree
+
+import (
+	"DecisionTree/data"
+	"fmt"
+	"math"
+	"sort"
+)
+
+type CalibrationMethod string
+
+const (
+	LaplaceCalibration   CalibrationMethod = "laplace"    // (count + 1) / (total + number of classes)
+	MEstimateCalibration CalibrationMethod = "m-estimate" // (count + m * prior) / (total + m)
+	PlattCalibration     CalibrationMethod = "platt"      // sigmoid of the leaf probability, one-vs-rest
+	IsotonicCalibration  CalibrationMethod = "isotonic"   // isotonic regression of the leaf probability, one-vs-rest
+)
+
+// Calibrator maps the class distribution of a leaf into calibrated class probabilities.
+// Laplace and m-estimate smoothing work on the class counts of the leaf, Platt scaling and isotonic regression are
+// fitted on the leaf probabilities of a validation data set, one class against the others, and normalized.
+type Calibrator struct {
+	Method  CalibrationMethod `json:"method"`
+	Classes []string          `json:"classes"`
+
+	M      float64            `json:"m,omitempty"`
+	Priors map[string]float64 `json:"priors,omitempty"`
+
+	Platt    map[string]*PlattScaling       `json:"platt,omitempty"`
+	Isotonic map[string]*IsotonicRegression `json:"isotonic,omitempty"`
+}
+
+// PlattScaling is the sigmoid 1 / (1 + exp(A * p + B)) of the leaf probability p.
+type PlattScaling struct {
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+}
+
+// IsotonicRegression is a non-decreasing piecewise linear function through the points (X, Y), X is ascending.
+// Probabilities out of the range of X are clipped.
+type IsotonicRegression struct {
+	X []float64 `json:"x"`
+	Y []float64 `json:"y"`
+}
+
+type CalibrationOptions struct {
+	Method CalibrationMethod
+	M      float64 // m of the m-estimate, chosen by the log-loss on the validation data if 0
+}
+
+// mEstimateCandidates are the values of m tried when fitting an m-estimate calibrator.
+var mEstimateCandidates = []float64{0.5, 1, 2, 4, 8, 16, 32, 64, 128, 256}
+
+// FitCalibrator fits a calibrator of the tree, the validation data is required unless the method is laplace or
+// m-estimate with a given m. The calibrator is not set to the tree.
+func FitCalibrator(tr *Tree, validation *data.ValueTable, opts *CalibrationOptions) (*Calibrator, error) {
+	c := &Calibrator{Method: opts.Method, Classes: treeClasses(tr)}
+	if len(c.Classes) == 0 {
+		return nil, fmt.Errorf("tree has no class")
+	}
+	needValidation := opts.Method == PlattCalibration || opts.Method == IsotonicCalibration ||
+		(opts.Method == MEstimateCalibration && opts.M == 0)
+	if needValidation && (validation == nil || len(validation.Instances) == 0) {
+		return nil, fmt.Errorf("calibration method '%s' requires validation data", opts.Method)
+	}
+
+	var (
+		leaves []*Node
+		actual []string
+	)
+	if validation != nil {
+		for i, instance := range validation.Instances {
+			path, err := tr.PredictPath(instance)
+			if err != nil {
+				return nil, fmt.Errorf("failed to predict instance %d: %w", i, err)
+			}
+			leaves = append(leaves, path[len(path)-1])
+			actual = append(actual, instance.ClassValue.Value().(string))
+		}
+	}
+
+	switch opts.Method {
+	case LaplaceCalibration:
+		c.M = float64(len(c.Classes))
+		c.Priors = make(map[string]float64)
+		for _, class := range c.Classes {
+			c.Priors[class] = 1 / float64(len(c.Classes))
+		}
+	case MEstimateCalibration:
+		c.Priors = make(map[string]float64)
+		total := tr.RootNode.GetSampleCount()
+		for _, class := range c.Classes {
+			if total > 0 {
+				c.Priors[class] = tr.RootNode.ClassCount[class] / total
+			} else {
+				c.Priors[class] = 1 / float64(len(c.Classes))
+			}
+		}
+		c.M = opts.M
+		if c.M == 0 {
+			var (
+				bestM    float64
+				bestLoss = math.Inf(1)
+			)
+			for _, m := range mEstimateCandidates {
+				c.M = m
+				if loss := c.logLoss(leaves, actual); loss < bestLoss {
+					bestM, bestLoss = m, loss
+				}
+			}
+			c.M = bestM
+		}
+	case PlattCalibration, IsotonicCalibration:
+		scores := make([]float64, len(leaves))
+		labels := make([]bool, len(leaves))
+		for _, class := range c.Classes {
+			for i, leaf := range leaves {
+				scores[i] = leaf.ClassProbabilities()[class]
+				labels[i] = actual[i] == class
+			}
+			if opts.Method == PlattCalibration {
+				if c.Platt == nil {
+					c.Platt = make(map[string]*PlattScaling)
+				}
+				c.Platt[class] = fitPlattScaling(scores, labels)
+			} else {
+				if c.Isotonic == nil {
+					c.Isotonic = make(map[string]*IsotonicRegression)
+				}
+				c.Isotonic[class] = fitIsotonicRegression(scores, labels)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown calibration method '%s'", opts.Method)
+	}
+	return c, nil
+}
+
+// treeClasses returns the class values of the tree, the classes seen in training if the class attribute is unknown.
+func treeClasses(tr *Tree) []string {
+	if tr.Class != nil {
+		return append([]string(nil), tr.Class.AcceptedValues...)
+	}
+	var classes []string
+	for class := range tr.RootNode.ClassCount {
+		classes = append(classes, class)
+	}
+	if len(classes) == 0 {
+		for _, leaf := range tr.GetLeafNodes() {
+			classes = append(classes, leaf.LeafClass)
+		}
+		classes = uniqueStrings(classes)
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+func uniqueStrings(values []string) []string {
+	var (
+		res  []string
+		seen = make(map[string]bool)
+	)
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func (c *Calibrator) logLoss(leaves []*Node, actual []string) float64 {
+	var loss float64
+	for i, leaf := range leaves {
+		loss -= math.Log(math.Max(c.Calibrate(leaf)[actual[i]], 1e-15))
+	}
+	return loss / float64(len(leaves))
+}
+
+// Calibrate returns the calibrated class probabilities of the leaf.
+// Leaves restored from older model files have no class distribution, their probabilities are not calibrated.
+func (c *Calibrator) Calibrate(leaf *Node) map[string]float64 {
+	switch c.Method {
+	case LaplaceCalibration, MEstimateCalibration:
+		total := leaf.GetSampleCount()
+		if total == 0 {
+			return leaf.ClassProbabilities()
+		}
+		res := make(map[string]float64)
+		for _, class := range c.Classes {
+			res[class] = (leaf.ClassCount[class] + c.M*c.Priors[class]) / (total + c.M)
+		}
+		return res
+	case PlattCalibration, IsotonicCalibration:
+		var (
+			raw = leaf.ClassProbabilities()
+			res = make(map[string]float64)
+			sum float64
+		)
+		for _, class := range c.Classes {
+			if c.Method == PlattCalibration {
+				res[class] = c.Platt[class].Predict(raw[class])
+			} else {
+				res[class] = c.Isotonic[class].Predict(raw[class])
+			}
+			sum += res[class]
+		}
+		if sum == 0 {
+			return raw
+		}
+		for class := range res {
+			res[class] /= sum
+		}
+		return res
+	default:
+		return leaf.ClassProbabilities()
+	}
+}
+
+func (p *PlattScaling) Predict(score float64) float64 {
+	if p == nil {
+		return score
+	}
+	return sigmoid(-(p.A*score + p.B))
+}
+
+// sigmoid computes 1 / (1 + exp(-x)) without overflow.
+func sigmoid(x float64) float64 {
+	if x >= 0 {
+		return 1 / (1 + math.Exp(-x))
+	}
+	e := math.Exp(x)
+	return e / (1 + e)
+}
+
+// fitPlattScaling fits the sigmoid by Newton's method with backtracking line search, on targets smoothed by the
+// numbers of positive and negative instances to avoid overfitting.
+// See Lin, Lin and Weng, "A note on Platt's probabilistic outputs for support vector machines".
+func fitPlattScaling(scores []float64, labels []bool) *PlattScaling {
+	var positives, negatives float64
+	for _, label := range labels {
+		if label {
+			positives++
+		} else {
+			negatives++
+		}
+	}
+	var (
+		highTarget = (positives + 1) / (positives + 2)
+		lowTarget  = 1 / (negatives + 2)
+		targets    = make([]float64, len(labels))
+	)
+	for i, label := range labels {
+		if label {
+			targets[i] = highTarget
+		} else {
+			targets[i] = lowTarget
+		}
+	}
+	objective := func(a, b float64) float64 {
+		var res float64
+		for i, score := range scores {
+			z := score*a + b
+			if z >= 0 {
+				res += targets[i]*z + math.Log1p(math.Exp(-z))
+			} else {
+				res += (targets[i]-1)*z + math.Log1p(math.Exp(z))
+			}
+		}
+		return res
+	}
+
+	var (
+		a, b = 0.0, math.Log((negatives + 1) / (positives + 1))
+		fval = objective(a, b)
+	)
+	for iteration := 0; iteration < 100; iteration++ {
+		// gradient and Hessian, the Hessian is regularized to be positive definite
+		h11, h22, h21, g1, g2 := 1e-12, 1e-12, 0.0, 0.0, 0.0
+		for i, score := range scores {
+			p := sigmoid(-(score*a + b)) // probability of positive
+			d2 := p * (1 - p)
+			h11 += score * score * d2
+			h22 += d2
+			h21 += score * d2
+			d1 := targets[i] - p
+			g1 += score * d1
+			g2 += d1
+		}
+		if math.Abs(g1) < 1e-5 && math.Abs(g2) < 1e-5 {
+			break
+		}
+		det := h11*h22 - h21*h21
+		da := -(h22*g1 - h21*g2) / det
+		db := -(-h21*g1 + h11*g2) / det
+		gd := g1*da + g2*db
+
+		step := 1.0
+		for ; step >= 1e-10; step /= 2 {
+			newA, newB := a+step*da, b+step*db
+			if newF := objective(newA, newB); newF < fval+1e-4*step*gd {
+				a, b, fval = newA, newB, newF
+				break
+			}
+		}
+		if step < 1e-10 {
+			break
+		}
+	}
+	return &PlattScaling{A: a, B: b}
+}
+
+func (r *IsotonicRegression) Predict(score float64) float64 {
+	if r == nil || len(r.X) == 0 {
+		return score
+	}
+	if score <= r.X[0] {
+		return r.Y[0]
+	}
+	if score >= r.X[len(r.X)-1] {
+		return r.Y[len(r.Y)-1]
+	}
+	i := sort.SearchFloat64s(r.X, score) // r.X[i-1] < score <= r.X[i]
+	if r.X[i] == score {
+		return r.Y[i]
+	}
+	ratio := (score - r.X[i-1]) / (r.X[i] - r.X[i-1])
+	return r.Y[i-1] + ratio*(r.Y[i]-r.Y[i-1])
+}
+
+// isotonicBlock is a block of the pool adjacent violators algorithm.
+type isotonicBlock struct {
+	minX, maxX float64
+	sum        float64 // number of positive instances
+	weight     float64 // number of instances
+}
+
+func (b *isotonicBlock) value() float64 {
+	return b.sum / b.weight
+}
+
+// fitIsotonicRegression fits the frequency of positive instances against the scores with the pool adjacent
+// violators algorithm. Each block is kept as its lowest and highest score, so the function is flat within blocks and
+// linear between them.
+func fitIsotonicRegression(scores []float64, labels []bool) *IsotonicRegression {
+	indexes := make([]int, len(scores))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return scores[indexes[i]] < scores[indexes[j]]
+	})
+
+	// instances with the same score always share a block
+	var ties []*isotonicBlock
+	for _, i := range indexes {
+		var positive float64
+		if labels[i] {
+			positive = 1
+		}
+		if len(ties) > 0 && ties[len(ties)-1].maxX == scores[i] {
+			ties[len(ties)-1].sum += positive
+			ties[len(ties)-1].weight++
+		} else {
+			ties = append(ties, &isotonicBlock{minX: scores[i], maxX: scores[i], sum: positive, weight: 1})
+		}
+	}
+
+	var blocks []*isotonicBlock
+	for _, tie := range ties {
+		blocks = append(blocks, tie)
+		// merge the last block with the previous blocks while violating the order
+		for len(blocks) > 1 && blocks[len(blocks)-2].value() >= blocks[len(blocks)-1].value() {
+			last, prev := blocks[len(blocks)-1], blocks[len(blocks)-2]
+			prev.maxX = last.maxX
+			prev.sum += last.sum
+			prev.weight += last.weight
+			blocks = blocks[:len(blocks)-1]
+		}
+	}
+
+	res := &IsotonicRegression{}
+	for _, block := range blocks {
+		res.X = append(res.X, block.minX)
+		res.Y = append(res.Y, block.value())
+		if block.maxX != block.minX {
+			res.X = append(res.X, block.maxX)
+			res.Y = append(res.Y, block.value())
+		}
+	}
+	return res
+}
+
+// DecisionThreshold is the operating point of a tree: the positive class is predicted if its probability is no less
+// than the threshold, otherwise the most probable other class is predicted.
+type DecisionThreshold struct {
+	PositiveClass string  `json:"positive_class"`
+	Threshold     float64 `json:"threshold"`
+}
+
+// Predict predicts the class from the class probabilities, ties are broken by the class order.
+func (d *DecisionThreshold) Predict(probabilities map[string]float64, classes []string) string {
+	if probabilities[d.PositiveClass] >= d.Threshold {
+		return d.PositiveClass
+	}
+	var (
+		best            string
+		bestProbability = -1.0
+	)
+	for _, class := range classes {
+		if class != d.PositiveClass && probabilities[class] > bestProbability {
+			best, bestProbability = class, probabilities[class]
+		}
+	}
+	return best
+}
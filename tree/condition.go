@@ -11,8 +11,13 @@ type ConditionType string
 const (
 	LessThan      ConditionType = "lt"        // continuous
 	GreaterThanEq ConditionType = "ge"        // continuous
-	Range         ConditionType = "range"     // continuous, min < value <= max
+	Range         ConditionType = "range"     // continuous, min <= value < max
 	IsOneOf       ConditionType = "is_one_of" // Nominal
+	LessEq        ConditionType = "le"        // ordinal, value <= level
+	Greater       ConditionType = "gt"        // ordinal, value > level
+
+	ObliqueLessThan      ConditionType = "oblique_lt" // linear combination of continuous attributes, w·x < threshold
+	ObliqueGreaterThanEq ConditionType = "oblique_ge" // linear combination of continuous attributes, w·x >= threshold
 )
 
 type Condition interface {
@@ -22,6 +27,13 @@ type Condition interface {
 	Log() string
 }
 
+// ValueSetCondition is a condition met by the values in a set, IsOneOf conditions and ordinal conditions, which the
+// exporters write as a set membership test.
+type ValueSetCondition interface {
+	Condition
+	AcceptedValues() []string
+}
+
 type ContinuousCondition struct {
 	conditionType ConditionType
 	attr          data.Attribute
@@ -29,7 +41,7 @@ type ContinuousCondition struct {
 	lowerValue    float64
 }
 
-func newLessThanCondition(attr data.Attribute, upperValue float64) *ContinuousCondition {
+func NewLessThanCondition(attr data.Attribute, upperValue float64) *ContinuousCondition {
 	return &ContinuousCondition{
 		conditionType: LessThan,
 		attr:          attr,
@@ -37,7 +49,7 @@ func newLessThanCondition(attr data.Attribute, upperValue float64) *ContinuousCo
 	}
 }
 
-func newGreaterThanEqCondition(attr data.Attribute, lowerValue float64) *ContinuousCondition {
+func NewGreaterThanEqCondition(attr data.Attribute, lowerValue float64) *ContinuousCondition {
 	return &ContinuousCondition{
 		conditionType: GreaterThanEq,
 		attr:          attr,
@@ -45,6 +57,17 @@ func newGreaterThanEqCondition(attr data.Attribute, lowerValue float64) *Continu
 	}
 }
 
+// NewRangeCondition is the conjunction of a GreaterThanEq and a LessThan condition on the same attribute, met by the
+// values from lowerValue included up to upperValue excluded.
+func NewRangeCondition(attr data.Attribute, lowerValue, upperValue float64) *ContinuousCondition {
+	return &ContinuousCondition{
+		conditionType: Range,
+		attr:          attr,
+		lowerValue:    lowerValue,
+		upperValue:    upperValue,
+	}
+}
+
 func (c *ContinuousCondition) Type() ConditionType {
 	return c.conditionType
 }
@@ -53,6 +76,16 @@ func (c *ContinuousCondition) Attr() data.Attribute {
 	return c.attr
 }
 
+// UpperValue is the upper bound of LessThan and Range conditions, excluded.
+func (c *ContinuousCondition) UpperValue() float64 {
+	return c.upperValue
+}
+
+// LowerValue is the lower bound of GreaterThanEq and Range conditions, included.
+func (c *ContinuousCondition) LowerValue() float64 {
+	return c.lowerValue
+}
+
 func (c *ContinuousCondition) IsMet(value data.Value) bool {
 	v := value.Value().(float64)
 	switch c.conditionType {
@@ -61,7 +94,7 @@ func (c *ContinuousCondition) IsMet(value data.Value) bool {
 	case GreaterThanEq:
 		return v >= c.lowerValue
 	case Range:
-		return c.lowerValue < v && v <= c.upperValue
+		return c.lowerValue <= v && v < c.upperValue
 	default:
 		return false
 	}
@@ -70,23 +103,31 @@ func (c *ContinuousCondition) IsMet(value data.Value) bool {
 func (c *ContinuousCondition) Log() string {
 	switch c.conditionType {
 	case LessThan:
-		return c.attr.Name() + " < " + fmt.Sprintf("%.2f", c.upperValue)
+		return c.attr.Name() + " < " + c.format(c.upperValue)
 	case GreaterThanEq:
-		return c.attr.Name() + " >= " + fmt.Sprintf("%.2f", c.lowerValue)
+		return c.attr.Name() + " >= " + c.format(c.lowerValue)
 	case Range:
-		return fmt.Sprintf("%.2f", c.lowerValue) + " < " + c.attr.Name() + " <= " + fmt.Sprintf("%.2f", c.upperValue)
+		return c.format(c.lowerValue) + " <= " + c.attr.Name() + " < " + c.format(c.upperValue)
 	default:
 		return "INVALID COND"
 	}
 }
 
+// format formats the threshold, as a date of datetime attributes.
+func (c *ContinuousCondition) format(v float64) string {
+	if dateTimeAttr, ok := c.attr.(*data.DateTimeAttribute); ok {
+		return dateTimeAttr.Format(v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
 type NominalCondition struct {
 	conditionType  ConditionType
 	attr           data.Attribute
 	acceptedValues []string
 }
 
-func newIsOneOfCondition(attr data.Attribute, acceptedValues []string) *NominalCondition {
+func NewIsOneOfCondition(attr data.Attribute, acceptedValues []string) *NominalCondition {
 	return &NominalCondition{
 		conditionType:  IsOneOf,
 		attr:           attr,
@@ -102,6 +143,10 @@ func (n *NominalCondition) Attr() data.Attribute {
 	return n.attr
 }
 
+func (n *NominalCondition) AcceptedValues() []string {
+	return n.acceptedValues
+}
+
 func (n *NominalCondition) IsMet(value data.Value) bool {
 	v := value.Value().(string)
 	for _, acceptedValue := range n.acceptedValues {
@@ -115,3 +160,153 @@ func (n *NominalCondition) IsMet(value data.Value) bool {
 func (n *NominalCondition) Log() string {
 	return n.attr.Name() + " in " + fmt.Sprintf("['%s']", strings.Join(n.acceptedValues, "', '"))
 }
+
+// OrdinalCondition compares the level of the value of an ordinal attribute with a level, in the order of the accepted
+// values of the attribute. Values that are not levels of the attribute meet no ordinal condition.
+type OrdinalCondition struct {
+	conditionType ConditionType
+	attr          data.Attribute
+	level         string
+}
+
+func NewLessEqCondition(attr data.Attribute, level string) *OrdinalCondition {
+	return &OrdinalCondition{
+		conditionType: LessEq,
+		attr:          attr,
+		level:         level,
+	}
+}
+
+func NewGreaterCondition(attr data.Attribute, level string) *OrdinalCondition {
+	return &OrdinalCondition{
+		conditionType: Greater,
+		attr:          attr,
+		level:         level,
+	}
+}
+
+func (o *OrdinalCondition) Type() ConditionType {
+	return o.conditionType
+}
+
+func (o *OrdinalCondition) Attr() data.Attribute {
+	return o.attr
+}
+
+// Level is the inclusive upper bound of LessEq conditions, and the exclusive lower bound of Greater conditions.
+func (o *OrdinalCondition) Level() string {
+	return o.level
+}
+
+// AcceptedValues returns the levels of the attribute that meet the condition, from the lowest to the highest.
+func (o *OrdinalCondition) AcceptedValues() []string {
+	ordinalAttr, ok := o.attr.(*data.NominalAttribute)
+	if !ok {
+		return nil
+	}
+	level := ordinalAttr.Level(o.level)
+	if level < 0 {
+		return nil
+	}
+	if o.conditionType == LessEq {
+		return ordinalAttr.AcceptedValues[:level+1]
+	}
+	return ordinalAttr.AcceptedValues[level+1:]
+}
+
+func (o *OrdinalCondition) IsMet(value data.Value) bool {
+	ordinalAttr, ok := o.attr.(*data.NominalAttribute)
+	if !ok {
+		return false
+	}
+	v, level := ordinalAttr.Level(value.Value().(string)), ordinalAttr.Level(o.level)
+	if v < 0 || level < 0 {
+		return false
+	}
+	switch o.conditionType {
+	case LessEq:
+		return v <= level
+	case Greater:
+		return v > level
+	default:
+		return false
+	}
+}
+
+func (o *OrdinalCondition) Log() string {
+	switch o.conditionType {
+	case LessEq:
+		return o.attr.Name() + " <= " + fmt.Sprintf("'%s'", o.level)
+	case Greater:
+		return o.attr.Name() + " > " + fmt.Sprintf("'%s'", o.level)
+	default:
+		return "INVALID COND"
+	}
+}
+
+// ObliqueCondition compares a linear combination of continuous attributes with a threshold, the hyperplane of an
+// oblique split. Its attribute is the data.LinearCombinationAttribute, of which the values are the weighted sums,
+// missing if the value of any of the attributes is missing.
+type ObliqueCondition struct {
+	conditionType ConditionType
+	attr          *data.LinearCombinationAttribute
+	threshold     float64
+}
+
+func NewObliqueLessThanCondition(attr *data.LinearCombinationAttribute, threshold float64) *ObliqueCondition {
+	return &ObliqueCondition{
+		conditionType: ObliqueLessThan,
+		attr:          attr,
+		threshold:     threshold,
+	}
+}
+
+func NewObliqueGreaterThanEqCondition(attr *data.LinearCombinationAttribute, threshold float64) *ObliqueCondition {
+	return &ObliqueCondition{
+		conditionType: ObliqueGreaterThanEq,
+		attr:          attr,
+		threshold:     threshold,
+	}
+}
+
+func (o *ObliqueCondition) Type() ConditionType {
+	return o.conditionType
+}
+
+func (o *ObliqueCondition) Attr() data.Attribute {
+	return o.attr
+}
+
+// Combination is the linear combination of the attributes compared with the threshold.
+func (o *ObliqueCondition) Combination() *data.LinearCombinationAttribute {
+	return o.attr
+}
+
+// Threshold is the exclusive upper bound of ObliqueLessThan conditions, and the inclusive lower bound of
+// ObliqueGreaterThanEq conditions.
+func (o *ObliqueCondition) Threshold() float64 {
+	return o.threshold
+}
+
+func (o *ObliqueCondition) IsMet(value data.Value) bool {
+	v := value.Value().(float64)
+	switch o.conditionType {
+	case ObliqueLessThan:
+		return v < o.threshold
+	case ObliqueGreaterThanEq:
+		return v >= o.threshold
+	default:
+		return false
+	}
+}
+
+func (o *ObliqueCondition) Log() string {
+	switch o.conditionType {
+	case ObliqueLessThan:
+		return o.attr.Name() + " < " + fmt.Sprintf("%.2f", o.threshold)
+	case ObliqueGreaterThanEq:
+		return o.attr.Name() + " >= " + fmt.Sprintf("%.2f", o.threshold)
+	default:
+		return "INVALID COND"
+	}
+}
@@ -0,0 +1,145 @@
+package tree
+
+import (
+	"DecisionTree/data"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExplainStep describes a node visited when predicting an instance.
+type ExplainStep struct {
+	NodeId int `json:"node_id"`
+
+	// Condition met to reach this node, empty for the root node.
+	Condition string `json:"condition,omitempty"`
+	// Attribute and value of the instance that the condition is checked against.
+	Attribute string `json:"attribute,omitempty"`
+	Value     string `json:"value,omitempty"`
+
+	// MissingValueFallback is set if the value is missing, so the prioritized branch is used.
+	MissingValueFallback bool `json:"missing_value_fallback,omitempty"`
+	// PrioritizedFallback is set if the value met no condition of the children, so the prioritized branch is used.
+	PrioritizedFallback bool `json:"prioritized_fallback,omitempty"`
+
+	ClassCount map[string]float64 `json:"class_count,omitempty"`
+	LeafClass  string             `json:"leaf_class,omitempty"` // only set on the leaf node
+	// Model is the linear model of the leaf node of model trees, see LinearModel.String.
+	Model string `json:"model,omitempty"`
+}
+
+// Explanation is the ordered list of nodes visited when predicting an instance, from the root node to the leaf node.
+type Explanation struct {
+	PredictedClass string         `json:"predicted_class"`
+	Steps          []*ExplainStep `json:"steps"`
+	// Probabilities are the class probabilities of model trees, smoothed along the path.
+	Probabilities map[string]float64 `json:"probabilities,omitempty"`
+}
+
+// Explain predicts the instance and records why each node on the decision path is chosen.
+// The values are those transformed by the pipeline of the tree, if any.
+func (t *Tree) Explain(instance *data.Instance) (*Explanation, error) {
+	instance, err := t.Transform(instance)
+	if err != nil {
+		return nil, err
+	}
+	return t.ExplainTransformed(instance)
+}
+
+// ExplainTransformed is Explain of an instance already transformed by the pipeline.
+func (t *Tree) ExplainTransformed(instance *data.Instance) (*Explanation, error) {
+	var (
+		res  = &Explanation{}
+		node = t.RootNode
+		step = &ExplainStep{NodeId: node.UniqId(), ClassCount: node.ClassCount}
+		path = []*Node{node}
+	)
+	for len(node.Children) > 0 {
+		res.Steps = append(res.Steps, step)
+		child, val, route := node.getRelatedChild(instance)
+		if child == nil {
+			return nil, fmt.Errorf("unknown error, cannot predict instance at node %d", node.UniqId())
+		}
+		step = &ExplainStep{
+			NodeId:               child.UniqId(),
+			Condition:            child.Condition.Log(),
+			Attribute:            child.Condition.Attr().Name(),
+			Value:                val.Log(),
+			MissingValueFallback: route == routeMissingValue,
+			PrioritizedFallback:  route == routeNoConditionMet,
+			ClassCount:           child.ClassCount,
+		}
+		if combination, ok := child.Condition.Attr().(*data.LinearCombinationAttribute); ok {
+			step.Value = explainCombination(instance, combination, val)
+		}
+		node = child
+		path = append(path, node)
+	}
+	step.LeafClass = node.LeafClass
+	res.Steps = append(res.Steps, step)
+	res.PredictedClass = t.PathPrediction(path, instance)
+	if t.IsModelTree() && node.Model != nil {
+		step.Model = node.Model.String()
+		res.Probabilities = t.modelProbabilities(path, instance)
+	}
+	return res, nil
+}
+
+// explainCombination shows the value of the linear combination with the values of its attributes,
+// e.g. "12.500000 (age = 30.000000, hours-per-week = 40.000000)".
+func explainCombination(instance *data.Instance, combination *data.LinearCombinationAttribute, val data.Value) string {
+	var parts []string
+	for _, attr := range combination.Attributes {
+		value := "?"
+		if v := instance.GetValueByAttr(attr); v != nil && !v.IsMissing() {
+			value = v.Log()
+		}
+		parts = append(parts, attr.Name()+" = "+value)
+	}
+	return val.Log() + " (" + strings.Join(parts, ", ") + ")"
+}
+
+// String renders the explanation as human-readable lines, one line per visited node.
+func (e *Explanation) String() string {
+	var sb strings.Builder
+	for i, step := range e.Steps {
+		sb.WriteString(fmt.Sprintf("%d. [node %d] ", i+1, step.NodeId))
+		switch {
+		case step.Condition == "":
+			sb.WriteString("root")
+		case step.MissingValueFallback:
+			sb.WriteString(fmt.Sprintf("%s is missing, took prioritized branch <%s>", step.Attribute, step.Condition))
+		case step.PrioritizedFallback:
+			sb.WriteString(fmt.Sprintf("%s = %s met no condition, took prioritized branch <%s>", step.Attribute, step.Value, step.Condition))
+		default:
+			sb.WriteString(fmt.Sprintf("%s = %s met <%s>", step.Attribute, step.Value, step.Condition))
+		}
+		if len(step.ClassCount) > 0 {
+			sb.WriteString(", class distribution: " + logClassCount(step.ClassCount))
+		}
+		if step.LeafClass != "" {
+			sb.WriteString(", predict: " + step.LeafClass)
+		}
+		if step.Model != "" {
+			sb.WriteString(", model: " + step.Model)
+		}
+		sb.WriteString("\n")
+	}
+	if len(e.Probabilities) > 0 {
+		sb.WriteString(fmt.Sprintf("smoothed model prediction: %s, probabilities: %s\n", e.PredictedClass, logClassCount(e.Probabilities)))
+	}
+	return sb.String()
+}
+
+func logClassCount(classCount map[string]float64) string {
+	var classes []string
+	for class := range classCount {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	var parts []string
+	for _, class := range classes {
+		parts = append(parts, fmt.Sprintf("%s=%.2f", class, classCount[class]))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
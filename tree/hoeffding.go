@@ -0,0 +1,377 @@
+package tree
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// HoeffdingOptions controls when the leaves of a HoeffdingTree split.
+type HoeffdingOptions struct {
+	GracePeriod        float64 // weighted number of instances a leaf learns between split attempts, 200 if 0
+	Confidence         float64 // δ, the probability that the split differs from the one of the whole stream, 1e-7 if 0
+	TieThreshold       float64 // τ, split anyway if the Hoeffding bound falls below it, 0.05 if 0
+	NumericSplitPoints int     // number of thresholds tried between the min and max of numeric values, 10 if 0
+}
+
+// HoeffdingTree learns a tree from a stream of instances, one at a time, as the VFDT (Hoeffding tree) of Domingos
+// and Hulten. Every leaf keeps the sufficient statistics of the instances reaching it: the class counts of every
+// nominal value, and a normal distribution of the numeric values of every class. Every GracePeriod instances, a leaf
+// splits on the attribute with the best information gain, if the gain exceeds the one of the second best attribute
+// (or of not splitting) by the Hoeffding bound ε = sqrt(R² ln(1/δ) / 2n), where R = log2(number of classes).
+// Nominal attributes split into a child of each value seen, numeric attributes into a LessThan and a GreaterThanEq
+// child. MaxDepth and MinSamplesSplit of the config limit the splits.
+//
+// The learned Tree uses the same conditions as BuildTree, so it predicts, explains and serializes the same way.
+// The sufficient statistics are not saved with the tree, ResumeHoeffdingTree continues learning from a saved tree
+// with empty statistics at its leaves. A HoeffdingTree is not safe for concurrent use.
+type HoeffdingTree struct {
+	conf  *config.Config
+	opts  HoeffdingOptions
+	tree  *Tree
+	stats map[*Node]*hoeffdingStats // of the leaves
+}
+
+func NewHoeffdingTree(conf *config.Config, attributes []data.Attribute, class *data.NominalAttribute, opts *HoeffdingOptions) *HoeffdingTree {
+	tr := &Tree{
+		Attributes: attributes,
+		Class:      class,
+		RootNode:   &Node{ClassCount: make(map[string]float64)},
+	}
+	tr.RootNode.UniqId()
+	return newHoeffdingTree(conf, tr, opts)
+}
+
+// ResumeHoeffdingTree continues learning from a tree, e.g. restored from a model file. The leaves of the tree start
+// with empty sufficient statistics, the class counts of the nodes are kept. Multi-output trees and model trees are
+// not supported.
+func ResumeHoeffdingTree(conf *config.Config, tr *Tree, opts *HoeffdingOptions) (*HoeffdingTree, error) {
+	if tr.Class == nil {
+		return nil, fmt.Errorf("tree has no class")
+	}
+	if len(tr.Targets) > 0 || tr.IsModelTree() {
+		return nil, fmt.Errorf("multi-output trees and model trees cannot be learned incrementally")
+	}
+	var initClassCount func(node *Node)
+	initClassCount = func(node *Node) {
+		if node.ClassCount == nil {
+			node.ClassCount = make(map[string]float64)
+		}
+		for _, child := range node.Children {
+			initClassCount(child)
+		}
+	}
+	initClassCount(tr.RootNode)
+	// the nodes split from now on get ids above those of the tree
+	tr.AssignUniqIds()
+	return newHoeffdingTree(conf, tr, opts), nil
+}
+
+func newHoeffdingTree(conf *config.Config, tr *Tree, opts *HoeffdingOptions) *HoeffdingTree {
+	h := &HoeffdingTree{conf: conf, tree: tr, stats: make(map[*Node]*hoeffdingStats)}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.GracePeriod == 0 {
+		h.opts.GracePeriod = 200
+	}
+	if h.opts.Confidence == 0 {
+		h.opts.Confidence = 1e-7
+	}
+	if h.opts.TieThreshold == 0 {
+		h.opts.TieThreshold = 0.05
+	}
+	if h.opts.NumericSplitPoints == 0 {
+		h.opts.NumericSplitPoints = 10
+	}
+	return h
+}
+
+// Tree returns the tree learned so far, with the impurity importance of its splits. The tree is updated by the
+// instances learned afterward.
+func (h *HoeffdingTree) Tree() *Tree {
+	h.tree.FeatureImportance = &FeatureImportance{Impurity: h.tree.ImpurityImportance()}
+	return h.tree
+}
+
+// Learn updates the tree with an instance, which is transformed by the pipeline of the tree first. Instances
+// without a class value are ignored.
+func (h *HoeffdingTree) Learn(instance *data.Instance) error {
+	instance, err := h.tree.Transform(instance)
+	if err != nil {
+		return err
+	}
+	if instance.ClassValue.IsMissing() {
+		return nil
+	}
+	if len(instance.TargetValues) > 0 {
+		return fmt.Errorf("multi-output instances cannot be learned incrementally")
+	}
+	class := instance.ClassValue.Value().(string)
+
+	var (
+		node  = h.tree.RootNode
+		level = 1
+	)
+	for len(node.Children) > 0 {
+		node.ClassCount[class]++
+		child := node.GetRelatedChild(instance)
+		if child == nil {
+			return fmt.Errorf("unknown error, cannot learn instance at node %d", node.UniqId())
+		}
+		child.ClassCount[class]++
+		updatePrioritized(node.Children)
+		child.ClassCount[class]-- // counted again below, as the node of the next level
+		node = child
+		level++
+	}
+	node.ClassCount[class]++
+	node.LeafClass = majorityClass(node.ClassCount)
+
+	stats, ok := h.stats[node]
+	if !ok {
+		stats = newHoeffdingStats()
+		h.stats[node] = stats
+	}
+	stats.add(h.tree.Attributes, instance, class)
+	if stats.weight-stats.lastAttempt < h.opts.GracePeriod {
+		return nil
+	}
+	stats.lastAttempt = stats.weight
+	if level >= h.conf.MaxDepth || node.GetSampleCount() < float64(h.conf.MinSamplesSplit) || len(node.ClassCount) < 2 {
+		return nil
+	}
+	h.trySplit(node, stats)
+	return nil
+}
+
+// updatePrioritized prioritizes the child that most training instances reached.
+func updatePrioritized(children []*Node) {
+	best := 0
+	for i, child := range children {
+		if child.GetSampleCount() > children[best].GetSampleCount() {
+			best = i
+		}
+	}
+	for i, child := range children {
+		child.IsPrioritized = i == best
+	}
+}
+
+// hoeffdingSplit is a candidate split of a leaf, with the class counts of its children.
+type hoeffdingSplit struct {
+	gain        float64
+	conditions  []Condition
+	classCounts []map[string]float64
+}
+
+// trySplit splits the leaf by the best candidate split if the Hoeffding bound is met.
+func (h *HoeffdingTree) trySplit(leaf *Node, stats *hoeffdingStats) {
+	var (
+		best, second float64 // the gain of not splitting is 0
+		bestSplit    *hoeffdingSplit
+		n            = leaf.GetSampleCount()
+	)
+	for i, attr := range h.tree.Attributes {
+		var split *hoeffdingSplit
+		if attr.Type().IsNumeric() {
+			split = stats.numericSplit(attr, stats.numeric[i], n, h.opts.NumericSplitPoints)
+		} else {
+			split = stats.nominalSplit(attr, stats.nominal[i], n)
+		}
+		if split == nil {
+			continue
+		}
+		switch {
+		case split.gain > best:
+			second, best, bestSplit = best, split.gain, split
+		case split.gain > second:
+			second = split.gain
+		}
+	}
+	if bestSplit == nil {
+		return
+	}
+	r := math.Log2(float64(max(len(h.tree.Class.AcceptedValues), 2)))
+	epsilon := math.Sqrt(r * r * math.Log(1/h.opts.Confidence) / (2 * n))
+	if best-second <= epsilon && epsilon >= h.opts.TieThreshold {
+		return
+	}
+
+	config.Logf("[Hoeffding %d] Split node by %d conditions, gain=%f, second=%f, epsilon=%f, n_instance=%.0f", leaf.UniqId(), len(bestSplit.conditions), best, second, epsilon, n)
+	for i, condition := range bestSplit.conditions {
+		child := &Node{
+			Condition:  condition,
+			ClassCount: bestSplit.classCounts[i],
+			LeafClass:  majorityClass(bestSplit.classCounts[i]),
+		}
+		child.UniqId()
+		leaf.Children = append(leaf.Children, child)
+	}
+	updatePrioritized(leaf.Children)
+	leaf.SplitGain = best
+	leaf.LeafClass = ""
+	delete(h.stats, leaf)
+}
+
+// hoeffdingStats are the sufficient statistics of the instances reaching a leaf, by attribute index.
+type hoeffdingStats struct {
+	weight      float64
+	lastAttempt float64                               // weight when the split was last tried
+	nominal     map[int]map[string]map[string]float64 // value -> class -> weight
+	numeric     map[int]map[string]*hoeffdingGaussian // class -> distribution of the values
+}
+
+func newHoeffdingStats() *hoeffdingStats {
+	return &hoeffdingStats{
+		nominal: make(map[int]map[string]map[string]float64),
+		numeric: make(map[int]map[string]*hoeffdingGaussian),
+	}
+}
+
+func (s *hoeffdingStats) add(attributes []data.Attribute, instance *data.Instance, class string) {
+	s.weight++
+	for i, attr := range attributes {
+		value := instance.GetValueByAttr(attr)
+		if value == nil || value.IsMissing() {
+			continue
+		}
+		if attr.Type().IsNumeric() {
+			if s.numeric[i] == nil {
+				s.numeric[i] = make(map[string]*hoeffdingGaussian)
+			}
+			if s.numeric[i][class] == nil {
+				s.numeric[i][class] = &hoeffdingGaussian{min: math.Inf(1), max: math.Inf(-1)}
+			}
+			s.numeric[i][class].add(value.Value().(float64))
+			continue
+		}
+		v := value.Value().(string)
+		if s.nominal[i] == nil {
+			s.nominal[i] = make(map[string]map[string]float64)
+		}
+		if s.nominal[i][v] == nil {
+			s.nominal[i][v] = make(map[string]float64)
+		}
+		s.nominal[i][v][class]++
+	}
+}
+
+// nominalSplit returns the split into a child of each value seen, nil if fewer than two values are seen.
+// As in BuildTree, the gain is that of the instances knowing the value, scaled by their fraction.
+func (s *hoeffdingStats) nominalSplit(attr data.Attribute, counts map[string]map[string]float64, n float64) *hoeffdingSplit {
+	if len(counts) < 2 {
+		return nil
+	}
+	values := make([]string, 0, len(counts))
+	for v := range counts {
+		values = append(values, v)
+	}
+	slices.Sort(values)
+	res := &hoeffdingSplit{}
+	for _, v := range values {
+		res.conditions = append(res.conditions, NewIsOneOfCondition(attr, []string{v}))
+		res.classCounts = append(res.classCounts, copyClassCount(counts[v]))
+	}
+	res.gain = classCountsGain(res.classCounts, n)
+	return res
+}
+
+// numericSplit returns the best split by the thresholds equally spaced between the min and max of the values, of
+// which the class counts are estimated by the normal distributions of the classes. Nil if the values are constant.
+func (s *hoeffdingStats) numericSplit(attr data.Attribute, distributions map[string]*hoeffdingGaussian, n float64, points int) *hoeffdingSplit {
+	var (
+		low  = math.Inf(1)
+		high = math.Inf(-1)
+	)
+	for _, g := range distributions {
+		low, high = min(low, g.min), max(high, g.max)
+	}
+	if !(low < high) {
+		return nil
+	}
+	var res *hoeffdingSplit
+	for k := 1; k <= points; k++ {
+		threshold := low + (high-low)*float64(k)/float64(points+1)
+		left, right := make(map[string]float64), make(map[string]float64)
+		for class, g := range distributions {
+			below := g.weightBelow(threshold)
+			left[class] = below
+			right[class] = g.weight - below
+		}
+		classCounts := []map[string]float64{left, right}
+		if gain := classCountsGain(classCounts, n); res == nil || gain > res.gain {
+			res = &hoeffdingSplit{
+				gain:        gain,
+				conditions:  []Condition{NewLessThanCondition(attr, threshold), NewGreaterThanEqCondition(attr, threshold)},
+				classCounts: classCounts,
+			}
+		}
+	}
+	return res
+}
+
+// classCountsGain returns the information gain of splitting the instances knowing the value into the class counts
+// of the children, scaled by the fraction of n, the weight of all the instances.
+func classCountsGain(classCounts []map[string]float64, n float64) float64 {
+	var (
+		known    = make(map[string]float64)
+		total    = 0.0
+		children = 0.0
+	)
+	for _, classCount := range classCounts {
+		weight := 0.0
+		for class, count := range classCount {
+			known[class] += count
+			weight += count
+		}
+		total += weight
+		children += Entropy(classCount) * weight
+	}
+	if total == 0 || n == 0 {
+		return 0
+	}
+	return (Entropy(known) - children/total) * total / n
+}
+
+func copyClassCount(classCount map[string]float64) map[string]float64 {
+	res := make(map[string]float64, len(classCount))
+	for class, count := range classCount {
+		res[class] = count
+	}
+	return res
+}
+
+// hoeffdingGaussian is the normal distribution of the values of a class, by the running mean and variance of Welford.
+type hoeffdingGaussian struct {
+	weight, mean, m2 float64
+	min, max         float64
+}
+
+func (g *hoeffdingGaussian) add(v float64) {
+	g.weight++
+	delta := v - g.mean
+	g.mean += delta / g.weight
+	g.m2 += delta * (v - g.mean)
+	g.min, g.max = min(g.min, v), max(g.max, v)
+}
+
+// weightBelow estimates the weight of the values less than v.
+func (g *hoeffdingGaussian) weightBelow(v float64) float64 {
+	switch {
+	case v <= g.min:
+		return 0
+	case v > g.max:
+		return g.weight
+	}
+	std := math.Sqrt(g.m2 / g.weight)
+	if std == 0 {
+		if v > g.mean {
+			return g.weight
+		}
+		return 0
+	}
+	return g.weight * 0.5 * math.Erfc(-(v-g.mean)/(std*math.Sqrt2))
+}
@@ -0,0 +1,115 @@
+package tree
+
+import (
+	"DecisionTree/data"
+	"fmt"
+	"math/rand"
+)
+
+// FeatureImportance holds the normalized importance score of each attribute, scores of an importance type sum up to 1.
+type FeatureImportance struct {
+	Impurity    map[string]float64 `json:"impurity,omitempty"`
+	Permutation map[string]float64 `json:"permutation,omitempty"`
+}
+
+// ImpurityImportance sums up the split gain of each attribute, weighted by the number of training instances
+// reaching the split node.
+// Trees restored from older model files do not record the split gain, the result is empty in that case.
+func (t *Tree) ImpurityImportance() map[string]float64 {
+	res := make(map[string]float64)
+	for _, attr := range t.Attributes {
+		res[attr.Name()] = 0
+	}
+	total := t.RootNode.GetSampleCount()
+	if total == 0 {
+		return res
+	}
+	t.RootNode.accumulateImpurityImportance(res, total)
+	return normalizeImportance(res)
+}
+
+func (n *Node) accumulateImpurityImportance(importance map[string]float64, total float64) {
+	if len(n.Children) == 0 {
+		return
+	}
+	attr := n.Children[0].Condition.Attr()
+	if part, ok := attr.(*data.DateTimePartAttribute); ok {
+		attr = part.Parent // the splits by the parts count for the datetime attribute
+	}
+	if combination, ok := attr.(*data.LinearCombinationAttribute); ok {
+		// the oblique splits count for each of their attributes equally
+		for _, a := range combination.Attributes {
+			importance[a.Name()] += n.SplitGain * n.GetSampleCount() / total / float64(len(combination.Attributes))
+		}
+	} else {
+		importance[attr.Name()] += n.SplitGain * n.GetSampleCount() / total
+	}
+	for _, child := range n.Children {
+		child.accumulateImpurityImportance(importance, total)
+	}
+}
+
+// PermutationImportance measures how much the accuracy drops when values of an attribute are shuffled among the
+// instances of the value table. Each attribute is shuffled `repeats` times and the drops are averaged.
+// Negative drops (shuffling helps) are considered as 0 before normalizing.
+func PermutationImportance(tr *Tree, valueTable *data.ValueTable, repeats int, seed int64) (map[string]float64, error) {
+	if len(valueTable.Instances) == 0 {
+		return nil, fmt.Errorf("no instances")
+	}
+	if repeats < 1 {
+		repeats = 1
+	}
+	baseline, err := TestRun(tr, valueTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to do baseline test run: %w", err)
+	}
+
+	var (
+		rnd = rand.New(rand.NewSource(seed))
+		res = make(map[string]float64)
+	)
+	for attrIndex, value := range valueTable.Instances[0].AttributeValues {
+		drop := 0.0
+		for r := 0; r < repeats; r++ {
+			permuted := permuteAttribute(rnd, valueTable, attrIndex)
+			permutedRes, err := TestRun(tr, permuted)
+			if err != nil {
+				return nil, fmt.Errorf("failed to do test run on permuted attribute '%s': %w", value.Attribute().Name(), err)
+			}
+			drop += baseline.Accuracy - permutedRes.Accuracy
+		}
+		res[value.Attribute().Name()] = max(drop/float64(repeats), 0)
+	}
+	return normalizeImportance(res), nil
+}
+
+// permuteAttribute returns a shallow copy of the value table whose values of the attribute are shuffled.
+func permuteAttribute(rnd *rand.Rand, valueTable *data.ValueTable, attrIndex int) *data.ValueTable {
+	order := rnd.Perm(len(valueTable.Instances))
+	res := &data.ValueTable{}
+	for i, instance := range valueTable.Instances {
+		values := make([]data.Value, len(instance.AttributeValues))
+		copy(values, instance.AttributeValues)
+		values[attrIndex] = valueTable.Instances[order[i]].AttributeValues[attrIndex]
+		res.Instances = append(res.Instances, &data.Instance{
+			AttributeValues: values,
+			ClassValue:      instance.ClassValue,
+			TargetValues:    instance.TargetValues,
+		})
+	}
+	return res
+}
+
+func normalizeImportance(importance map[string]float64) map[string]float64 {
+	total := 0.0
+	for _, v := range importance {
+		total += v
+	}
+	if total == 0 {
+		return importance
+	}
+	for k, v := range importance {
+		importance[k] = v / total
+	}
+	return importance
+}
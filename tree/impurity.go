@@ -2,10 +2,12 @@ package tree
 
 import (
 	"DecisionTree/data"
+	"DecisionTree/utils"
 	"math"
 )
 
-// calculateEntropy calculates the entropy of a set of instances.
+// calculateEntropy calculates the entropy of a set of instances, summed with the entropy of every additional target
+// of multi-output data sets.
 // The fastClassFrequencyCnt is the frequency count of the majority class in the parent node, input a non-empty list set a fast-forward frequency.
 func calculateEntropy(instances []*WeightedInstance, allInstanceCount float64, fastClassFrequencyCnt map[string]float64) float64 {
 	if len(instances) == 0 {
@@ -33,16 +35,25 @@ func calculateEntropy(instances []*WeightedInstance, allInstanceCount float64, f
 		}
 		entropy -= frequency * math.Log2(frequency)
 	}
-	return entropy
+	return entropy + targetsEntropy(instances[0].Instance, fastClassFrequencyCnt)
 }
 
+// calculateClassFrequencyCnt counts the class values, and the target values keyed by targetClassKey.
 func calculateClassFrequencyCnt(instances []*WeightedInstance) map[string]float64 {
 	// calculate frequency
 	valueCount := make(map[string]float64)
-	totalCount := 0.0
 	for _, instance := range instances {
-		valueCount[instance.Instance.ClassValue.Value().(string)] += instance.Weight
-		totalCount += instance.Weight
+		addClassCount(valueCount, instance.Instance, instance.Weight)
 	}
 	return valueCount
 }
+
+// Entropy calculates the entropy of the class distribution of the node.
+func (n *Node) Entropy() float64 {
+	return Entropy(n.ClassCount)
+}
+
+// Entropy calculates the entropy of a class distribution, given as the (weighted) count of each class value.
+func Entropy(classCount map[string]float64) float64 {
+	return utils.Entropy(classCount)
+}
@@ -0,0 +1,71 @@
+package tree
+
+import "math"
+
+// calculateMetrics calculates the metrics derived from the per-class counts and the confusion matrix.
+func (r *TestResults) calculateMetrics() {
+	var (
+		total   = float64(r.TotalDataCount)
+		classes = make(map[string]bool)
+	)
+	for class := range r.ClassDataCount {
+		classes[class] = true
+	}
+	for class := range r.ClassPredictCount {
+		classes[class] = true
+	}
+
+	// F1 scores
+	r.ClassF1 = make(map[string]float64)
+	var macroF1, weightedF1 float64
+	for class := range classes {
+		var precision, recall float64
+		if r.ClassPredictCount[class] > 0 {
+			precision = float64(r.ClassCorrectCount[class]) / float64(r.ClassPredictCount[class])
+		}
+		if r.ClassDataCount[class] > 0 {
+			recall = float64(r.ClassCorrectCount[class]) / float64(r.ClassDataCount[class])
+		}
+		var f1 float64
+		if precision+recall > 0 {
+			f1 = 2 * precision * recall / (precision + recall)
+		}
+		r.ClassF1[class] = f1
+		macroF1 += f1
+		weightedF1 += f1 * float64(r.ClassDataCount[class])
+	}
+	if len(classes) > 0 {
+		r.MacroF1 = macroF1 / float64(len(classes))
+	}
+	if total > 0 {
+		r.WeightedF1 = weightedF1 / total
+	}
+	// every instance is predicted as exactly one class, so micro precision and micro recall equal the accuracy
+	r.MicroF1 = r.Accuracy
+
+	var balancedAccuracy float64
+	for _, recall := range r.ClassRecall {
+		balancedAccuracy += recall
+	}
+	if len(r.ClassRecall) > 0 {
+		r.BalancedAccuracy = balancedAccuracy / float64(len(r.ClassRecall))
+	}
+
+	// MCC and kappa, both compare the agreement of the predictions with the agreement expected by chance:
+	// sum over classes of (predicted count * data count)
+	var chanceAgreement, sumPredictSquare, sumDataSquare float64
+	for class := range classes {
+		predicted, actual := float64(r.ClassPredictCount[class]), float64(r.ClassDataCount[class])
+		chanceAgreement += predicted * actual
+		sumPredictSquare += predicted * predicted
+		sumDataSquare += actual * actual
+	}
+	correct := float64(r.CorrectCount)
+	if denominator := math.Sqrt((total*total - sumPredictSquare) * (total*total - sumDataSquare)); denominator > 0 {
+		r.MCC = (correct*total - chanceAgreement) / denominator
+	}
+	if total > 0 && total*total != chanceAgreement {
+		expected := chanceAgreement / (total * total)
+		r.Kappa = (r.Accuracy - expected) / (1 - expected)
+	}
+}
@@ -0,0 +1,400 @@
+package tree
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultModelSmoothing is the k of the smoothing of model trees if not configured, as in M5.
+const defaultModelSmoothing = 15
+
+// LinearModel predicts the indicator of every class (1 for the class of the instance, 0 for the others) by a linear
+// regression over continuous attributes, as the model trees of M5 used for classification.
+// Model trees fit a linear model at every node, the leaves predict by their models smoothed along the path.
+type LinearModel struct {
+	Attributes   []string             `json:"attributes,omitempty"`
+	Means        []float64            `json:"means,omitempty"` // replace the missing values of the attributes
+	Intercepts   map[string]float64   `json:"intercepts"`
+	Coefficients map[string][]float64 `json:"coefficients,omitempty"` // by class, in the order of the attributes
+}
+
+// Predict returns the output of the regression of every class, which is not clipped to [0, 1].
+func (m *LinearModel) Predict(instance *data.Instance) map[string]float64 {
+	values := make([]float64, len(m.Attributes))
+	for i, name := range m.Attributes {
+		values[i] = m.Means[i]
+		if value := instance.GetValueByAttr(data.NewContinuousAttribute(name)); value != nil && !value.IsMissing() {
+			values[i] = value.Value().(float64)
+		}
+	}
+	res := make(map[string]float64)
+	for class, intercept := range m.Intercepts {
+		res[class] = intercept
+		for i, coefficient := range m.Coefficients[class] {
+			res[class] += coefficient * values[i]
+		}
+	}
+	return res
+}
+
+// String shows the regression of every class, e.g. "no = 0.8800 - 0.0300*age; yes = 0.1200 + 0.0300*age".
+func (m *LinearModel) String() string {
+	classes := make([]string, 0, len(m.Intercepts))
+	for class := range m.Intercepts {
+		classes = append(classes, class)
+	}
+	slices.Sort(classes)
+	var parts []string
+	for _, class := range classes {
+		var sb strings.Builder
+		sb.WriteString(class + " = " + strconv.FormatFloat(m.Intercepts[class], 'f', 4, 64))
+		for i, coefficient := range m.Coefficients[class] {
+			if coefficient < 0 {
+				sb.WriteString(" - " + strconv.FormatFloat(-coefficient, 'f', 4, 64))
+			} else {
+				sb.WriteString(" + " + strconv.FormatFloat(coefficient, 'f', 4, 64))
+			}
+			sb.WriteString("*" + m.Attributes[i])
+		}
+		parts = append(parts, sb.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// parameterCount is the number of parameters of the regression of a class.
+func (m *LinearModel) parameterCount() int {
+	return len(m.Attributes) + 1
+}
+
+// IsModelTree tells whether the leaves of the tree predict by linear models, see config.Config.ModelTree.
+func (t *Tree) IsModelTree() bool {
+	return t.RootNode != nil && t.RootNode.Model != nil
+}
+
+// modelProbabilities returns the class probabilities of the model tree for the instance reaching the leaf by the
+// path: the output of the model of the leaf is smoothed by the models of the nodes up to the root, as in M5,
+// p' = (n*p + k*q) / (n + k), where n is the training instance count of the node below and q the output of the node
+// above. The outputs are clipped to [0, 1] and normalized.
+func (t *Tree) modelProbabilities(path []*Node, instance *data.Instance) map[string]float64 {
+	leaf := path[len(path)-1]
+	if leaf.Model == nil {
+		return leaf.ClassProbabilities()
+	}
+	res := leaf.Model.Predict(instance)
+	for i := len(path) - 2; i >= 0 && t.ModelSmoothing > 0; i-- {
+		if path[i].Model == nil {
+			continue
+		}
+		var (
+			n = path[i+1].GetSampleCount()
+			q = path[i].Model.Predict(instance)
+		)
+		for class, p := range res {
+			res[class] = (n*p + t.ModelSmoothing*q[class]) / (n + t.ModelSmoothing)
+		}
+	}
+	total := 0.0
+	for class, p := range res {
+		res[class] = min(max(p, 0), 1)
+		total += res[class]
+	}
+	if total == 0 {
+		return leaf.ClassProbabilities()
+	}
+	for class := range res {
+		res[class] /= total
+	}
+	return res
+}
+
+// modelSmoothing returns the k of the smoothing of the config, 0 if disabled.
+func modelSmoothing(conf *config.Config) float64 {
+	switch {
+	case conf.ModelTreeSmoothing < 0:
+		return 0
+	case conf.ModelTreeSmoothing == 0:
+		return defaultModelSmoothing
+	default:
+		return conf.ModelTreeSmoothing
+	}
+}
+
+// fitNodeModel fits the linear model of the node on its training instances. It starts from all the continuous
+// attributes that vary at the node, and drops the attributes one at a time as long as the adjusted error does not
+// increase, as in M5.
+func fitNodeModel(node *Node) (*LinearModel, error) {
+	instances := node.instances
+	if len(instances) == 0 {
+		return nil, nil
+	}
+	classAttr, ok := instances[0].Instance.ClassValue.Attribute().(*data.NominalAttribute)
+	if !ok {
+		return nil, fmt.Errorf("class must be a nominal attribute")
+	}
+	var attrs []data.Attribute
+	for _, value := range instances[0].Instance.AttributeValues {
+		if value.Attribute().Type() == data.Continuous {
+			attrs = append(attrs, value.Attribute())
+		}
+	}
+
+	d := newModelDesign(instances, attrs, classAttr.AcceptedValues)
+	var selected []int
+	for j := range attrs {
+		if d.variance[j] > 0 {
+			selected = append(selected, j)
+		}
+	}
+	model, err := d.fit(selected)
+	if err != nil {
+		return nil, err
+	}
+	modelError := adjustedModelError(instances, model)
+	for len(selected) > 0 {
+		var (
+			bestSelected []int
+			bestModel    *LinearModel
+			bestError    = modelError
+		)
+		for i := range selected {
+			candidate := slices.Delete(slices.Clone(selected), i, i+1)
+			candidateModel, err := d.fit(candidate)
+			if err != nil {
+				return nil, err
+			}
+			if candidateError := adjustedModelError(instances, candidateModel); candidateError <= bestError {
+				bestSelected, bestModel, bestError = candidate, candidateModel, candidateError
+			}
+		}
+		if bestModel == nil {
+			break
+		}
+		selected, model, modelError = bestSelected, bestModel, bestError
+	}
+	return model, nil
+}
+
+// pruneModelTree replaces the subtree of the node by the model of the node if the adjusted error of the model is not
+// larger than the error of the subtree, from the bottom up as in M5. Returns the error of the node after pruning.
+func pruneModelTree(conf *config.Config, node *Node) (float64, error) {
+	if node.Model == nil {
+		return math.Inf(1), nil
+	}
+	modelError := adjustedModelError(node.instances, node.Model)
+	if len(node.Children) == 0 {
+		return modelError, nil
+	}
+	var (
+		subtreeError = 0.0
+		total        = 0.0
+	)
+	for _, child := range node.Children {
+		childError, err := pruneModelTree(conf, child)
+		if err != nil {
+			return 0, err
+		}
+		weight := SumInstanceWeights(child.instances)
+		if weight > 0 {
+			subtreeError += childError * weight
+			total += weight
+		}
+	}
+	if total > 0 {
+		subtreeError /= total
+	}
+	if modelError <= subtreeError {
+		node.Children = nil
+		node.SplitGain = 0
+		if err := postProcessNode(conf, node); err != nil {
+			return 0, fmt.Errorf("failed to post process node: %w", err)
+		}
+		return modelError, nil
+	}
+	return subtreeError, nil
+}
+
+// modelDesign holds the values of the continuous attributes of the instances of a node, missing values replaced by
+// the means of the attributes, and the class indicators.
+type modelDesign struct {
+	instances []*WeightedInstance
+	attrs     []data.Attribute
+	classes   []string
+	values    [][]float64 // by instance, then by attribute
+	mean      []float64
+	variance  []float64
+}
+
+func newModelDesign(instances []*WeightedInstance, attrs []data.Attribute, classes []string) *modelDesign {
+	d := &modelDesign{
+		instances: instances,
+		attrs:     attrs,
+		classes:   classes,
+		values:    make([][]float64, len(instances)),
+		mean:      make([]float64, len(attrs)),
+		variance:  make([]float64, len(attrs)),
+	}
+	known := make([]float64, len(attrs))
+	for i, instance := range instances {
+		d.values[i] = make([]float64, len(attrs))
+		for j, attr := range attrs {
+			d.values[i][j] = math.NaN()
+			if value := instance.Instance.GetValueByAttr(attr); value != nil && !value.IsMissing() {
+				d.values[i][j] = value.Value().(float64)
+				d.mean[j] += d.values[i][j] * instance.Weight
+				known[j] += instance.Weight
+			}
+		}
+	}
+	for j := range attrs {
+		if known[j] > 0 {
+			d.mean[j] /= known[j]
+		}
+	}
+	for i, instance := range instances {
+		for j := range attrs {
+			if math.IsNaN(d.values[i][j]) {
+				d.values[i][j] = d.mean[j]
+			}
+			d.variance[j] += (d.values[i][j] - d.mean[j]) * (d.values[i][j] - d.mean[j]) * instance.Weight
+		}
+	}
+	return d
+}
+
+// fit fits the weighted least squares regression of every class indicator over the selected attributes.
+// The attributes are centered, so the intercept is the weighted frequency of the class, and a tiny ridge keeps the
+// normal equations solvable when attributes are collinear.
+func (d *modelDesign) fit(selected []int) (*LinearModel, error) {
+	var (
+		p     = len(selected)
+		xtx   = make([][]float64, p)
+		total = SumInstanceWeights(d.instances)
+		model = &LinearModel{
+			Intercepts:   make(map[string]float64),
+			Coefficients: make(map[string][]float64),
+		}
+	)
+	for _, j := range selected {
+		model.Attributes = append(model.Attributes, d.attrs[j].Name())
+		model.Means = append(model.Means, d.mean[j])
+	}
+	for a := range xtx {
+		xtx[a] = make([]float64, p)
+	}
+	for i, instance := range d.instances {
+		for a, ja := range selected {
+			for b, jb := range selected {
+				xtx[a][b] += (d.values[i][ja] - d.mean[ja]) * (d.values[i][jb] - d.mean[jb]) * instance.Weight
+			}
+		}
+	}
+	for a := range xtx {
+		xtx[a][a] += 1e-9*xtx[a][a] + 1e-12
+	}
+
+	for _, class := range d.classes {
+		var (
+			xty       = make([]float64, p)
+			frequency = 0.0
+		)
+		for i, instance := range d.instances {
+			if instance.Instance.ClassValue.Value().(string) != class {
+				continue
+			}
+			frequency += instance.Weight
+			for a, ja := range selected {
+				xty[a] += (d.values[i][ja] - d.mean[ja]) * instance.Weight
+			}
+		}
+		if total > 0 {
+			frequency /= total
+		}
+		coefficients, err := solveLinearSystem(xtx, xty)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fit the model of class '%s': %w", class, err)
+		}
+		// back to the raw values, f = frequency + Σ w * (x - mean)
+		intercept := frequency
+		for a, ja := range selected {
+			intercept -= coefficients[a] * d.mean[ja]
+		}
+		model.Intercepts[class] = intercept
+		if p > 0 {
+			model.Coefficients[class] = coefficients
+		}
+	}
+	return model, nil
+}
+
+// adjustedModelError is the weighted mean absolute error of the class indicators, multiplied by (n + v) / (n - v)
+// where n is the number of instances and v the number of parameters, as in M5. It is 10 times the error if n <= v.
+func adjustedModelError(instances []*WeightedInstance, model *LinearModel) float64 {
+	var (
+		sum   = 0.0
+		total = 0.0
+	)
+	for _, instance := range instances {
+		predicted := model.Predict(instance.Instance)
+		actual := instance.Instance.ClassValue.Value().(string)
+		for class, p := range predicted {
+			if class == actual {
+				sum += math.Abs(1-p) * instance.Weight
+			} else {
+				sum += math.Abs(p) * instance.Weight
+			}
+		}
+		total += instance.Weight
+	}
+	if total == 0 {
+		return 0
+	}
+	var (
+		n = float64(len(instances))
+		v = float64(model.parameterCount())
+	)
+	if n <= v {
+		return 10 * sum / total
+	}
+	return sum / total * (n + v) / (n - v)
+}
+
+// solveLinearSystem solves a x = b by Gaussian elimination with partial pivoting, a and b are not modified.
+func solveLinearSystem(a [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = append(slices.Clone(a[i]), b[i])
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if m[pivot][col] == 0 {
+			return nil, fmt.Errorf("singular system")
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for row := col + 1; row < n; row++ {
+			factor := m[row][col] / m[col][col]
+			for k := col; k <= n; k++ {
+				m[row][k] -= factor * m[col][k]
+			}
+		}
+	}
+	res := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		res[row] = m[row][n]
+		for k := row + 1; k < n; k++ {
+			res[row] -= m[row][k] * res[k]
+		}
+		res[row] /= m[row][row]
+	}
+	return res, nil
+}
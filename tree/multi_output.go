@@ -0,0 +1,195 @@
+package tree
+
+import (
+	"DecisionTree/data"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Multi-output trees predict the additional targets of the instances (data.Instance.TargetValues) besides the class.
+// The class counts of the splits are keyed by targetClassKey, so that the entropy is summed across the class and
+// every target, and each node records the class distribution of every target.
+
+// targetClassKey is the key of a value of the target in the class counts, the class values are keyed by themselves.
+func targetClassKey(target int, value string) string {
+	return "\x00" + strconv.Itoa(target) + "\x00" + value
+}
+
+// addClassCount adds the weight of the class value and of every known target value of the instance.
+func addClassCount(classCount map[string]float64, instance *data.Instance, weight float64) {
+	classCount[instance.ClassValue.Value().(string)] += weight
+	for t, value := range instance.TargetValues {
+		if !value.IsMissing() {
+			classCount[targetClassKey(t, value.Value().(string))] += weight
+		}
+	}
+}
+
+// targetsEntropy sums the entropy of every target of the instance, of which the values are counted by addClassCount.
+// The instances missing a target value do not count for that target.
+func targetsEntropy(instance *data.Instance, classCount map[string]float64) float64 {
+	res := 0.0
+	for t, value := range instance.TargetValues {
+		var (
+			acceptedValues = value.Attribute().(*data.NominalAttribute).AcceptedValues
+			total          = 0.0
+		)
+		for _, v := range acceptedValues {
+			total += classCount[targetClassKey(t, v)]
+		}
+		if total == 0 {
+			continue
+		}
+		for _, v := range acceptedValues {
+			frequency := classCount[targetClassKey(t, v)] / total
+			if frequency == 0 {
+				continue
+			}
+			res -= frequency * math.Log2(frequency)
+		}
+	}
+	return res
+}
+
+// sameTargetValues tells whether the known values of every target are the same for all instances.
+func sameTargetValues(instances []*WeightedInstance) bool {
+	for t := range instances[0].Instance.TargetValues {
+		known := ""
+		for _, instance := range instances {
+			value := instance.Instance.TargetValues[t]
+			if value.IsMissing() {
+				continue
+			}
+			if known == "" {
+				known = value.Value().(string)
+			} else if value.Value().(string) != known {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// majorityClass returns the class value with the highest count, empty if there is none.
+func majorityClass(classCount map[string]float64) string {
+	var (
+		maxFrequency      float64
+		maxFrequencyClass string
+	)
+	for c, f := range classCount {
+		if f > maxFrequency {
+			maxFrequency = f
+			maxFrequencyClass = c
+		}
+	}
+	return maxFrequencyClass
+}
+
+// leaf returns the leaf node under the node that the instance reaches, nil if it cannot be predicted.
+func (n *Node) leaf(instance *data.Instance) *Node {
+	node := n
+	for node != nil && len(node.Children) > 0 {
+		node = node.GetRelatedChild(instance)
+	}
+	return node
+}
+
+// PredictTargets predicts the class and every additional target of the instance, by name. The instance is transformed
+// by the pipeline of the tree first.
+func (t *Tree) PredictTargets(instance *data.Instance) (map[string]string, error) {
+	instance, err := t.Transform(instance)
+	if err != nil {
+		return nil, err
+	}
+	path, err := t.PredictTransformedPath(instance)
+	if err != nil {
+		return nil, err
+	}
+	leaf := path[len(path)-1]
+	className := "Class"
+	if t.Class != nil {
+		className = t.Class.Name()
+	}
+	res := map[string]string{className: t.PathPrediction(path, instance)}
+	for i, target := range t.Targets {
+		if i < len(leaf.LeafTargetClass) {
+			res[target.Name()] = leaf.LeafTargetClass[i]
+		}
+	}
+	return res, nil
+}
+
+// testRunTargets returns the test results of every additional target of the tree, by name. The instances missing the
+// value of a target are left out of its results.
+func testRunTargets(tr *Tree, instances []*data.Instance) (map[string]*TestResults, error) {
+	leaves := make([]*Node, len(instances))
+	for i, instance := range instances {
+		path, err := tr.PredictPath(instance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to predict instance %d: %w", i, err)
+		}
+		leaves[i] = path[len(path)-1]
+	}
+	return leafTargetResults(tr, instances, leaves)
+}
+
+// leafTargetResults returns the test results of every additional target of the tree, by name, of the instances
+// predicted at the leaves.
+func leafTargetResults(tr *Tree, instances []*data.Instance, leaves []*Node) (map[string]*TestResults, error) {
+	var (
+		actual    = make([][]string, len(tr.Targets))
+		predicted = make([][]string, len(tr.Targets))
+	)
+	for i, instance := range instances {
+		if len(instance.TargetValues) != len(tr.Targets) {
+			return nil, fmt.Errorf("instance %d has %d target values, expected %d", i, len(instance.TargetValues), len(tr.Targets))
+		}
+		leaf := leaves[i]
+		for t, value := range instance.TargetValues {
+			if value.IsMissing() || t >= len(leaf.LeafTargetClass) {
+				continue
+			}
+			actual[t] = append(actual[t], value.Value().(string))
+			predicted[t] = append(predicted[t], leaf.LeafTargetClass[t])
+		}
+	}
+
+	res := make(map[string]*TestResults)
+	leafCount := len(tr.GetLeafNodes())
+	for t, target := range tr.Targets {
+		if len(actual[t]) > 0 {
+			res[target.Name()] = NewTestResults(actual[t], predicted[t], leafCount)
+		}
+	}
+	return res, nil
+}
+
+// targetsPessimisticError sums the pessimistic error of the node on every additional target of the instances, which
+// is added to that of the class when pruning.
+func targetsPessimisticError(node *Node, instances []*data.Instance) float64 {
+	if len(instances) == 0 || len(instances[0].TargetValues) == 0 {
+		return 0
+	}
+	var (
+		leafCount = len(node.GetLeafNodes())
+		res       = 0.0
+	)
+	for t := range instances[0].TargetValues {
+		var errorCount, totalCount int
+		for _, instance := range instances {
+			value := instance.TargetValues[t]
+			if value.IsMissing() {
+				continue
+			}
+			totalCount++
+			if leaf := node.leaf(instance); leaf == nil || t >= len(leaf.LeafTargetClass) || leaf.LeafTargetClass[t] != value.Value().(string) {
+				errorCount++
+			}
+		}
+		if totalCount > 0 {
+			res += calculatePessimisticError(errorCount, leafCount, totalCount)
+		}
+	}
+	return res
+}
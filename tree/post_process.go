@@ -2,6 +2,7 @@ package tree
 
 import (
 	"DecisionTree/config"
+	"fmt"
 )
 
 func postProcessTree(conf *config.Config, tree *Tree) error {
@@ -9,28 +10,50 @@ func postProcessTree(conf *config.Config, tree *Tree) error {
 	return postProcessNode(conf, tree.RootNode)
 }
 
-func postProcessNode(_ *config.Config, node *Node) error {
+func postProcessNode(conf *config.Config, node *Node) error {
+	// record the class distribution of every node
+	classFrequency := make(map[string]float64)
+	for _, ins := range node.instances {
+		classValue := ins.Instance.ClassValue.Value().(string)
+		classFrequency[classValue] += ins.Weight
+	}
+	node.ClassCount = classFrequency
+
+	// and that of every additional target
+	node.TargetClassCount = nil
+	if len(node.instances) > 0 {
+		for t := range node.instances[0].Instance.TargetValues {
+			targetFrequency := make(map[string]float64)
+			for _, ins := range node.instances {
+				if value := ins.Instance.TargetValues[t]; !value.IsMissing() {
+					targetFrequency[value.Value().(string)] += ins.Weight
+				}
+			}
+			node.TargetClassCount = append(node.TargetClassCount, targetFrequency)
+		}
+	}
+
+	// model trees fit a linear model at every node, for the smoothing of the predictions of the leaves.
+	// The instances of a node do not change when pruning, neither does its model.
+	if conf != nil && conf.ModelTree && node.Model == nil {
+		model, err := fitNodeModel(node)
+		if err != nil {
+			return fmt.Errorf("failed to fit the model of node %d: %w", node.UniqId(), err)
+		}
+		node.Model = model
+	}
+
 	// if is leaf node, calculate its majority class
 	if len(node.Children) == 0 {
-		classFrequency := make(map[string]float64)
-		for _, ins := range node.instances {
-			classValue := ins.Instance.ClassValue.Value().(string)
-			classFrequency[classValue] += ins.Weight
-		}
-		var (
-			maxFrequency      float64
-			maxFrequencyClass string
-		)
-		for c, f := range classFrequency {
-			if f > maxFrequency {
-				maxFrequency = f
-				maxFrequencyClass = c
-			}
+		node.LeafClass = majorityClass(classFrequency)
+		node.LeafTargetClass = nil
+		for _, targetFrequency := range node.TargetClassCount {
+			node.LeafTargetClass = append(node.LeafTargetClass, majorityClass(targetFrequency))
 		}
-		node.LeafClass = maxFrequencyClass
 	} else {
+		node.LeafTargetClass = nil
 		for _, child := range node.Children {
-			if err := postProcessNode(nil, child); err != nil {
+			if err := postProcessNode(conf, child); err != nil {
 				return err
 			}
 		}
@@ -6,8 +6,20 @@ import (
 	"fmt"
 )
 
+// Predict predicts the class of the instance, which is transformed by the pipeline of the tree first.
 func (t *Tree) Predict(instance *data.Instance) (string, error) {
-	return t.RootNode.Predict(instance)
+	instance, err := t.Transform(instance)
+	if err != nil {
+		return "", err
+	}
+	if t.Threshold == nil && !t.IsModelTree() {
+		return t.RootNode.Predict(instance)
+	}
+	path, err := t.PredictTransformedPath(instance)
+	if err != nil {
+		return "", err
+	}
+	return t.PathPrediction(path, instance), nil
 }
 
 func (n *Node) Predict(instance *data.Instance) (string, error) {
@@ -25,30 +37,140 @@ func (n *Node) Predict(instance *data.Instance) (string, error) {
 	return "", fmt.Errorf("unknown error, cannot predict instance")
 }
 
+// routeType tells how the related child of a node is chosen.
+type routeType int
+
+const (
+	routeConditionMet      routeType = iota // the value met the condition of the child
+	routeMissingValue                       // the value is missing, went along the prioritized branch
+	routeNoConditionMet                     // the value met no condition, went along the prioritized branch
+	routeNoPrioritizedNode                  // no child can be chosen
+)
+
 func (n *Node) GetRelatedChild(instance *data.Instance) *Node {
+	child, _, _ := n.getRelatedChild(instance)
+	return child
+}
+
+// getRelatedChild returns the related child, the value of the split attribute and how the child is chosen.
+func (n *Node) getRelatedChild(instance *data.Instance) (*Node, data.Value, routeType) {
 	if len(n.Children) == 0 {
-		return nil
+		return nil, nil, routeNoPrioritizedNode
 	}
 	attr := n.Children[0].Condition.Attr()
 	val := instance.GetValueByAttr(attr)
 
+	route := routeMissingValue
 	if !val.IsMissing() {
 		for _, child := range n.Children {
 			if child.Condition.IsMet(val) {
 				config.Logf("[Predict %d] Value %v met condition <%s> to child node %d\n", n.UniqId(), val.Log(), child.Condition.Log(), child.UniqId())
-				return child
+				return child, val, routeConditionMet
 			}
 		}
 		config.Logf("[Predict %d] Value %v mismatched all child nodes...\n", n.UniqId(), val.Log())
+		route = routeNoConditionMet
 	}
 	// If missing value, or no child is met, return the first prioritized child
 
 	for _, child := range n.Children {
 		if child.IsPrioritized {
 			config.Logf("[Predict %d] Value %v goes along prioritized branch node %d...\n", n.UniqId(), val.Value(), child.UniqId())
-			return child
+			return child, val, route
+		}
+	}
+
+	return nil, val, routeNoPrioritizedNode
+}
+
+// PredictPath returns the nodes visited when predicting the instance, from the root node to the leaf node.
+func (t *Tree) PredictPath(instance *data.Instance) ([]*Node, error) {
+	instance, err := t.Transform(instance)
+	if err != nil {
+		return nil, err
+	}
+	return t.PredictTransformedPath(instance)
+}
+
+// PredictTransformedPath is PredictPath of an instance already transformed by the pipeline.
+func (t *Tree) PredictTransformedPath(instance *data.Instance) ([]*Node, error) {
+	var (
+		path = []*Node{t.RootNode}
+		node = t.RootNode
+	)
+	for len(node.Children) > 0 {
+		node = node.GetRelatedChild(instance)
+		if node == nil {
+			return nil, fmt.Errorf("unknown error, cannot predict instance")
 		}
+		path = append(path, node)
+	}
+	return path, nil
+}
+
+// PredictProba returns the class probabilities of the leaf node that the instance reaches.
+func (t *Tree) PredictProba(instance *data.Instance) (map[string]float64, error) {
+	instance, err := t.Transform(instance)
+	if err != nil {
+		return nil, err
+	}
+	path, err := t.PredictTransformedPath(instance)
+	if err != nil {
+		return nil, err
+	}
+	return t.PathProbabilities(path, instance), nil
+}
+
+// PathProbabilities returns the class probabilities of the instance reaching the leaf by the path, which are those
+// of the leaf, unless the tree is a model tree (see Node.Model). The instance is transformed by the pipeline already.
+func (t *Tree) PathProbabilities(path []*Node, instance *data.Instance) map[string]float64 {
+	if t.IsModelTree() {
+		return t.modelProbabilities(path, instance)
+	}
+	return t.LeafProbabilities(path[len(path)-1])
+}
+
+// PathPrediction returns the class predicted for the instance reaching the leaf by the path, which is the prediction
+// of the leaf, unless the tree is a model tree. The instance is transformed by the pipeline already.
+func (t *Tree) PathPrediction(path []*Node, instance *data.Instance) string {
+	if !t.IsModelTree() {
+		return t.LeafPrediction(path[len(path)-1])
+	}
+	probabilities := t.modelProbabilities(path, instance)
+	if t.Threshold != nil {
+		return t.Threshold.Predict(probabilities, treeClasses(t))
 	}
+	return majorityClass(probabilities)
+}
 
-	return nil
+// LeafProbabilities returns the class distribution of the leaf node, calibrated if the tree has a calibrator.
+func (t *Tree) LeafProbabilities(leaf *Node) map[string]float64 {
+	if t.Calibrator == nil {
+		return leaf.ClassProbabilities()
+	}
+	return t.Calibrator.Calibrate(leaf)
+}
+
+// LeafPrediction returns the class predicted at the leaf node, which is the leaf class unless the tree has a
+// decision threshold.
+func (t *Tree) LeafPrediction(leaf *Node) string {
+	if t.Threshold == nil {
+		return leaf.LeafClass
+	}
+	return t.Threshold.Predict(t.LeafProbabilities(leaf), treeClasses(t))
+}
+
+// ClassProbabilities normalizes the class distribution of the node.
+// Nodes restored from older model files have no class distribution, their leaf class gets probability 1.
+func (n *Node) ClassProbabilities() map[string]float64 {
+	res := make(map[string]float64)
+	total := n.GetSampleCount()
+	if total == 0 {
+		res[n.LeafClass] = 1
+		return res
+	}
+	for class, count := range n.ClassCount {
+		res[class] = count / total
+	}
+	return res
 }
@@ -31,6 +31,8 @@ func postPruneTree(conf *config.Config, tree *Tree, trainData *data.ValueTable)
 		if err != nil {
 			return fmt.Errorf("failed to test run node (prior): %w", err)
 		}
+		// multi-output trees are pruned by the error summed across the class and the targets
+		oldError := oldInfo.PessimisticError + targetsPessimisticError(targetNode, instancesMapping[targetNode.UniqId()])
 
 		// try how much error will be reduced if we prune this node
 		savedChildren := targetNode.Children
@@ -45,17 +47,19 @@ func postPruneTree(conf *config.Config, tree *Tree, trainData *data.ValueTable)
 		if err != nil {
 			return fmt.Errorf("failed to test run node (post): %w", err)
 		}
-		if -(newInfo.PessimisticError - oldInfo.PessimisticError) < conf.MinPostPruneGeneralizationErrorDecrease {
+		newError := newInfo.PessimisticError + targetsPessimisticError(targetNode, instancesMapping[targetNode.UniqId()])
+		if -(newError - oldError) < conf.MinPostPruneGeneralizationErrorDecrease {
 			// if the error is not decreased, revert the prune
 			targetNode.Children = savedChildren
 		} else {
+			targetNode.SplitGain = 0
 			// if the error is decreased, add its parent to the prune ready nodes
 			if isNodePruneReady(reverseMapping[targetNode.UniqId()]) {
 				pruneReadyNodes = append(pruneReadyNodes, reverseMapping[targetNode.UniqId()])
 				bar.Total++
 			}
 			config.Logf("[Post-Prune] Pruned node %d, Pessimistic Error: %.6f%% -> %.6f%% (%.6f%%), Error Nodes: %d -> %d (%+d), Leaf Nodes: %d -> %d (%+d)",
-				targetNode.UniqId(), oldInfo.PessimisticError*100, newInfo.PessimisticError*100, (newInfo.PessimisticError-oldInfo.PessimisticError)*100,
+				targetNode.UniqId(), oldError*100, newError*100, (newError-oldError)*100,
 				oldInfo.ErrorCount, newInfo.ErrorCount, newInfo.ErrorCount-oldInfo.ErrorCount,
 				len(savedChildren), 1, 1-len(savedChildren))
 		}
@@ -2,6 +2,7 @@ package tree
 
 import (
 	"DecisionTree/data"
+	"DecisionTree/transform"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -57,7 +58,16 @@ func WriteTreeToFile(tree *Tree, filepath string) error {
 
 type PersistentTree struct {
 	Attributes []*data.PersistentAttribute `json:"attributes"`
+	Class      *data.PersistentAttribute   `json:"class,omitempty"`
+	Targets    []*data.PersistentAttribute `json:"targets,omitempty"`
 	RootNode   *PersistentNode             `json:"root_node"`
+
+	FeatureImportance *FeatureImportance  `json:"feature_importance,omitempty"`
+	Calibrator        *Calibrator         `json:"calibrator,omitempty"`
+	Threshold         *DecisionThreshold  `json:"threshold,omitempty"`
+	TrainingStats     *TrainingStats      `json:"training_stats,omitempty"`
+	Pipeline          *transform.Pipeline `json:"pipeline,omitempty"`
+	ModelSmoothing    float64             `json:"model_smoothing,omitempty"`
 }
 
 func NewPersistentTree(tree *Tree) *PersistentTree {
@@ -65,10 +75,23 @@ func NewPersistentTree(tree *Tree) *PersistentTree {
 	for _, attr := range tree.Attributes {
 		attrList = append(attrList, data.NewPersistentAttribute(attr))
 	}
-	return &PersistentTree{
-		Attributes: attrList,
-		RootNode:   NewPersistentNode(attrList, tree.RootNode),
+	pt := &PersistentTree{
+		Attributes:        attrList,
+		RootNode:          NewPersistentNode(attrList, tree.RootNode),
+		FeatureImportance: tree.FeatureImportance,
+		Calibrator:        tree.Calibrator,
+		Threshold:         tree.Threshold,
+		TrainingStats:     tree.TrainingStats,
+		Pipeline:          tree.Pipeline,
+		ModelSmoothing:    tree.ModelSmoothing,
+	}
+	if tree.Class != nil {
+		pt.Class = data.NewPersistentAttribute(tree.Class)
 	}
+	for _, target := range tree.Targets {
+		pt.Targets = append(pt.Targets, data.NewPersistentAttribute(target))
+	}
+	return pt
 }
 
 func (p *PersistentTree) ToTree() *Tree {
@@ -80,9 +103,29 @@ func (p *PersistentTree) ToTree() *Tree {
 		attrInst, _ := attr.ToAttribute()
 		attrList = append(attrList, attrInst)
 	}
+	var classAttr *data.NominalAttribute
+	if p.Class != nil {
+		attrInst, _ := p.Class.ToAttribute()
+		classAttr, _ = attrInst.(*data.NominalAttribute)
+	}
+	var targets []*data.NominalAttribute
+	for _, target := range p.Targets {
+		attrInst, _ := target.ToAttribute()
+		if targetAttr, ok := attrInst.(*data.NominalAttribute); ok {
+			targets = append(targets, targetAttr)
+		}
+	}
 	return &Tree{
-		Attributes: attrList,
-		RootNode:   p.RootNode.ToNode(attrList),
+		Attributes:        attrList,
+		Class:             classAttr,
+		Targets:           targets,
+		RootNode:          p.RootNode.ToNode(attrList),
+		FeatureImportance: p.FeatureImportance,
+		Calibrator:        p.Calibrator,
+		Threshold:         p.Threshold,
+		TrainingStats:     p.TrainingStats,
+		Pipeline:          p.Pipeline,
+		ModelSmoothing:    p.ModelSmoothing,
 	}
 }
 
@@ -90,8 +133,15 @@ type PersistentNode struct {
 	UniqId        int `json:"uniq_id"`
 	Condition     *PersistentCondition
 	Children      []*PersistentNode
-	IsPrioritized bool   `json:"is_prioritized,omitempty"`
-	LeafClass     string `json:"leaf_class,omitempty"`
+	IsPrioritized bool               `json:"is_prioritized,omitempty"`
+	LeafClass     string             `json:"leaf_class,omitempty"`
+	ClassCount    map[string]float64 `json:"class_count,omitempty"`
+	SplitGain     float64            `json:"split_gain,omitempty"`
+
+	TargetClassCount []map[string]float64 `json:"target_class_count,omitempty"`
+	LeafTargetClass  []string             `json:"leaf_target_class,omitempty"`
+
+	Model *LinearModel `json:"model,omitempty"`
 }
 
 func NewPersistentNode(attrList []*data.PersistentAttribute, node *Node) *PersistentNode {
@@ -101,6 +151,13 @@ func NewPersistentNode(attrList []*data.PersistentAttribute, node *Node) *Persis
 		Children:      nil,
 		IsPrioritized: node.IsPrioritized,
 		LeafClass:     node.LeafClass,
+		ClassCount:    node.ClassCount,
+		SplitGain:     node.SplitGain,
+
+		TargetClassCount: node.TargetClassCount,
+		LeafTargetClass:  node.LeafTargetClass,
+
+		Model: node.Model,
 	}
 	for _, child := range node.Children {
 		pNode.Children = append(pNode.Children, NewPersistentNode(attrList, child))
@@ -118,7 +175,14 @@ func (p *PersistentNode) ToNode(attrList []data.Attribute) *Node {
 		instances:     nil,
 		IsPrioritized: p.IsPrioritized,
 		LeafClass:     p.LeafClass,
+		ClassCount:    p.ClassCount,
+		SplitGain:     p.SplitGain,
 		uniqId:        p.UniqId,
+
+		TargetClassCount: p.TargetClassCount,
+		LeafTargetClass:  p.LeafTargetClass,
+
+		Model: p.Model,
 	}
 	for _, child := range p.Children {
 		node.Children = append(node.Children, child.ToNode(attrList))
@@ -132,6 +196,13 @@ type PersistentCondition struct {
 	UpperValue     float64       `json:"upper_value,omitempty"`
 	LowerValue     float64       `json:"lower_value,omitempty"`
 	AcceptedValues []string      `json:"accepted_values,omitempty"`
+	Level          string        `json:"level,omitempty"`
+	// part of the datetime attribute of which the values are compared, see data.DateTimePartAttribute
+	Part data.DateTimePart `json:"part,omitempty"`
+	// attributes and weights of the linear combination of oblique conditions, of which AttrId is the first attribute
+	AttrIds   []int     `json:"attr_ids,omitempty"`
+	Weights   []float64 `json:"weights,omitempty"`
+	Threshold float64   `json:"threshold,omitempty"`
 }
 
 func getIdFromAttrList(attrList []*data.PersistentAttribute, attribute data.Attribute) int {
@@ -146,18 +217,40 @@ func getIdFromAttrList(attrList []*data.PersistentAttribute, attribute data.Attr
 func NewPersistentCondition(attrList []*data.PersistentAttribute, condition Condition) *PersistentCondition {
 	switch c := condition.(type) {
 	case *ContinuousCondition:
-		return &PersistentCondition{
+		p := &PersistentCondition{
 			ConditionType: c.conditionType,
 			AttrId:        getIdFromAttrList(attrList, c.Attr()),
 			UpperValue:    c.upperValue,
 			LowerValue:    c.lowerValue,
 		}
+		if part, ok := c.attr.(*data.DateTimePartAttribute); ok {
+			p.AttrId = getIdFromAttrList(attrList, part.Parent)
+			p.Part = part.Part
+		}
+		return p
 	case *NominalCondition:
 		return &PersistentCondition{
 			ConditionType:  c.conditionType,
 			AttrId:         getIdFromAttrList(attrList, c.Attr()),
 			AcceptedValues: c.acceptedValues,
 		}
+	case *OrdinalCondition:
+		return &PersistentCondition{
+			ConditionType: c.conditionType,
+			AttrId:        getIdFromAttrList(attrList, c.Attr()),
+			Level:         c.level,
+		}
+	case *ObliqueCondition:
+		p := &PersistentCondition{
+			ConditionType: c.conditionType,
+			Weights:       c.attr.Weights,
+			Threshold:     c.threshold,
+		}
+		for _, attr := range c.attr.Attributes {
+			p.AttrIds = append(p.AttrIds, getIdFromAttrList(attrList, attr))
+		}
+		p.AttrId = p.AttrIds[0]
+		return p
 	default:
 		return nil
 	}
@@ -167,14 +260,34 @@ func (p *PersistentCondition) ToCondition(attrList []data.Attribute) Condition {
 	if p == nil {
 		return nil
 	}
+	if p.ConditionType == ObliqueLessThan || p.ConditionType == ObliqueGreaterThanEq {
+		var attrs []data.Attribute
+		for _, id := range p.AttrIds {
+			attrs = append(attrs, attrList[id])
+		}
+		combination := data.NewLinearCombinationAttribute(attrs, p.Weights)
+		if p.ConditionType == ObliqueLessThan {
+			return NewObliqueLessThanCondition(combination, p.Threshold)
+		}
+		return NewObliqueGreaterThanEqCondition(combination, p.Threshold)
+	}
+	attr := attrList[p.AttrId]
+	if dateTimeAttr, ok := attr.(*data.DateTimeAttribute); ok && p.Part != "" {
+		attr = data.NewDateTimePartAttribute(dateTimeAttr, p.Part)
+	}
 	switch p.ConditionType {
 	case LessThan:
-		return newLessThanCondition(attrList[p.AttrId], p.UpperValue)
+		return NewLessThanCondition(attr, p.UpperValue)
 	case GreaterThanEq:
-		return newGreaterThanEqCondition(attrList[p.AttrId], p.LowerValue)
-	// Range is not supported
+		return NewGreaterThanEqCondition(attr, p.LowerValue)
+	case Range:
+		return NewRangeCondition(attr, p.LowerValue, p.UpperValue)
 	case IsOneOf:
-		return newIsOneOfCondition(attrList[p.AttrId], p.AcceptedValues)
+		return NewIsOneOfCondition(attrList[p.AttrId], p.AcceptedValues)
+	case LessEq:
+		return NewLessEqCondition(attrList[p.AttrId], p.Level)
+	case Greater:
+		return NewGreaterCondition(attrList[p.AttrId], p.Level)
 	default:
 		return nil
 	}
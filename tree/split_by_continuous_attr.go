@@ -2,10 +2,20 @@ package tree
 
 import (
 	"DecisionTree/config"
+	"DecisionTree/data"
+	"DecisionTree/transform"
+	"fmt"
 	"sort"
 )
 
-func splitInstancesByContinuousAttr(_ *config.Config, rootEntropy float64, attrIndex int, instances []*WeightedInstance) ([]*Node, float64, error) {
+func splitInstancesByContinuousAttr(conf *config.Config, rootEntropy float64, attrIndex int, instances []*WeightedInstance) ([]*Node, float64, error) {
+	return splitInstancesByContinuousValue(conf, rootEntropy, instances[0].Instance.AttributeValues[attrIndex].Attribute(),
+		func(instance *data.Instance) data.Value { return instance.AttributeValues[attrIndex] }, instances)
+}
+
+// splitInstancesByContinuousValue splits the instances in two by the best threshold of the continuous values of the
+// attribute returned by valueOf, which may be derived from the values of the instances, e.g. the month of the dates.
+func splitInstancesByContinuousValue(conf *config.Config, rootEntropy float64, attr data.Attribute, valueOf func(*data.Instance) data.Value, instances []*WeightedInstance) ([]*Node, float64, error) {
 
 	// Calculate count for all class values
 	var (
@@ -16,14 +26,13 @@ func splitInstancesByContinuousAttr(_ *config.Config, rootEntropy float64, attrI
 		missingInstanceCount    = 0.0
 	)
 	for _, instance := range instances {
-		if instance.Instance.AttributeValues[attrIndex].IsMissing() {
+		if valueOf(instance.Instance).IsMissing() {
 			missingInstances = append(missingInstances, instance)
 			missingInstanceCount += instance.Weight
 			continue
 		}
 		nonMissingInstances = append(nonMissingInstances, instance)
-		classValue := instance.Instance.ClassValue.Value().(string)
-		classValueCount[classValue] += instance.Weight
+		addClassCount(classValueCount, instance.Instance, instance.Weight)
 		nonMissingInstanceCount += instance.Weight
 	}
 	instanceCount := nonMissingInstanceCount + missingInstanceCount
@@ -34,42 +43,64 @@ func splitInstancesByContinuousAttr(_ *config.Config, rootEntropy float64, attrI
 
 	// sort instances for continuous attribute
 	sort.Slice(nonMissingInstances, func(i, j int) bool {
-		return nonMissingInstances[i].Instance.AttributeValues[attrIndex].Value().(float64) < nonMissingInstances[j].Instance.AttributeValues[attrIndex].Value().(float64)
+		return valueOf(nonMissingInstances[i].Instance).Value().(float64) < valueOf(nonMissingInstances[j].Instance).Value().(float64)
 	})
 
+	// candidate thresholds, all midpoints if not restricted
+	candidates, err := continuousSplitCandidates(conf, valueOf, nonMissingInstances)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get split candidates: %w", err)
+	}
+	nextCandidate := 0
+
 	// from left to right, calculate the best split
 	var (
 		bestSplitValue     float64
 		bestSplitGain      float64
 		bestSplitPoint     = 0
+		bestLeftCount      float64
+		bestRightCount     float64
 		leftClassCnt       = make(map[string]float64)
 		rightClassCnt      = classValueCount
 		leftInstanceCount  float64
 		rightInstanceCount = nonMissingInstanceCount
 	)
-	for i := 1; i < len(instances); i++ {
+	for i := 1; i < len(nonMissingInstances); i++ {
 		// update class count
-		leftClassCnt[instances[i-1].Instance.ClassValue.Value().(string)] += instances[i-1].Weight
-		rightClassCnt[instances[i-1].Instance.ClassValue.Value().(string)] -= instances[i-1].Weight
-		leftInstanceCount += instances[i-1].Weight
-		rightInstanceCount -= instances[i-1].Weight
+		addClassCount(leftClassCnt, nonMissingInstances[i-1].Instance, nonMissingInstances[i-1].Weight)
+		addClassCount(rightClassCnt, nonMissingInstances[i-1].Instance, -nonMissingInstances[i-1].Weight)
+		leftInstanceCount += nonMissingInstances[i-1].Weight
+		rightInstanceCount -= nonMissingInstances[i-1].Weight
 
-		v1 := instances[i-1].Instance.AttributeValues[attrIndex].Value().(float64)
-		v2 := instances[i].Instance.AttributeValues[attrIndex].Value().(float64)
+		v1 := valueOf(nonMissingInstances[i-1].Instance).Value().(float64)
+		v2 := valueOf(nonMissingInstances[i].Instance).Value().(float64)
 		if v1 == v2 {
 			continue
 		}
+		splitValue := (v1 + v2) / 2
+		if candidates != nil {
+			// the first candidate in (v1, v2], if any
+			for nextCandidate < len(candidates) && candidates[nextCandidate] <= v1 {
+				nextCandidate++
+			}
+			if nextCandidate == len(candidates) || candidates[nextCandidate] > v2 {
+				continue
+			}
+			splitValue = candidates[nextCandidate]
+		}
 
 		// calculate gain for split
-		entropyLeft := calculateEntropy(instances[:i], leftInstanceCount, leftClassCnt)
-		entropyRight := calculateEntropy(instances[i:], rightInstanceCount, rightClassCnt)
+		entropyLeft := calculateEntropy(nonMissingInstances[:i], leftInstanceCount, leftClassCnt)
+		entropyRight := calculateEntropy(nonMissingInstances[i:], rightInstanceCount, rightClassCnt)
 		entropy := (entropyLeft*leftInstanceCount + entropyRight*rightInstanceCount) / instanceCount
 
 		gain := (rootEntropy - entropy) * nonMissingInstanceCount / instanceCount
 		if gain > bestSplitGain {
 			bestSplitGain = gain
-			bestSplitValue = (v1 + v2) / 2
+			bestSplitValue = splitValue
 			bestSplitPoint = i
+			bestLeftCount = leftInstanceCount
+			bestRightCount = rightInstanceCount
 		}
 	}
 
@@ -77,26 +108,57 @@ func splitInstancesByContinuousAttr(_ *config.Config, rootEntropy float64, attrI
 		return nil, 0, nil
 	}
 
-	// split instances
-	leftInstances := nonMissingInstances[:bestSplitPoint]
-	rightInstances := nonMissingInstances[bestSplitPoint:]
-	// distribute missing value instances
+	// split instances, copied since the missing value instances are appended
+	leftInstances := append([]*WeightedInstance(nil), nonMissingInstances[:bestSplitPoint]...)
+	rightInstances := append([]*WeightedInstance(nil), nonMissingInstances[bestSplitPoint:]...)
+	// distribute missing value instances by the weights of the best split
 	for _, instance := range missingInstances {
-		newLeftInstance := instance.CopyWithScale(leftInstanceCount / nonMissingInstanceCount)
-		newRightInstance := instance.CopyWithScale(rightInstanceCount / nonMissingInstanceCount)
-		leftInstances = append(leftInstances, newLeftInstance)
-		rightInstances = append(rightInstances, newRightInstance)
+		leftInstances = append(leftInstances, instance.CopyWithScale(bestLeftCount/nonMissingInstanceCount))
+		rightInstances = append(rightInstances, instance.CopyWithScale(bestRightCount/nonMissingInstanceCount))
 	}
 	return []*Node{
 		{
-			Condition:     newLessThanCondition(instances[0].Instance.AttributeValues[attrIndex].Attribute(), bestSplitValue),
+			Condition:     NewLessThanCondition(attr, bestSplitValue),
 			instances:     leftInstances,
-			IsPrioritized: leftInstanceCount >= rightInstanceCount,
+			IsPrioritized: bestLeftCount >= bestRightCount,
 		},
 		{
-			Condition:     newGreaterThanEqCondition(instances[0].Instance.AttributeValues[attrIndex].Attribute(), bestSplitValue),
+			Condition:     NewGreaterThanEqCondition(attr, bestSplitValue),
 			instances:     rightInstances,
-			IsPrioritized: rightInstanceCount > leftInstanceCount,
+			IsPrioritized: bestRightCount > bestLeftCount,
 		},
 	}, bestSplitGain, nil
 }
+
+// continuousSplitCandidates returns the bin edges of the sorted non-missing instances by the strategy of the config,
+// or nil if every midpoint is a candidate.
+func continuousSplitCandidates(conf *config.Config, valueOf func(*data.Instance) data.Value, instances []*WeightedInstance) ([]float64, error) {
+	if conf == nil || conf.ContinuousSplitCandidates == "" {
+		return nil, nil
+	}
+	if transform.BinStrategy(conf.ContinuousSplitCandidates) == transform.MDLBins {
+		points := make([]transform.ClassPoint, len(instances))
+		for i, instance := range instances {
+			points[i] = transform.ClassPoint{
+				Value:  valueOf(instance.Instance).Value().(float64),
+				Class:  instance.Instance.ClassValue.Value().(string),
+				Weight: instance.Weight,
+			}
+		}
+		return append([]float64{}, transform.MDLEdges(points)...), nil // not nil, no edge means no split
+	}
+
+	bins := conf.ContinuousSplitBins
+	if bins == 0 {
+		bins = 10
+	}
+	values := make([]float64, len(instances))
+	for i, instance := range instances {
+		values[i] = valueOf(instance.Instance).Value().(float64)
+	}
+	edges, err := transform.BinEdges(transform.BinStrategy(conf.ContinuousSplitCandidates), values, bins)
+	if err != nil {
+		return nil, err
+	}
+	return append([]float64{}, edges...), nil
+}
@@ -0,0 +1,46 @@
+package tree
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"fmt"
+)
+
+// splitInstancesByDateTimeParts splits the instances by the best threshold of the parts of the datetime attribute
+// in config.Config.DateTimeParts, e.g. month < 6.5. The conditions are on a data.DateTimePartAttribute, of which the
+// values are derived from those of the datetime attribute when predicting.
+func splitInstancesByDateTimeParts(conf *config.Config, rootEntropy float64, attrIndex int, instances []*WeightedInstance) ([]*Node, float64, error) {
+	attr, ok := instances[0].Instance.AttributeValues[attrIndex].Attribute().(*data.DateTimeAttribute)
+	if !ok || len(conf.DateTimeParts) == 0 {
+		return nil, 0, nil
+	}
+	var (
+		bestSplit []*Node
+		bestGain  float64
+	)
+	for _, part := range conf.DateTimeParts {
+		if !isDateTimePart(data.DateTimePart(part)) {
+			return nil, 0, fmt.Errorf("unknown datetime part '%s'", part)
+		}
+		partAttr := data.NewDateTimePartAttribute(attr, data.DateTimePart(part))
+		split, gain, err := splitInstancesByContinuousValue(conf, rootEntropy, partAttr, func(instance *data.Instance) data.Value {
+			return partAttr.Derive(instance.AttributeValues[attrIndex])
+		}, instances)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to split by the %s of attribute '%s': %w", part, attr.Name(), err)
+		}
+		if len(split) > 0 && gain > bestGain {
+			bestSplit, bestGain = split, gain
+		}
+	}
+	return bestSplit, bestGain, nil
+}
+
+func isDateTimePart(part data.DateTimePart) bool {
+	for _, p := range data.DateTimeParts {
+		if p == part {
+			return true
+		}
+	}
+	return false
+}
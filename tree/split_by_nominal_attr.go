@@ -4,9 +4,11 @@ import (
 	"DecisionTree/config"
 	"DecisionTree/data"
 	"DecisionTree/utils"
+	"cmp"
 	"fmt"
 	"math"
 	"slices"
+	"strings"
 )
 
 func splitInstancesByNominalAttr(conf *config.Config, rootEntropy float64, attrIndex int, instances []*WeightedInstance) ([]*nominalSplitUnit, float64, error) {
@@ -67,30 +69,58 @@ func multiWaySplitByNominalAttr(conf *config.Config, rootEntropy float64, attrIn
 }
 
 // binarySplitByNominalAttr splits instances into two groups by a nominal attribute.
-// For nominal attribute, if the number of accepted values is less than max_nominal_brute_force_scale, use
-// brute-force to find the best split. If not, we will first join the values with fewer instances until the
-// number of values is less than or equal to max_nominal_brute_force_scale, then perform brute-force.
+// If at most two classes are present and there are no targets, the values are sorted by the proportion of one class
+// and the best of the len(values)-1 cut points is the best binary split (Breiman's theorem), whatever the number of
+// values. Otherwise, if the number of values is less than or equal to max_nominal_brute_force_scale, use brute-force
+// to find the best split. If not, the values are sorted by the proportion of every class (and target value) in turn,
+// and the best cut point of all these orderings is used, which is a heuristic.
 // returns: split result, gain, error
 func binarySplitByNominalAttr(conf *config.Config, rootEntropy float64, attrIndex int, instances []*WeightedInstance) ([]*nominalSplitUnit, float64, error) {
 	classifiedInstancesMap, missingValueInstances := classifyInstancesByNominalAttr(instances, attrIndex)
-	// join values with fewer instances until the number of values is less than or equal to max_nominal_brute_force_scale
-	// initialize a join list
 	var sortUnits []*nominalSplitUnit
 	for value, instanceList := range classifiedInstancesMap {
 		sortUnits = append(sortUnits, newNominalValueUnit(value, instanceList))
 	}
-	// sort the join list by the number of instances
-	sortNominalValueUnitList(sortUnits)
-	for len(sortUnits) > conf.MaxNominalBruteForceScale {
-		sortUnits[0] = joinNominalValueUnit(sortUnits[0], sortUnits[1])
-		sortUnits = append(sortUnits[:1], sortUnits[2:]...)
-		// sort after joining
-		sortNominalValueUnitList(sortUnits)
+	if len(sortUnits) < 2 {
+		return nil, 0, nil
+	}
+	// sort the units by value first, so that the ties of the orderings do not depend on the map iteration
+	slices.SortFunc(sortUnits, func(a, b *nominalSplitUnit) int {
+		return strings.Compare(a.values[0], b.values[0])
+	})
+
+	var (
+		bestGain  = 0.0
+		bestSplit []*nominalSplitUnit
+	)
+	classKeys := nominalSplitClassKeys(sortUnits)
+	switch {
+	case len(classKeys) < 2:
+		// a single class, no split has a gain
+		return nil, 0, nil
+	case len(classKeys) == 2 && len(instances[0].Instance.TargetValues) == 0:
+		bestSplit, bestGain = orderedBinarySplitByNominalUnits(conf, rootEntropy, instances, sortUnits, classKeys[0])
+	case len(sortUnits) > conf.MaxNominalBruteForceScale:
+		for _, key := range classKeys {
+			split, gain := orderedBinarySplitByNominalUnits(conf, rootEntropy, instances, sortUnits, key)
+			if split != nil && gain > bestGain {
+				bestGain = gain
+				bestSplit = split
+			}
+		}
+	default:
+		bestSplit, bestGain = bruteForceBinarySplitByNominalUnits(conf, rootEntropy, instances, sortUnits)
+	}
+	if len(bestSplit) != 2 {
+		return nil, 0, nil
 	}
+	// distribute missing value instances
+	distributeMissingValuesToNominalSplit(bestSplit, missingValueInstances)
+	return bestSplit, bestGain, nil
+}
 
-	///////////////////////////////////////////////////////////////////////
-	// perform brute-force, find the best split
-	///////////////////////////////////////////////////////////////////////
+// bruteForceBinarySplitByNominalUnits tries all the 2^len(units)-2 binary splits of the units.
+func bruteForceBinarySplitByNominalUnits(conf *config.Config, rootEntropy float64, instances []*WeightedInstance, sortUnits []*nominalSplitUnit) ([]*nominalSplitUnit, float64) {
 	totalSplits := int(math.Pow(2, float64(len(sortUnits)))) - 1
 	var (
 		bestGain  = 0.0
@@ -132,12 +162,80 @@ func binarySplitByNominalAttr(conf *config.Config, rootEntropy float64, attrInde
 			bestSplit = []*nominalSplitUnit{left, right}
 		}
 	}
-	if len(bestSplit) != 2 {
-		return nil, 0, nil
+	return bestSplit, bestGain
+}
+
+// orderedBinarySplitByNominalUnits sorts the units by the proportion of the class key (a class value or a
+// targetClassKey) and returns the best of the len(units)-1 splits between the first i units and the others.
+// The splits are evaluated from the class counts of the units, only the best one joins the instances.
+func orderedBinarySplitByNominalUnits(conf *config.Config, rootEntropy float64, instances []*WeightedInstance, units []*nominalSplitUnit, key string) ([]*nominalSplitUnit, float64) {
+	sorted := slices.Clone(units)
+	slices.SortStableFunc(sorted, func(a, b *nominalSplitUnit) int {
+		return cmp.Compare(a.classValueInstanceCount[key]/a.count, b.classValueInstanceCount[key]/b.count)
+	})
+
+	var (
+		bestGain   = 0.0
+		bestCut    = 0
+		leftSize   = 0
+		totalSize  = 0
+		leftCount  = 0.0
+		leftClass  = make(map[string]float64)
+		totalCount = 0.0
+		// calculateEntropy only reads the class and target attributes of the first instance
+		first             = sorted[0].instances[:1]
+		instanceWeightSum = SumInstanceWeights(instances)
+	)
+	for _, unit := range sorted {
+		totalSize += len(unit.instances)
+		totalCount += unit.count
 	}
-	// distribute missing value instances
-	distributeMissingValuesToNominalSplit(bestSplit, missingValueInstances)
-	return bestSplit, bestGain, nil
+	for i := 1; i < len(sorted); i++ {
+		leftSize += len(sorted[i-1].instances)
+		leftCount += sorted[i-1].count
+		for k, v := range sorted[i-1].classValueInstanceCount {
+			leftClass[k] += v
+		}
+		if leftSize < conf.MinSamplesLeaf || totalSize-leftSize < conf.MinSamplesLeaf {
+			continue
+		}
+		// sum the right class counts instead of subtracting, which could leave negative rounding errors
+		var (
+			rightCount = 0.0
+			rightClass = make(map[string]float64)
+		)
+		for _, unit := range sorted[i:] {
+			rightCount += unit.count
+			for k, v := range unit.classValueInstanceCount {
+				rightClass[k] += v
+			}
+		}
+		entropy := (calculateEntropy(first, leftCount, leftClass)*leftCount +
+			calculateEntropy(first, rightCount, rightClass)*rightCount) / totalCount
+		gain := (rootEntropy - entropy) * totalCount / instanceWeightSum
+		if gain > bestGain {
+			bestGain = gain
+			bestCut = i
+		}
+	}
+	if bestCut == 0 {
+		return nil, 0
+	}
+	return []*nominalSplitUnit{mergeNominalValueUnits(sorted[:bestCut]), mergeNominalValueUnits(sorted[bestCut:])}, bestGain
+}
+
+// nominalSplitClassKeys returns the sorted class keys (class values and targetClassKey) present in the units.
+func nominalSplitClassKeys(units []*nominalSplitUnit) []string {
+	var res []string
+	for _, unit := range units {
+		for k, v := range unit.classValueInstanceCount {
+			if v > 0 && !slices.Contains(res, k) {
+				res = append(res, k)
+			}
+		}
+	}
+	slices.Sort(res)
+	return res
 }
 
 // classifyInstancesByNominalAttr classifies instances by a nominal attribute.
@@ -173,7 +271,7 @@ func newNominalValueUnit(value string, instances []*WeightedInstance) *nominalSp
 		classValueInstanceCount: make(map[string]float64),
 	}
 	for _, instance := range instances {
-		res.classValueInstanceCount[instance.Instance.ClassValue.Value().(string)] += instance.Weight
+		addClassCount(res.classValueInstanceCount, instance.Instance, instance.Weight)
 	}
 	return res
 }
@@ -201,17 +299,19 @@ func joinNominalValueUnit(a, b *nominalSplitUnit) *nominalSplitUnit {
 	return res
 }
 
-func sortNominalValueUnitList(units []*nominalSplitUnit) {
-	slices.SortFunc(units, func(a, b *nominalSplitUnit) int {
-		switch {
-		case a.count < b.count:
-			return -1
-		case a.count > b.count:
-			return 1
-		default:
-			return 0
+// mergeNominalValueUnits joins the units into a new unit, of which the instances do not share the memory of the units.
+func mergeNominalValueUnits(units []*nominalSplitUnit) *nominalSplitUnit {
+	res := &nominalSplitUnit{classValueInstanceCount: make(map[string]float64)}
+	for _, unit := range units {
+		res.values = append(res.values, unit.values...)
+		res.instances = append(res.instances, unit.instances...)
+		res.count += unit.count
+		for k, v := range unit.classValueInstanceCount {
+			res.classValueInstanceCount[k] += v
 		}
-	})
+	}
+	res.values = utils.RemoveEmptyStr(res.values)
+	return res
 }
 
 func calculateGainForNominalSplit(rootEntropy float64, instances []*WeightedInstance, split []*nominalSplitUnit) float64 {
@@ -260,7 +360,7 @@ func buildNodeListFromNominalSplit(attribute data.Attribute, split []*nominalSpl
 	)
 	for i, unit := range split {
 		res = append(res, &Node{
-			Condition: newIsOneOfCondition(attribute, unit.values),
+			Condition: NewIsOneOfCondition(attribute, unit.values),
 			Children:  nil,
 			instances: unit.instances,
 		})
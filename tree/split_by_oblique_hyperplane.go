@@ -0,0 +1,292 @@
+package tree
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+const obliqueSplitIterations = 10
+
+// obliqueSplitSteps are the changes of a standardized weight tried by the hill climbing, the weights are scaled so
+// that the largest absolute weight is 1.
+var obliqueSplitSteps = []float64{1, -1, 0.5, -0.5, 0.25, -0.25, 0.1, -0.1}
+
+// splitInstancesByObliqueHyperplane splits the instances in two by the best hyperplane w·x < b found over the
+// continuous attributes, as in OC1: starting from the best axis-aligned direction and from the difference between the
+// class means, the weights of the standardized attributes are changed one at a time while the gain increases.
+// The threshold of the final direction is found as for a continuous attribute, so the instances missing any value
+// of the hyperplane are distributed in the same way.
+// Splits with fewer than two non-zero weights are left to the splits by a single attribute.
+func splitInstancesByObliqueHyperplane(conf *config.Config, rootEntropy float64, instances []*WeightedInstance) ([]*Node, float64, error) {
+	if len(instances) == 0 {
+		return nil, 0, nil
+	}
+	var attrs []data.Attribute
+	for _, value := range instances[0].Instance.AttributeValues {
+		if value.Attribute().Type() == data.Continuous {
+			attrs = append(attrs, value.Attribute())
+		}
+	}
+	if len(attrs) < 2 {
+		return nil, 0, nil
+	}
+
+	search := newObliqueSearch(rootEntropy, attrs, instances)
+	if search == nil {
+		return nil, 0, nil
+	}
+	var (
+		bestWeights []float64
+		bestGain    = 0.0
+	)
+	for _, seed := range search.seeds() {
+		weights, gain := search.climb(seed)
+		if gain > bestGain {
+			bestGain = gain
+			bestWeights = weights
+		}
+	}
+	if bestWeights == nil {
+		return nil, 0, nil
+	}
+
+	// back to the weights of the raw values, the offset of the means is left to the threshold
+	var (
+		combinationAttrs   []data.Attribute
+		combinationWeights []float64
+	)
+	for m, w := range bestWeights {
+		if w != 0 {
+			combinationAttrs = append(combinationAttrs, attrs[m])
+			combinationWeights = append(combinationWeights, w/search.std[m])
+		}
+	}
+	if len(combinationAttrs) < 2 {
+		return nil, 0, nil
+	}
+	combination := data.NewLinearCombinationAttribute(combinationAttrs, combinationWeights)
+	split, gain, err := splitInstancesByContinuousValue(conf, rootEntropy, combination,
+		func(instance *data.Instance) data.Value { return instance.GetValueByAttr(combination) }, instances)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to split instances by linear combination: %w", err)
+	}
+	for _, node := range split {
+		c := node.Condition.(*ContinuousCondition)
+		if c.Type() == LessThan {
+			node.Condition = NewObliqueLessThanCondition(combination, c.UpperValue())
+		} else {
+			node.Condition = NewObliqueGreaterThanEqCondition(combination, c.LowerValue())
+		}
+	}
+	return split, gain, nil
+}
+
+// obliqueSearch holds the standardized values of the instances having all the values of the attributes.
+type obliqueSearch struct {
+	rootEntropy float64
+	instances   []*WeightedInstance
+	points      [][]float64 // standardized values of the instances
+	mean, std   []float64
+}
+
+func newObliqueSearch(rootEntropy float64, attrs []data.Attribute, instances []*WeightedInstance) *obliqueSearch {
+	s := &obliqueSearch{
+		rootEntropy: rootEntropy,
+		mean:        make([]float64, len(attrs)),
+		std:         make([]float64, len(attrs)),
+	}
+	totalWeight := 0.0
+	for _, instance := range instances {
+		point := make([]float64, len(attrs))
+		complete := true
+		for m, attr := range attrs {
+			value := instance.Instance.GetValueByAttr(attr)
+			if value == nil || value.IsMissing() {
+				complete = false
+				break
+			}
+			point[m] = value.Value().(float64)
+		}
+		if !complete {
+			continue
+		}
+		s.instances = append(s.instances, instance)
+		s.points = append(s.points, point)
+		for m, v := range point {
+			s.mean[m] += v * instance.Weight
+		}
+		totalWeight += instance.Weight
+	}
+	if len(s.instances) < 2 || totalWeight == 0 {
+		return nil
+	}
+	for m := range attrs {
+		s.mean[m] /= totalWeight
+	}
+	for j, point := range s.points {
+		for m, v := range point {
+			s.std[m] += (v - s.mean[m]) * (v - s.mean[m]) * s.instances[j].Weight
+		}
+	}
+	for m := range attrs {
+		s.std[m] = math.Sqrt(s.std[m] / totalWeight)
+		if s.std[m] == 0 {
+			s.std[m] = 1 // constant values, all standardized to 0
+		}
+	}
+	for _, point := range s.points {
+		for m, v := range point {
+			point[m] = (v - s.mean[m]) / s.std[m]
+		}
+	}
+	return s
+}
+
+// seeds returns the starting directions: the attribute with the best axis-aligned split, and the difference
+// between the mean of the most frequent class and the mean of the other instances.
+func (s *obliqueSearch) seeds() [][]float64 {
+	var (
+		res      [][]float64
+		bestAxis []float64
+		bestGain = 0.0
+		dims     = len(s.mean)
+	)
+	for m := 0; m < dims; m++ {
+		axis := make([]float64, dims)
+		axis[m] = 1
+		if gain := s.gain(axis); gain > bestGain {
+			bestGain = gain
+			bestAxis = axis
+		}
+	}
+	if bestAxis != nil {
+		res = append(res, bestAxis)
+	}
+
+	classCount := make(map[string]float64)
+	for _, instance := range s.instances {
+		classCount[instance.Instance.ClassValue.Value().(string)] += instance.Weight
+	}
+	majority := ""
+	for class, count := range classCount {
+		if majority == "" || count > classCount[majority] || (count == classCount[majority] && class < majority) {
+			majority = class
+		}
+	}
+	var (
+		in, out           = make([]float64, dims), make([]float64, dims)
+		inCount, outCount = 0.0, 0.0
+	)
+	for j, instance := range s.instances {
+		sum, count := out, &outCount
+		if instance.Instance.ClassValue.Value().(string) == majority {
+			sum, count = in, &inCount
+		}
+		for m, v := range s.points[j] {
+			sum[m] += v * instance.Weight
+		}
+		*count += instance.Weight
+	}
+	if inCount > 0 && outCount > 0 {
+		diff := make([]float64, dims)
+		for m := range diff {
+			diff[m] = in[m]/inCount - out[m]/outCount
+		}
+		if normalizeObliqueWeights(diff) {
+			res = append(res, diff)
+		}
+	}
+	return res
+}
+
+// climb changes one weight at a time by the steps, keeping the changes that increase the gain, until no change
+// does or the iterations run out. Returns the weights and their gain.
+func (s *obliqueSearch) climb(weights []float64) ([]float64, float64) {
+	gain := s.gain(weights)
+	for iteration := 0; iteration < obliqueSplitIterations; iteration++ {
+		improved := false
+		for m := range weights {
+			for _, step := range obliqueSplitSteps {
+				candidate := slices.Clone(weights)
+				candidate[m] += step
+				if !normalizeObliqueWeights(candidate) {
+					continue
+				}
+				if candidateGain := s.gain(candidate); candidateGain > gain+1e-9 {
+					weights, gain, improved = candidate, candidateGain, true
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return weights, gain
+}
+
+// gain returns the gain of the best threshold of the projections of the instances on the weights.
+func (s *obliqueSearch) gain(weights []float64) float64 {
+	var (
+		projections = make([]float64, len(s.points))
+		order       = make([]int, len(s.points))
+		classCount  = make(map[string]float64)
+		totalCount  = 0.0
+	)
+	for j, point := range s.points {
+		for m, v := range point {
+			projections[j] += weights[m] * v
+		}
+		order[j] = j
+		addClassCount(classCount, s.instances[j].Instance, s.instances[j].Weight)
+		totalCount += s.instances[j].Weight
+	}
+	sort.Slice(order, func(a, b int) bool { return projections[order[a]] < projections[order[b]] })
+
+	var (
+		bestGain   = 0.0
+		leftCount  = 0.0
+		leftClass  = make(map[string]float64)
+		rightClass = classCount
+		// calculateEntropy only reads the class and target attributes of the first instance
+		first = s.instances[:1]
+	)
+	for i := 1; i < len(order); i++ {
+		instance := s.instances[order[i-1]]
+		addClassCount(leftClass, instance.Instance, instance.Weight)
+		addClassCount(rightClass, instance.Instance, -instance.Weight)
+		leftCount += instance.Weight
+		if projections[order[i-1]] == projections[order[i]] {
+			continue
+		}
+		rightCount := totalCount - leftCount
+		entropy := (calculateEntropy(first, leftCount, leftClass)*leftCount +
+			calculateEntropy(first, rightCount, rightClass)*rightCount) / totalCount
+		if gain := s.rootEntropy - entropy; gain > bestGain {
+			bestGain = gain
+		}
+	}
+	return bestGain
+}
+
+// normalizeObliqueWeights scales the weights so that the largest absolute weight is 1, and zeroes the negligible
+// ones. Returns false if all the weights are 0.
+func normalizeObliqueWeights(weights []float64) bool {
+	largest := 0.0
+	for _, w := range weights {
+		largest = max(largest, math.Abs(w))
+	}
+	if largest == 0 {
+		return false
+	}
+	for m, w := range weights {
+		weights[m] = w / largest
+		if math.Abs(weights[m]) < 1e-3 {
+			weights[m] = 0
+		}
+	}
+	return true
+}
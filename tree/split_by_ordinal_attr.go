@@ -0,0 +1,121 @@
+package tree
+
+import (
+	"DecisionTree/config"
+	"DecisionTree/data"
+	"sort"
+)
+
+// splitInstancesByOrdinalAttr splits the instances in two by the best threshold level of the ordinal attribute, like
+// a continuous attribute in the order of its levels, so that only the len(levels) - 1 thresholds are tried instead of
+// every subset of the values. The values that are not levels of the attribute are distributed like missing values.
+func splitInstancesByOrdinalAttr(conf *config.Config, rootEntropy float64, attrIndex int, instances []*WeightedInstance) ([]*Node, float64, error) {
+	attr, ok := instances[0].Instance.AttributeValues[attrIndex].Attribute().(*data.NominalAttribute)
+	if !ok {
+		return nil, 0, nil
+	}
+
+	// Calculate count for all class values
+	var (
+		nonMissingInstances     []*WeightedInstance
+		missingInstances        []*WeightedInstance
+		levels                  = make(map[*WeightedInstance]int)
+		classValueCount         = make(map[string]float64) // will only count non-missing instances
+		nonMissingInstanceCount = 0.0
+		missingInstanceCount    = 0.0
+	)
+	for _, instance := range instances {
+		value := instance.Instance.AttributeValues[attrIndex]
+		level := -1
+		if !value.IsMissing() {
+			level = attr.Level(value.Value().(string))
+		}
+		if level < 0 {
+			missingInstances = append(missingInstances, instance)
+			missingInstanceCount += instance.Weight
+			continue
+		}
+		levels[instance] = level
+		nonMissingInstances = append(nonMissingInstances, instance)
+		addClassCount(classValueCount, instance.Instance, instance.Weight)
+		nonMissingInstanceCount += instance.Weight
+	}
+	instanceCount := nonMissingInstanceCount + missingInstanceCount
+
+	if len(nonMissingInstances) < 2 {
+		return nil, 0, nil
+	}
+
+	// sort instances by level
+	sort.SliceStable(nonMissingInstances, func(i, j int) bool {
+		return levels[nonMissingInstances[i]] < levels[nonMissingInstances[j]]
+	})
+
+	// from the lowest level to the highest, calculate the best split
+	var (
+		bestSplitLevel     int
+		bestSplitGain      float64
+		bestSplitPoint     = 0
+		leftClassCnt       = make(map[string]float64)
+		rightClassCnt      = classValueCount
+		leftInstanceCount  float64
+		rightInstanceCount = nonMissingInstanceCount
+	)
+	for i := 1; i < len(nonMissingInstances); i++ {
+		// update class count
+		addClassCount(leftClassCnt, nonMissingInstances[i-1].Instance, nonMissingInstances[i-1].Weight)
+		addClassCount(rightClassCnt, nonMissingInstances[i-1].Instance, -nonMissingInstances[i-1].Weight)
+		leftInstanceCount += nonMissingInstances[i-1].Weight
+		rightInstanceCount -= nonMissingInstances[i-1].Weight
+
+		l1, l2 := levels[nonMissingInstances[i-1]], levels[nonMissingInstances[i]]
+		if l1 == l2 || i < conf.MinSamplesLeaf || len(nonMissingInstances)-i < conf.MinSamplesLeaf {
+			continue
+		}
+
+		// calculate gain for split
+		entropyLeft := calculateEntropy(nonMissingInstances[:i], leftInstanceCount, leftClassCnt)
+		entropyRight := calculateEntropy(nonMissingInstances[i:], rightInstanceCount, rightClassCnt)
+		entropy := (entropyLeft*leftInstanceCount + entropyRight*rightInstanceCount) / instanceCount
+
+		gain := (rootEntropy - entropy) * nonMissingInstanceCount / instanceCount
+		if gain > bestSplitGain {
+			bestSplitGain = gain
+			bestSplitLevel = l1
+			bestSplitPoint = i
+		}
+	}
+
+	if bestSplitPoint == 0 {
+		return nil, 0, nil
+	}
+
+	// split instances
+	var (
+		bestLeftCount  float64
+		leftInstances  = append([]*WeightedInstance(nil), nonMissingInstances[:bestSplitPoint]...)
+		rightInstances = append([]*WeightedInstance(nil), nonMissingInstances[bestSplitPoint:]...)
+	)
+	for _, instance := range leftInstances {
+		bestLeftCount += instance.Weight
+	}
+	bestRightCount := nonMissingInstanceCount - bestLeftCount
+	// distribute missing value instances
+	for _, instance := range missingInstances {
+		leftInstances = append(leftInstances, instance.CopyWithScale(bestLeftCount/nonMissingInstanceCount))
+		rightInstances = append(rightInstances, instance.CopyWithScale(bestRightCount/nonMissingInstanceCount))
+	}
+	level := attr.AcceptedValues[bestSplitLevel]
+	return []*Node{
+		{
+			Condition:     NewLessEqCondition(attr, level),
+			instances:     leftInstances,
+			IsPrioritized: bestLeftCount >= bestRightCount,
+		},
+		{
+			Condition:     NewGreaterCondition(attr, level),
+			instances:     rightInstances,
+			IsPrioritized: bestRightCount > bestLeftCount,
+		},
+	}, bestSplitGain, nil
+}
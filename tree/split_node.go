@@ -41,6 +41,16 @@ func splitNode(bar *uiprogress.Bar, conf *config.Config, level int, node *Node)
 	for i, value := range node.instances[0].Instance.AttributeValues {
 		attribute := value.Attribute()
 		switch attribute.Type() {
+		case data.DateTime:
+			bestPartSplit, bestPartGain, err := splitInstancesByDateTimeParts(conf, nodeEntropy, i, node.instances)
+			if err != nil {
+				return fmt.Errorf("failed to split instances by datetime parts: %w", err)
+			}
+			if len(bestPartSplit) > 0 && bestPartGain > bestSplitGain {
+				bestSplitGain = bestPartGain
+				bestSplitChildren = bestPartSplit
+			}
+			fallthrough
 		case data.Continuous:
 			bestContinuousSplit, bestContinuousGain, err := splitInstancesByContinuousAttr(conf, nodeEntropy, i, node.instances)
 			if err != nil {
@@ -61,6 +71,27 @@ func splitNode(bar *uiprogress.Bar, conf *config.Config, level int, node *Node)
 				bestSplitChildren = buildNodeListFromNominalSplit(attribute, bestNominalSplit)
 				//bestSplitIndex = i
 			}
+		case data.Ordinal:
+			bestOrdinalSplit, bestOrdinalGain, err := splitInstancesByOrdinalAttr(conf, nodeEntropy, i, node.instances)
+			if err != nil {
+				return fmt.Errorf("failed to split instances by ordinal attribute: %w", err)
+			}
+			if len(bestOrdinalSplit) > 0 && bestOrdinalGain > bestSplitGain {
+				bestSplitGain = bestOrdinalGain
+				bestSplitChildren = bestOrdinalSplit
+			}
+		}
+	}
+
+	// an oblique split has to beat the best axis-aligned split by the margin
+	if conf.ObliqueSplits {
+		obliqueSplit, obliqueGain, err := splitInstancesByObliqueHyperplane(conf, nodeEntropy, node.instances)
+		if err != nil {
+			return fmt.Errorf("failed to split instances by oblique hyperplane: %w", err)
+		}
+		if len(obliqueSplit) > 0 && obliqueGain > bestSplitGain+conf.ObliqueSplitMargin {
+			bestSplitGain = obliqueGain
+			bestSplitChildren = obliqueSplit
 		}
 	}
 
@@ -77,6 +108,7 @@ func splitNode(bar *uiprogress.Bar, conf *config.Config, level int, node *Node)
 
 	// split node
 	node.Children = bestSplitChildren
+	node.SplitGain = bestSplitGain
 	bar.Total += len(bestSplitChildren)
 
 	config.Logf("[Train %d] [Level %d] Split node by condition %s, gain=%f, n_instance=%d", node.UniqId(), level, node.LogChildConditions(), bestSplitGain, len(node.instances))
@@ -100,5 +132,5 @@ func allSameClassValue(instances []*WeightedInstance) bool {
 			return false
 		}
 	}
-	return true
+	return sameTargetValues(instances)
 }
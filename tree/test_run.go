@@ -16,17 +16,108 @@ type TestResults struct {
 	ClassCorrectCount map[string]int
 	ClassErrorCount   map[string]int
 	ClassRecall       map[string]float64
-	ClassPrecision    map[string]float64
+	ClassPrecision    map[string]float64 // 0 if the class is never predicted
+	ClassF1           map[string]float64
 	ConfusionMatrix   map[string]map[string]int // actual class -> predicted class -> count
 	PessimisticError  float64
 	AvgPredictTime    time.Duration
+
+	MacroF1          float64 // unweighted mean of the F1 score of every actual or predicted class
+	MicroF1          float64 // F1 score of the total true positives, false negatives and false positives
+	WeightedF1       float64 // mean of the F1 score of every class weighted by its data count
+	BalancedAccuracy float64 // mean of the recall of every actual class
+	MCC              float64 // Matthews correlation coefficient
+	Kappa            float64 // Cohen's kappa
+
+	Targets map[string]*TestResults // results of every additional target of multi-output trees, by name
 }
 
+// TestRun tests the tree on the data set, and on every additional target of multi-output trees.
 func TestRun(tr *Tree, dataTable *data.ValueTable) (*TestResults, error) {
-	return testRunNode(tr.RootNode, dataTable.Instances)
+	res, err := testRun(tr.Predict, len(tr.GetLeafNodes()), dataTable.Instances)
+	if err != nil {
+		return nil, err
+	}
+	if len(tr.Targets) > 0 {
+		res.Targets, err = testRunTargets(tr, dataTable.Instances)
+		if err != nil {
+			return nil, fmt.Errorf("failed to test run targets: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// TestRunProba is TestRun, which also returns the class probabilities of every instance of the data set. Every
+// instance is predicted once, its class and probabilities are taken from the path it follows.
+func TestRunProba(tr *Tree, dataTable *data.ValueTable) (*TestResults, []map[string]float64, error) {
+	var (
+		instances     = dataTable.Instances
+		actual        = make([]string, len(instances))
+		predicted     = make([]string, len(instances))
+		probabilities = make([]map[string]float64, len(instances))
+		leaves        = make([]*Node, len(instances))
+	)
+	startTime := time.Now()
+	for i, instance := range instances {
+		transformed, err := tr.Transform(instance)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to predict instance %d: %w", i, err)
+		}
+		path, err := tr.PredictTransformedPath(transformed)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to predict instance %d: %w", i, err)
+		}
+		actual[i] = instance.ClassValue.Value().(string)
+		predicted[i] = tr.PathPrediction(path, transformed)
+		probabilities[i] = tr.PathProbabilities(path, transformed)
+		leaves[i] = path[len(path)-1]
+	}
+	var avgPredictTime time.Duration
+	if len(instances) > 0 {
+		avgPredictTime = time.Since(startTime) / time.Duration(len(instances))
+	}
+	res := NewTestResults(actual, predicted, len(tr.GetLeafNodes()))
+	res.AvgPredictTime = avgPredictTime
+	if len(tr.Targets) > 0 {
+		var err error
+		res.Targets, err = leafTargetResults(tr, instances, leaves)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to test run targets: %w", err)
+		}
+	}
+	return res, probabilities, nil
 }
 
 func testRunNode(node *Node, instances []*data.Instance) (*TestResults, error) {
+	return testRun(node.Predict, len(node.GetLeafNodes()), instances)
+}
+
+func testRun(predict func(instance *data.Instance) (string, error), leafCount int, instances []*data.Instance) (*TestResults, error) {
+	var (
+		actual    = make([]string, len(instances))
+		predicted = make([]string, len(instances))
+		err       error
+	)
+	startTime := time.Now()
+	for i, instance := range instances {
+		actual[i] = instance.ClassValue.Value().(string)
+		predicted[i], err = predict(instance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to predict instance %d: %w", i, err)
+		}
+	}
+	var avgPredictTime time.Duration
+	if len(instances) > 0 {
+		avgPredictTime = time.Since(startTime) / time.Duration(len(instances))
+	}
+	res := NewTestResults(actual, predicted, leafCount)
+	res.AvgPredictTime = avgPredictTime
+	return res, nil
+}
+
+// NewTestResults calculates the test results of the predicted classes against the actual classes, the number of
+// leaf nodes is used by the pessimistic error.
+func NewTestResults(actual, predicted []string, leafCount int) *TestResults {
 	var (
 		correctCount      int
 		errorCount        int
@@ -37,40 +128,34 @@ func testRunNode(node *Node, instances []*data.Instance) (*TestResults, error) {
 		classRecall       = make(map[string]float64)
 		classPrecision    = make(map[string]float64)
 		confusionMatrix   = make(map[string]map[string]int)
-		startTime         time.Time
 	)
-	startTime = time.Now()
-	for i, instance := range instances {
-		classDataCount[instance.ClassValue.Value().(string)]++
-		res, err := node.Predict(instance)
-		if err != nil {
-			return nil, fmt.Errorf("failed to predict instance %d: %w", i, err)
-		}
+	for i, class := range actual {
+		classDataCount[class]++
+		res := predicted[i]
 		classPredictCount[res]++
-		if _, ok := confusionMatrix[instance.ClassValue.Value().(string)]; !ok {
-			confusionMatrix[instance.ClassValue.Value().(string)] = make(map[string]int)
+		if _, ok := confusionMatrix[class]; !ok {
+			confusionMatrix[class] = make(map[string]int)
 		}
-		confusionMatrix[instance.ClassValue.Value().(string)][res]++
-		if res == instance.ClassValue.Value().(string) {
+		confusionMatrix[class][res]++
+		if res == class {
 			correctCount++
-			classCorrectCount[instance.ClassValue.Value().(string)]++
+			classCorrectCount[class]++
 		} else {
 			errorCount++
-			classErrorCount[instance.ClassValue.Value().(string)]++
+			classErrorCount[class]++
 		}
 	}
-	accuracy := float64(correctCount) / float64(len(instances))
+	accuracy := float64(correctCount) / float64(len(actual))
 	for k, v := range classDataCount {
 		classRecall[k] = float64(classCorrectCount[k]) / float64(v)
-		classPrecision[k] = float64(classCorrectCount[k]) / float64(classPredictCount[k])
-	}
-	leafNodes := node.GetLeafNodes()
-	var avgPredictTime time.Duration
-	if len(instances) > 0 {
-		avgPredictTime = time.Since(startTime) / time.Duration(len(instances))
+		if classPredictCount[k] > 0 {
+			classPrecision[k] = float64(classCorrectCount[k]) / float64(classPredictCount[k])
+		} else {
+			classPrecision[k] = 0
+		}
 	}
-	return &TestResults{
-		TotalDataCount:    len(instances),
+	res := &TestResults{
+		TotalDataCount:    len(actual),
 		CorrectCount:      correctCount,
 		ErrorCount:        errorCount,
 		Accuracy:          accuracy,
@@ -81,9 +166,10 @@ func testRunNode(node *Node, instances []*data.Instance) (*TestResults, error) {
 		ClassRecall:       classRecall,
 		ClassPrecision:    classPrecision,
 		ConfusionMatrix:   confusionMatrix,
-		PessimisticError:  calculatePessimisticError(errorCount, len(leafNodes), len(instances)),
-		AvgPredictTime:    avgPredictTime,
-	}, nil
+		PessimisticError:  calculatePessimisticError(errorCount, leafCount, len(actual)),
+	}
+	res.calculateMetrics()
+	return res
 }
 
 func calculatePessimisticError(errorCount, leafNodesCount, totalDataCount int) float64 {
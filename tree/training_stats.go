@@ -0,0 +1,130 @@
+package tree
+
+import (
+	"DecisionTree/data"
+	"math"
+	"sort"
+)
+
+// TrainingStatsBinCount is the number of equal-frequency bins of the continuous attributes in the training stats.
+const TrainingStatsBinCount = 10
+
+// TrainingStats summarizes the data a tree is trained on, so that the data it predicts can be checked for drift.
+type TrainingStats struct {
+	InstanceCount int                        `json:"instance_count"`
+	Attributes    map[string]*AttributeStats `json:"attributes"`
+	Class         *AttributeStats            `json:"class,omitempty"`
+	LeafOccupancy map[int]float64            `json:"leaf_occupancy"` // fraction of the instances reaching each leaf, by unique id
+}
+
+// AttributeStats is the distribution of the values of an attribute. The frequencies are among the non-missing values.
+type AttributeStats struct {
+	MissingRate float64            `json:"missing_rate"`
+	Values      map[string]float64 `json:"values,omitempty"` // frequency of each nominal value
+
+	// continuous attributes only
+	Min      float64   `json:"min,omitempty"`
+	Max      float64   `json:"max,omitempty"`
+	BinEdges []float64 `json:"bin_edges,omitempty"` // ascending inner edges of the bins, see Bin
+	Bins     []float64 `json:"bins,omitempty"`      // frequency of each bin
+}
+
+// NewTrainingStats collects the training stats of the tree from its training data, as the tree is trained on it,
+// i.e. already transformed by the pipeline of the tree if any.
+func NewTrainingStats(tr *Tree, valueTable *data.ValueTable) *TrainingStats {
+	res := &TrainingStats{
+		InstanceCount: len(valueTable.Instances),
+		Attributes:    make(map[string]*AttributeStats),
+		LeafOccupancy: make(map[int]float64),
+	}
+	if len(valueTable.Instances) == 0 {
+		return res
+	}
+
+	for _, attr := range tr.Attributes {
+		values := make([]data.Value, 0, len(valueTable.Instances))
+		for _, instance := range valueTable.Instances {
+			if value := instance.GetValueByAttr(attr); value != nil {
+				values = append(values, value)
+			}
+		}
+		res.Attributes[attr.Name()] = newAttributeStats(attr.Type(), values)
+	}
+
+	classValues := make([]data.Value, len(valueTable.Instances))
+	for i, instance := range valueTable.Instances {
+		classValues[i] = instance.ClassValue
+	}
+	res.Class = newAttributeStats(data.Nominal, classValues)
+
+	for _, instance := range valueTable.Instances {
+		path, err := tr.PredictTransformedPath(instance)
+		if err != nil {
+			continue
+		}
+		res.LeafOccupancy[path[len(path)-1].UniqId()]++
+	}
+	for id := range res.LeafOccupancy {
+		res.LeafOccupancy[id] /= float64(len(valueTable.Instances))
+	}
+	return res
+}
+
+func newAttributeStats(attrType data.AttributeType, values []data.Value) *AttributeStats {
+	var (
+		res        = &AttributeStats{}
+		missing    int
+		nominal    = make(map[string]float64)
+		continuous []float64
+	)
+	for _, value := range values {
+		switch {
+		case value.IsMissing():
+			missing++
+		case attrType.IsNumeric():
+			continuous = append(continuous, value.Value().(float64))
+		default:
+			nominal[value.Value().(string)]++
+		}
+	}
+	if len(values) > 0 {
+		res.MissingRate = float64(missing) / float64(len(values))
+	}
+	if !attrType.IsNumeric() {
+		known := float64(len(values) - missing)
+		for value, count := range nominal {
+			nominal[value] = count / known
+		}
+		res.Values = nominal
+		return res
+	}
+	if len(continuous) == 0 {
+		return res
+	}
+
+	sort.Float64s(continuous)
+	res.Min, res.Max = continuous[0], continuous[len(continuous)-1]
+	// inner edges at the quantiles, ties of the values might merge bins
+	for i := 1; i < TrainingStatsBinCount; i++ {
+		edge := continuous[int(math.Ceil(float64(i*len(continuous))/TrainingStatsBinCount))-1]
+		if edge == res.Max {
+			break
+		}
+		if len(res.BinEdges) == 0 || edge > res.BinEdges[len(res.BinEdges)-1] {
+			res.BinEdges = append(res.BinEdges, edge)
+		}
+	}
+	res.Bins = make([]float64, len(res.BinEdges)+1)
+	for _, v := range continuous {
+		res.Bins[res.Bin(v)]++
+	}
+	for i := range res.Bins {
+		res.Bins[i] /= float64(len(continuous))
+	}
+	return res
+}
+
+// Bin returns the index of the bin of a continuous value, bin i holds the values in (BinEdges[i-1], BinEdges[i]].
+func (s *AttributeStats) Bin(value float64) int {
+	return sort.SearchFloat64s(s.BinEdges, value)
+}
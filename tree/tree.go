@@ -2,21 +2,70 @@ package tree
 
 import (
 	"DecisionTree/data"
+	"DecisionTree/transform"
+	"fmt"
 	"strings"
 )
 
 type Tree struct {
 	Attributes []data.Attribute
+	Class      *data.NominalAttribute   // might be nil for trees restored from older model files
+	Targets    []*data.NominalAttribute // additional targets of multi-output trees, see PredictTargets
 	RootNode   *Node
+
+	FeatureImportance *FeatureImportance
+	Calibrator        *Calibrator         // calibrates the class probabilities, nil if not calibrated
+	Threshold         *DecisionThreshold  // replaces the majority vote of the leaves, nil if not tuned
+	TrainingStats     *TrainingStats      // nil for trees restored from older model files
+	Pipeline          *transform.Pipeline // transforms the instances to predict, nil if they are predicted as they are
+
+	ModelSmoothing float64 // k of the smoothing of model trees, 0 if not smoothed, see Node.Model
 }
 
 func (t *Tree) Copy() *Tree {
 	return &Tree{
-		Attributes: t.Attributes,
-		RootNode:   t.RootNode.Copy(),
+		Attributes:        t.Attributes,
+		Class:             t.Class,
+		Targets:           t.Targets,
+		RootNode:          t.RootNode.Copy(),
+		FeatureImportance: t.FeatureImportance,
+		Calibrator:        t.Calibrator,
+		Threshold:         t.Threshold,
+		TrainingStats:     t.TrainingStats,
+		Pipeline:          t.Pipeline,
+		ModelSmoothing:    t.ModelSmoothing,
 	}
 }
 
+// InputAttributes returns the attributes of the instances to predict, which are the input attributes of the
+// pipeline if the tree has one.
+func (t *Tree) InputAttributes() []data.Attribute {
+	if t.Pipeline == nil {
+		return t.Attributes
+	}
+	return t.Pipeline.InputAttributes()
+}
+
+// Transform transforms an instance to predict with the pipeline of the tree, if any.
+func (t *Tree) Transform(instance *data.Instance) (*data.Instance, error) {
+	if t.Pipeline == nil {
+		return instance, nil
+	}
+	res, err := t.Pipeline.Transform(instance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform instance: %w", err)
+	}
+	return res, nil
+}
+
+// TransformTable transforms a data set with the pipeline of the tree, if any.
+func (t *Tree) TransformTable(valueTable *data.ValueTable) (*data.ValueTable, error) {
+	if t.Pipeline == nil {
+		return valueTable, nil
+	}
+	return t.Pipeline.TransformTable(valueTable)
+}
+
 func (t *Tree) GetNodeCount() int {
 	return t.RootNode.GetNodeCount()
 }
@@ -56,6 +105,14 @@ type Node struct {
 
 	IsPrioritized bool // When facing missing value, prioritize this node
 	LeafClass     string
+	ClassCount    map[string]float64 // weighted training instance count of each class value reaching this node
+	SplitGain     float64            // information gain of splitting this node into its children
+
+	// multi-output trees only, by the index of the target in Tree.Targets
+	TargetClassCount []map[string]float64 // weighted training instance count of each value of the target
+	LeafTargetClass  []string             // majority value of the target at the leaf, empty if no instance knows it
+
+	Model *LinearModel // model trees only, fitted at every node, the leaves predict by it
 
 	uniqId int
 }
@@ -70,6 +127,26 @@ func (n *Node) UniqId() int {
 	return n.uniqId
 }
 
+// AssignUniqIds gives the nodes without an id one above the ids of the tree. UniqId assigns the ids lazily, writing a
+// global counter, so a tree must have its ids assigned before it predicts concurrently.
+func (t *Tree) AssignUniqIds() {
+	var (
+		nodes   []*Node
+		collect func(node *Node)
+	)
+	collect = func(node *Node) {
+		nodes = append(nodes, node)
+		globalUniqId = max(globalUniqId, node.uniqId)
+		for _, child := range node.Children {
+			collect(child)
+		}
+	}
+	collect(t.RootNode)
+	for _, node := range nodes {
+		node.UniqId()
+	}
+}
+
 func (n *Node) LogChildConditions() string {
 	var conditions []string
 	for _, child := range n.Children {
@@ -90,7 +167,14 @@ func (n *Node) Copy() *Node {
 		instances:     n.instances,
 		IsPrioritized: n.IsPrioritized,
 		LeafClass:     n.LeafClass,
+		ClassCount:    n.ClassCount,
+		SplitGain:     n.SplitGain,
 		uniqId:        n.uniqId,
+
+		TargetClassCount: n.TargetClassCount,
+		LeafTargetClass:  n.LeafTargetClass,
+
+		Model: n.Model,
 	}
 }
 
@@ -126,3 +210,12 @@ func (n *Node) getMaxDepth(myDepth int) int {
 	}
 	return maxDepth
 }
+
+// GetSampleCount returns the weighted number of training instances that reached this node.
+func (n *Node) GetSampleCount() float64 {
+	count := 0.0
+	for _, c := range n.ClassCount {
+		count += c
+	}
+	return count
+}
@@ -0,0 +1,22 @@
+package utils
+
+import "math"
+
+// Entropy calculates the entropy of a class distribution in bits, given as the (weighted) count of each class value.
+func Entropy(classCount map[string]float64) float64 {
+	var (
+		total   = 0.0
+		entropy = 0.0
+	)
+	for _, count := range classCount {
+		total += count
+	}
+	for _, count := range classCount {
+		if count == 0 {
+			continue
+		}
+		frequency := count / total
+		entropy -= frequency * math.Log2(frequency)
+	}
+	return entropy
+}